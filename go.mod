@@ -1,3 +1,5 @@
 module github.com/svgreg/wifi-bander
 
 go 1.21
+
+require github.com/godbus/dbus/v5 v5.1.0
@@ -0,0 +1,142 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultActionTimeout bounds a command or webhook that sets no timeout of its own
+const defaultActionTimeout = 10 * time.Second
+
+// Action delivers notifications somewhere outside the program
+type Action interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// ActionConfig configures one named action; which fields apply depends on Type
+type ActionConfig struct {
+	Type    string            `json:"type"`              // command, webhook or syslog
+	Command []string          `json:"command,omitempty"` // command: program and arguments, run with the notification as JSON on stdin
+	URL     string            `json:"url,omitempty"`     // webhook: the notification is POSTed here as JSON
+	Headers map[string]string `json:"headers,omitempty"` // webhook: extra request headers, e.g. Authorization
+	Tag     string            `json:"tag,omitempty"`     // syslog: program tag, wifi-bander by default
+	Timeout Duration          `json:"timeout,omitempty"` // command and webhook: 10s by default
+}
+
+// actionTypes creates actions by type name
+var actionTypes = map[string]func(config ActionConfig) (Action, error){
+	"command": newCommandAction,
+	"webhook": newWebhookAction,
+	"syslog":  newSyslogAction,
+}
+
+// NewAction creates the action a config describes
+func NewAction(config ActionConfig) (Action, error) {
+	create, ok := actionTypes[config.Type]
+	if !ok {
+		var types []string
+		for name := range actionTypes {
+			types = append(types, name)
+		}
+		sort.Strings(types)
+		return nil, fmt.Errorf("unknown action type %q; expected one of %s", config.Type, strings.Join(types, ", "))
+	}
+	return create(config)
+}
+
+// timeout returns the configured timeout or the default
+func (c ActionConfig) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return time.Duration(c.Timeout)
+	}
+	return defaultActionTimeout
+}
+
+// commandAction runs a local program with the notification as JSON on stdin
+type commandAction struct {
+	argv    []string
+	timeout time.Duration
+}
+
+func newCommandAction(config ActionConfig) (Action, error) {
+	if len(config.Command) == 0 || config.Command[0] == "" {
+		return nil, fmt.Errorf("command action needs a command")
+	}
+	return commandAction{argv: config.Command, timeout: config.timeout()}, nil
+}
+
+// Notify runs the command and fails if it exits with an error
+func (a commandAction) Notify(ctx context.Context, notification Notification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, a.argv[0], a.argv[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return fmt.Errorf("%s failed: %v: %s", a.argv[0], err, detail)
+		}
+		return fmt.Errorf("%s failed: %v", a.argv[0], err)
+	}
+	return nil
+}
+
+// webhookAction POSTs the notification as JSON to a URL
+type webhookAction struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newWebhookAction(config ActionConfig) (Action, error) {
+	parsed, err := url.Parse(config.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("webhook action needs an http or https url, got %q", config.URL)
+	}
+	return webhookAction{url: config.URL, headers: config.Headers, client: &http.Client{Timeout: config.timeout()}}, nil
+}
+
+// Notify posts the notification and fails on any status other than 2xx
+func (a webhookAction) Notify(ctx context.Context, notification Notification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range a.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook failed: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook failed: %s returned %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
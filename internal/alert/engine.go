@@ -0,0 +1,245 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/changes"
+	"github.com/svgreg/wifi-bander/internal/display"
+	"github.com/svgreg/wifi-bander/internal/scanner"
+)
+
+// queueLength is how many notifications an action may fall behind before they are dropped
+const queueLength = 64
+
+// State is whether a notification raises or clears an alert
+type State string
+
+const (
+	StateFiring   State = "firing"
+	StateResolved State = "resolved"
+)
+
+// Notification is what actions receive, as JSON for commands and webhooks
+type Notification struct {
+	Rule        string              `json:"rule"`
+	Description string              `json:"description,omitempty"`
+	State       State               `json:"state"`
+	Time        time.Time           `json:"time"`            // Scan that fired or resolved the alert
+	Since       time.Time           `json:"since"`           // When the condition started holding
+	Network     scanner.WiFiNetwork `json:"network"`         // Latest observation that matched the rule
+	Congestion  string              `json:"congestion"`      // Congestion level of the network
+	Event       string              `json:"event,omitempty"` // The change that fired an event rule
+}
+
+// String describes the notification in one line
+func (n Notification) String() string {
+	name := n.Network.SSID
+	if name == "" {
+		name = "<hidden>"
+	}
+	line := fmt.Sprintf("[%s] %s: %s (%s) on %s channel %d, %d dBm, %s congestion",
+		n.State, n.Rule, name, n.Network.BSSID, n.Network.Band, n.Network.Channel, n.Network.Signal, n.Congestion)
+	if n.Event != "" {
+		line += ": " + n.Event
+	}
+	return line
+}
+
+// Engine evaluates rules against each scan and hands notifications to the rules'
+// actions. Each action delivers in order on its own goroutine, so a slow webhook
+// does not hold up scanning.
+type Engine struct {
+	// OnError is called with delivery failures; they are dropped when nil
+	OnError func(action string, err error)
+
+	rules   []Rule
+	workers map[string]*worker
+	alerts  map[alertKey]*alertState
+	wg      sync.WaitGroup
+}
+
+// alertKey identifies one alert: a rule matching one BSS
+type alertKey struct {
+	rule    string
+	network string
+}
+
+// alertState tracks a network rule's alert from first match to resolution
+type alertState struct {
+	rule      *Rule
+	network   scanner.WiFiNetwork
+	since     time.Time // First scan of the current run of matches
+	lastMatch time.Time
+	firing    bool
+}
+
+// worker delivers one action's notifications in order
+type worker struct {
+	name   string
+	action Action
+	queue  chan Notification
+}
+
+// NewEngine creates the configured actions and starts delivering
+func NewEngine(config Config) (*Engine, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	e := &Engine{rules: config.Rules, workers: make(map[string]*worker), alerts: make(map[alertKey]*alertState)}
+	for name, actionConfig := range config.Actions {
+		action, err := NewAction(actionConfig)
+		if err != nil {
+			e.Close()
+			return nil, fmt.Errorf("action %q: %w", name, err)
+		}
+		w := &worker{name: name, action: action, queue: make(chan Notification, queueLength)}
+		e.workers[name] = w
+		e.wg.Add(1)
+		go e.deliver(w)
+	}
+	return e, nil
+}
+
+// Load reads an alerting configuration and creates its engine
+func Load(filename string) (*Engine, error) {
+	config, err := LoadConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := NewEngine(config)
+	if err != nil {
+		return nil, fmt.Errorf("alert rules %s: %w", filename, err)
+	}
+	return engine, nil
+}
+
+// Rules returns how many rules the engine evaluates
+func (e *Engine) Rules() int {
+	return len(e.rules)
+}
+
+// Evaluate checks every rule against a scan taken at the given time and the changes
+// it showed, and queues and returns the notifications it raised
+func (e *Engine) Evaluate(networks []scanner.WiFiNetwork, events []changes.Event, at time.Time) []Notification {
+	var notifications []Notification
+	connected := changes.NewConnected(networks)
+	matched := make(map[alertKey]bool)
+
+	for i := range e.rules {
+		rule := &e.rules[i]
+
+		if rule.When.Event != "" {
+			for _, event := range events {
+				if event.Type != rule.When.Event || !rule.When.Match(event.Network, connected) {
+					continue
+				}
+				notification := newNotification(rule, StateFiring, event.Network, at, at)
+				notification.Event = event.String()
+				notifications = append(notifications, notification)
+			}
+			continue
+		}
+
+		for _, network := range networks {
+			if !rule.When.Match(network, connected) {
+				continue
+			}
+			key := alertKey{rule: rule.Name, network: changes.Identity(network)}
+			if matched[key] {
+				continue
+			}
+			matched[key] = true
+
+			state, ok := e.alerts[key]
+			if !ok {
+				state = &alertState{rule: rule, since: at}
+				e.alerts[key] = state
+			}
+			state.network, state.lastMatch = network, at
+			if !state.firing && at.Sub(state.since) >= time.Duration(rule.For) {
+				state.firing = true
+				notifications = append(notifications, newNotification(rule, StateFiring, network, state.since, at))
+			}
+		}
+	}
+
+	for key, state := range e.alerts {
+		if matched[key] {
+			continue
+		}
+		// A pending alert whose condition lapses never fires
+		if !state.firing {
+			delete(e.alerts, key)
+			continue
+		}
+		if at.Sub(state.lastMatch) >= time.Duration(state.rule.ResolveAfter) {
+			delete(e.alerts, key)
+			notifications = append(notifications, newNotification(state.rule, StateResolved, state.network, state.since, at))
+		}
+	}
+
+	for _, notification := range notifications {
+		e.dispatch(notification)
+	}
+	return notifications
+}
+
+// newNotification describes a rule's alert on a network
+func newNotification(rule *Rule, state State, network scanner.WiFiNetwork, since, at time.Time) Notification {
+	return Notification{
+		Rule:        rule.Name,
+		Description: rule.Description,
+		State:       state,
+		Time:        at,
+		Since:       since,
+		Network:     network,
+		Congestion:  display.GetCongestionLevel(network.CongestionScore),
+	}
+}
+
+// dispatch queues a notification for each of its rule's actions
+func (e *Engine) dispatch(notification Notification) {
+	for _, rule := range e.rules {
+		if rule.Name != notification.Rule {
+			continue
+		}
+		for _, name := range rule.Actions {
+			w := e.workers[name]
+			select {
+			case w.queue <- notification:
+			default:
+				e.report(name, fmt.Errorf("dropped %s notification for %s: %d deliveries pending", notification.State, notification.Rule, queueLength))
+			}
+		}
+	}
+}
+
+// deliver runs an action on each queued notification until the queue is closed
+func (e *Engine) deliver(w *worker) {
+	defer e.wg.Done()
+	for notification := range w.queue {
+		if err := w.action.Notify(context.Background(), notification); err != nil {
+			e.report(w.name, err)
+		}
+	}
+}
+
+// report passes a delivery failure to OnError
+func (e *Engine) report(action string, err error) {
+	if e.OnError != nil {
+		e.OnError(action, err)
+	}
+}
+
+// Close waits for queued notifications to be delivered and stops the actions
+func (e *Engine) Close() {
+	for _, w := range e.workers {
+		close(w.queue)
+	}
+	e.wg.Wait()
+	e.workers = nil
+}
@@ -0,0 +1,186 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/changes"
+	"github.com/svgreg/wifi-bander/internal/scanner"
+)
+
+// webhookRecorder collects the notifications POSTed to it
+type webhookRecorder struct {
+	mu       sync.Mutex
+	received []Notification
+}
+
+// newTestEngine creates an engine whose rules all notify a local webhook
+func newTestEngine(t *testing.T, rules ...Rule) (*Engine, *webhookRecorder) {
+	t.Helper()
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification Notification
+		if err := json.NewDecoder(r.Body).Decode(&notification); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		recorder.mu.Lock()
+		recorder.received = append(recorder.received, notification)
+		recorder.mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	for i := range rules {
+		rules[i].Actions = []string{"hook"}
+	}
+	engine, err := NewEngine(Config{Actions: map[string]ActionConfig{"hook": {Type: "webhook", URL: server.URL}}, Rules: rules})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	engine.OnError = func(action string, err error) { t.Errorf("action %s: %v", action, err) }
+	return engine, recorder
+}
+
+// describe summarizes notifications as "state rule bssid" strings
+func describe(notifications []Notification) []string {
+	var described []string
+	for _, n := range notifications {
+		described = append(described, string(n.State)+" "+n.Rule+" "+n.Network.BSSID)
+	}
+	return described
+}
+
+func TestEngineForAndResolveAfter(t *testing.T) {
+	engine, recorder := newTestEngine(t, Rule{
+		Name:         "office",
+		When:         Condition{SSID: "Office"},
+		For:          Duration(2 * time.Minute),
+		ResolveAfter: Duration(time.Minute),
+	})
+	office, neighbor, _ := matchNetworks()
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		offset  time.Duration
+		present bool
+		want    State // Empty when nothing is raised
+	}{
+		{0, true, ""},           // Pending
+		{time.Minute, true, ""}, // Held for 1m of 2m
+		{2 * time.Minute, true, StateFiring},
+		{3 * time.Minute, true, ""},                 // Already firing
+		{3*time.Minute + 30*time.Second, false, ""}, // Missing for 30s of 1m
+		{4 * time.Minute, true, ""},                 // Back before resolving
+		{4*time.Minute + 30*time.Second, false, ""},
+		{5*time.Minute + 30*time.Second, false, StateResolved},
+		{6 * time.Minute, false, ""}, // Resolved alerts are forgotten
+	}
+
+	var raised []Notification
+	for _, step := range steps {
+		networks := []scanner.WiFiNetwork{neighbor}
+		if step.present {
+			networks = append(networks, office)
+		}
+		at := start.Add(step.offset)
+		notifications := engine.Evaluate(networks, nil, at)
+
+		if step.want == "" {
+			if len(notifications) != 0 {
+				t.Errorf("+%v: raised %v", step.offset, describe(notifications))
+			}
+			continue
+		}
+		if len(notifications) != 1 || notifications[0].State != step.want {
+			t.Fatalf("+%v: raised %v, want one %s notification", step.offset, describe(notifications), step.want)
+		}
+		n := notifications[0]
+		if !n.Time.Equal(at) || !n.Since.Equal(start) || n.Network.BSSID != office.BSSID || n.Congestion != "Medium" {
+			t.Errorf("+%v: notification %+v", step.offset, n)
+		}
+		raised = append(raised, n)
+	}
+
+	engine.Close()
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.received) != len(raised) {
+		t.Fatalf("webhook received %d notifications, want %d", len(recorder.received), len(raised))
+	}
+	for i, n := range recorder.received {
+		if n.State != raised[i].State || !n.Time.Equal(raised[i].Time) {
+			t.Errorf("webhook notification %d = %s at %v, want %s at %v", i, n.State, n.Time, raised[i].State, raised[i].Time)
+		}
+	}
+}
+
+func TestEnginePendingAlertLapses(t *testing.T) {
+	engine, _ := newTestEngine(t, Rule{Name: "office", When: Condition{SSID: "Office"}, For: Duration(2 * time.Minute)})
+	defer engine.Close()
+	office, _, _ := matchNetworks()
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// A single missed scan restarts the For period
+	for _, step := range []struct {
+		offset  time.Duration
+		present bool
+	}{{0, true}, {time.Minute, false}, {2 * time.Minute, true}, {3 * time.Minute, true}} {
+		var networks []scanner.WiFiNetwork
+		if step.present {
+			networks = append(networks, office)
+		}
+		if notifications := engine.Evaluate(networks, nil, start.Add(step.offset)); len(notifications) != 0 {
+			t.Errorf("+%v: raised %v", step.offset, describe(notifications))
+		}
+	}
+
+	notifications := engine.Evaluate([]scanner.WiFiNetwork{office}, nil, start.Add(4*time.Minute))
+	if len(notifications) != 1 || notifications[0].State != StateFiring || !notifications[0].Since.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("+4m: raised %+v, want firing since +2m", notifications)
+	}
+}
+
+func TestEngineImmediateRules(t *testing.T) {
+	// Without For and ResolveAfter an alert fires on the first match and resolves on the first miss
+	engine, _ := newTestEngine(t, Rule{Name: "open", When: Condition{Security: "Open"}}, Rule{Name: "weak", When: Condition{SignalBelow: intPtr(-65)}})
+	defer engine.Close()
+	_, neighbor, far := matchNetworks()
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// The same BSS reported twice in one scan, as by two radios, alerts once per rule
+	got := describe(engine.Evaluate([]scanner.WiFiNetwork{neighbor, far, neighbor}, nil, start))
+	want := []string{"firing open 02:00:00:00:00:01", "firing weak 02:00:00:00:00:01", "firing weak 02:00:00:00:00:02"}
+	if !slices.Equal(got, want) {
+		t.Errorf("first scan raised %v, want %v", got, want)
+	}
+
+	got = describe(engine.Evaluate([]scanner.WiFiNetwork{far}, nil, start.Add(time.Minute)))
+	if len(got) != 2 || got[0][:8] != "resolved" || got[1][:8] != "resolved" {
+		t.Errorf("second scan raised %v, want both of the neighbor's alerts resolved", got)
+	}
+}
+
+func TestEngineEventRules(t *testing.T) {
+	engine, _ := newTestEngine(t, Rule{Name: "strong-newcomer", When: Condition{Event: changes.APAppeared, SignalAbove: intPtr(-60)}})
+	defer engine.Close()
+	office, neighbor, _ := matchNetworks()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []changes.Event{
+		{Type: changes.APAppeared, Time: at, Network: office},
+		{Type: changes.APAppeared, Time: at, Network: neighbor}, // Too weak
+		{Type: changes.ChannelChanged, Time: at, Network: office, Old: "40", New: "36"},
+	}
+	for i := 0; i < 2; i++ {
+		// Event rules fire for every matching event, with no state between scans
+		notifications := engine.Evaluate([]scanner.WiFiNetwork{office, neighbor}, events, at.Add(time.Duration(i)*time.Minute))
+		if len(notifications) != 1 || notifications[0].Network.BSSID != office.BSSID || notifications[0].Event == "" {
+			t.Errorf("scan %d raised %+v, want the Office appearance", i+1, notifications)
+		}
+	}
+}
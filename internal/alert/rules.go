@@ -0,0 +1,213 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/changes"
+	"github.com/svgreg/wifi-bander/internal/display"
+	"github.com/svgreg/wifi-bander/internal/ie"
+	"github.com/svgreg/wifi-bander/internal/scanner"
+)
+
+// congestionLevels are the display.GetCongestionLevel levels, least congested first
+var congestionLevels = []string{"Low", "Medium", "High", "Very High"}
+
+// Config is an alerting configuration file: named actions and the rules that use them
+type Config struct {
+	Actions map[string]ActionConfig `json:"actions"`
+	Rules   []Rule                  `json:"rules"`
+}
+
+// Rule raises an alert for each network matching its condition. Rules on networks
+// fire once the condition has held for For and resolve once it has not held for
+// ResolveAfter; rules on change events fire for every matching event.
+type Rule struct {
+	Name         string    `json:"name"`
+	Description  string    `json:"description,omitempty"`
+	When         Condition `json:"when"`
+	For          Duration  `json:"for,omitempty"`           // How long the condition must hold before firing
+	ResolveAfter Duration  `json:"resolve_after,omitempty"` // How long it must have stopped holding before resolving
+	Actions      []string  `json:"actions"`                 // Names of actions in Config.Actions
+}
+
+// Condition matches networks, or change events when Event is set. Every field
+// that is set must match; SSID and BSSID accept shell patterns such as "Corp*".
+type Condition struct {
+	Event       changes.EventType `json:"event,omitempty"` // appeared, disappeared, channel, width, security or signal
+	SSID        string            `json:"ssid,omitempty"`
+	BSSID       string            `json:"bssid,omitempty"` // Case-insensitive
+	Band        string            `json:"band,omitempty"`  // 2.4G, 5G or 6G
+	Channel     int               `json:"channel,omitempty"`
+	Security    string            `json:"security,omitempty"`     // A label as shown in the results, e.g. "WPA2 Personal", or a class: Open (including OWE), WEP, Personal or Enterprise
+	SignalAbove *int              `json:"signal_above,omitempty"` // dBm
+	SignalBelow *int              `json:"signal_below,omitempty"` // dBm
+	Congestion  string            `json:"congestion,omitempty"`   // This congestion level or worse: Low, Medium, High or Very High
+
+	// The network this host is connected to, or any other network
+	Connected *bool `json:"connected,omitempty"`
+	// Networks overlapping the channel of the connected network, the connected one excluded
+	OnConnectedChannel *bool `json:"on_connected_channel,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "5m" in config files
+type Duration time.Duration
+
+// UnmarshalJSON parses a Go duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5m\"")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a Go duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// LoadConfig reads a JSON alerting configuration. Unknown names are an error, so
+// typos are not silently ignored, and every rule is checked before any scan.
+func LoadConfig(filename string) (Config, error) {
+	var config Config
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return config, fmt.Errorf("reading alert rules: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return config, fmt.Errorf("parsing alert rules %s: %w", filename, err)
+	}
+	if err := config.validate(); err != nil {
+		return config, fmt.Errorf("alert rules %s: %w", filename, err)
+	}
+	return config, nil
+}
+
+// validate checks rules for unknown actions, levels and event types
+func (c Config) validate() error {
+	names := make(map[string]bool)
+	for i, rule := range c.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule %q is defined twice", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if len(rule.Actions) == 0 {
+			return fmt.Errorf("rule %q has no actions", rule.Name)
+		}
+		for _, action := range rule.Actions {
+			if _, ok := c.Actions[action]; !ok {
+				return fmt.Errorf("rule %q uses undefined action %q", rule.Name, action)
+			}
+		}
+		if err := rule.When.validate(); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		if rule.When.Event != "" && (rule.For != 0 || rule.ResolveAfter != 0) {
+			return fmt.Errorf("rule %q: event rules fire once per event and take no for or resolve_after", rule.Name)
+		}
+	}
+	return nil
+}
+
+// validate checks a condition's enumerated values and patterns
+func (c Condition) validate() error {
+	switch c.Event {
+	case "", changes.APAppeared, changes.APDisappeared, changes.ChannelChanged,
+		changes.WidthChanged, changes.SecurityChanged, changes.SignalChanged:
+	default:
+		return fmt.Errorf("unknown event %q", c.Event)
+	}
+	if c.Congestion != "" && levelRank(c.Congestion) < 0 {
+		return fmt.Errorf("unknown congestion level %q; expected one of %s", c.Congestion, strings.Join(congestionLevels, ", "))
+	}
+	for _, pattern := range []string{c.SSID, c.BSSID} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Match reports whether a network satisfies the condition, given the networks this
+// host is connected to in the same scan
+func (c Condition) Match(network scanner.WiFiNetwork, connected changes.Connected) bool {
+	if c.SSID != "" && !matchPattern(c.SSID, network.SSID) {
+		return false
+	}
+	if c.BSSID != "" && !matchPattern(strings.ToLower(c.BSSID), strings.ToLower(network.BSSID)) {
+		return false
+	}
+	if c.Band != "" && network.Band != c.Band {
+		return false
+	}
+	if c.Channel != 0 && network.Channel != c.Channel {
+		return false
+	}
+	if c.Security != "" && !matchSecurity(c.Security, network.Security) {
+		return false
+	}
+	if c.SignalAbove != nil && network.Signal <= *c.SignalAbove {
+		return false
+	}
+	if c.SignalBelow != nil && network.Signal >= *c.SignalBelow {
+		return false
+	}
+	if c.Congestion != "" && levelRank(display.GetCongestionLevel(network.CongestionScore)) < levelRank(c.Congestion) {
+		return false
+	}
+	if c.Connected != nil && network.Connected != *c.Connected {
+		return false
+	}
+	if c.OnConnectedChannel != nil && connected.Overlaps(network) != *c.OnConnectedChannel {
+		return false
+	}
+	return true
+}
+
+// matchPattern matches a shell pattern; patterns were validated when loaded
+func matchPattern(pattern, s string) bool {
+	matched, _ := path.Match(pattern, s)
+	return matched
+}
+
+// matchSecurity reports whether a network's security label is the wanted label or
+// falls in the wanted class. Labels compare ignoring case, with hyphens and spaces
+// alike, so "WPA2-Personal" matches the "WPA2 Personal" the scanners report.
+func matchSecurity(want, security string) bool {
+	normalize := func(label string) string {
+		return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(label), "-", " "))
+	}
+	if normalize(want) == normalize(security) {
+		return true
+	}
+	class := ie.ClassifySecurity(security)
+	return class != ie.SecurityUnknown && strings.EqualFold(strings.TrimSpace(want), class.String())
+}
+
+// levelRank returns a congestion level's position, or -1 for an unknown level
+func levelRank(level string) int {
+	for i, known := range congestionLevels {
+		if strings.EqualFold(level, known) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,98 @@
+package alert
+
+import (
+	"testing"
+
+	"github.com/svgreg/wifi-bander/internal/changes"
+	"github.com/svgreg/wifi-bander/internal/scanner"
+)
+
+func intPtr(v int) *int    { return &v }
+func boolPtr(v bool) *bool { return &v }
+
+// matchNetworks is a scan with this host connected to Office on channel 36 at 80 MHz
+func matchNetworks() (office, neighbor, far scanner.WiFiNetwork) {
+	office = scanner.WiFiNetwork{
+		SSID: "Office", BSSID: "00:11:32:AA:BB:CC", Band: "5G", Channel: 36, Frequency: 5180,
+		ChannelWidth: "80MHz", CenterFrequency: 5210, Signal: -55, Security: "WPA2 Personal",
+		CongestionScore: 20, Connected: true,
+	}
+	neighbor = scanner.WiFiNetwork{
+		SSID: "Corp-Guest", BSSID: "02:00:00:00:00:01", Band: "5G", Channel: 44, Frequency: 5220,
+		ChannelWidth: "20MHz", Signal: -70, Security: "Open", CongestionScore: 60,
+	}
+	far = scanner.WiFiNetwork{
+		SSID: "Corp", BSSID: "02:00:00:00:00:02", Band: "5G", Channel: 149, Frequency: 5745,
+		ChannelWidth: "20MHz", Signal: -80, Security: "WPA2/WPA3 Enterprise", CongestionScore: 5,
+	}
+	return office, neighbor, far
+}
+
+func TestConditionMatch(t *testing.T) {
+	office, neighbor, far := matchNetworks()
+	connected := changes.NewConnected([]scanner.WiFiNetwork{office, neighbor, far})
+
+	tests := []struct {
+		name      string
+		condition Condition
+		want      [3]bool // Office, neighbor, far
+	}{
+		{"empty", Condition{}, [3]bool{true, true, true}},
+		{"ssid pattern", Condition{SSID: "Corp*"}, [3]bool{false, true, true}},
+		{"ssid exact", Condition{SSID: "Corp"}, [3]bool{false, false, true}},
+		{"bssid case-insensitive", Condition{BSSID: "00:11:32:aa:*"}, [3]bool{true, false, false}},
+		{"band", Condition{Band: "2.4G"}, [3]bool{false, false, false}},
+		{"channel", Condition{Channel: 44}, [3]bool{false, true, false}},
+		{"security label", Condition{Security: "WPA2 Personal"}, [3]bool{true, false, false}},
+		{"security label case and hyphens", Condition{Security: "wpa2-personal"}, [3]bool{true, false, false}},
+		{"security label is exact", Condition{Security: "WPA2"}, [3]bool{false, false, false}},
+		{"security class open", Condition{Security: "Open"}, [3]bool{false, true, false}},
+		{"security class personal", Condition{Security: "personal"}, [3]bool{true, false, false}},
+		{"security class enterprise", Condition{Security: "Enterprise"}, [3]bool{false, false, true}},
+		{"security class unknown", Condition{Security: "Unknown"}, [3]bool{false, false, false}},
+		{"signal above", Condition{SignalAbove: intPtr(-70)}, [3]bool{true, false, false}},
+		{"signal below", Condition{SignalBelow: intPtr(-70)}, [3]bool{false, false, true}},
+		{"signal range", Condition{SignalAbove: intPtr(-75), SignalBelow: intPtr(-60)}, [3]bool{false, true, false}},
+		{"congestion or worse", Condition{Congestion: "medium"}, [3]bool{true, true, false}},
+		{"congestion very high", Condition{Congestion: "Very High"}, [3]bool{false, true, false}},
+		{"connected", Condition{Connected: boolPtr(true)}, [3]bool{true, false, false}},
+		{"not connected", Condition{Connected: boolPtr(false)}, [3]bool{false, true, true}},
+		{"on connected channel", Condition{OnConnectedChannel: boolPtr(true)}, [3]bool{false, true, false}},
+		{"off connected channel", Condition{OnConnectedChannel: boolPtr(false)}, [3]bool{true, false, true}},
+		{"every field must match", Condition{SSID: "Corp*", Security: "Open", Channel: 149}, [3]bool{false, false, false}},
+	}
+
+	for _, test := range tests {
+		if err := test.condition.validate(); err != nil {
+			t.Errorf("%s: validate: %v", test.name, err)
+			continue
+		}
+		for i, network := range []scanner.WiFiNetwork{office, neighbor, far} {
+			if got := test.condition.Match(network, connected); got != test.want[i] {
+				t.Errorf("%s: Match(%s) = %v, want %v", test.name, network.SSID, got, test.want[i])
+			}
+		}
+	}
+}
+
+func TestConditionMatchWithoutConnection(t *testing.T) {
+	_, neighbor, _ := matchNetworks()
+	// Nothing overlaps the channel of a network this host is not connected to
+	condition := Condition{OnConnectedChannel: boolPtr(true)}
+	if condition.Match(neighbor, changes.NewConnected([]scanner.WiFiNetwork{neighbor})) {
+		t.Error("matched on_connected_channel without a connected network")
+	}
+}
+
+func TestConditionValidate(t *testing.T) {
+	for _, condition := range []Condition{
+		{Event: "vanished"},
+		{Congestion: "Extreme"},
+		{SSID: "Corp["},
+		{BSSID: `00:11\`},
+	} {
+		if err := condition.validate(); err == nil {
+			t.Errorf("validate(%+v) succeeded", condition)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/syslog"
+)
+
+// syslogAction writes notifications to the local syslog daemon: firing alerts
+// at warning priority and resolutions at notice
+type syslogAction struct {
+	writer *syslog.Writer
+}
+
+func newSyslogAction(config ActionConfig) (Action, error) {
+	tag := config.Tag
+	if tag == "" {
+		tag = "wifi-bander"
+	}
+	writer, err := syslog.New(syslog.LOG_WARNING|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %v", err)
+	}
+	return syslogAction{writer: writer}, nil
+}
+
+// Notify writes the notification as one line
+func (a syslogAction) Notify(ctx context.Context, notification Notification) error {
+	if notification.State == StateResolved {
+		return a.writer.Notice(notification.String())
+	}
+	return a.writer.Warning(notification.String())
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package alert
+
+import "fmt"
+
+// newSyslogAction reports that syslog is not available; Windows has the Event Log instead
+func newSyslogAction(config ActionConfig) (Action, error) {
+	return nil, fmt.Errorf("syslog actions are not supported on Windows; use a command or webhook action")
+}
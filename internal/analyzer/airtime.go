@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"math"
+
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+// airtimeScorer estimates the share of time the medium in a block is busy. Each
+// audible neighbor keeps it busy for its airtime times the share of its
+// transmissions that land in the block, since any of them makes a transmission
+// across the whole block wait. Suited to dense deployments that advertise BSS Load.
+type airtimeScorer struct {
+	weights Weights
+}
+
+func (s airtimeScorer) Name() string     { return "airtime" }
+func (s airtimeScorer) Weights() Weights { return s.weights }
+
+// CongestionScore is the busy share of the network's own block, itself included
+func (s airtimeScorer) CongestionScore(network WiFiNetwork, occupancy Occupancy) float64 {
+	var neighbors []OccupiedBlock
+	for _, neighbor := range occupancy {
+		if neighbor.Band == network.GetBand() {
+			neighbors = append(neighbors, neighbor)
+		}
+	}
+	busy := s.busyFraction(GetOccupiedBlock(network), network.GetChannel(), neighbors)
+	return busy * s.weights.AirtimeScale
+}
+
+// CandidateScore is the busy share of the candidate block plus the channel preferences
+func (s airtimeScorer) CandidateScore(candidate Candidate, analysis map[int]*NetworkAnalysis) float64 {
+	var neighbors []OccupiedBlock
+	for _, net := range analysis {
+		neighbors = append(neighbors, net.Occupied...)
+	}
+	busy := s.busyFraction(candidate.Block, candidate.Channel.Number, neighbors)
+	return busy*s.weights.AirtimeScale + preferencePenalty(candidate, s.weights)
+}
+
+// channelLoad accumulates the airtime seen on one primary channel
+type channelLoad struct {
+	measured    float64 // Highest BSS Load utilization share
+	hasMeasured bool
+	estimated   float64 // Estimated APs combined as independent sources
+}
+
+// busyFraction estimates how much of the time the medium in block is busy for a
+// network with the given primary. APs sharing a primary sense the same medium, so
+// a measured utilization already includes the others on it: the highest stands for
+// the channel. Channels then combine as independent sources.
+func (s airtimeScorer) busyFraction(block spectrum.Block, primary int, neighbors []OccupiedBlock) float64 {
+	loads := make(map[int]*channelLoad)
+	for _, neighbor := range neighbors {
+		overlap := block.Overlap(neighbor.Block)
+		if overlap == 0 || !s.audible(neighbor, primary) {
+			continue
+		}
+
+		load, ok := loads[neighbor.Channel]
+		if !ok {
+			load = &channelLoad{}
+			loads[neighbor.Channel] = load
+		}
+		share := s.airtime(neighbor) * neighbor.Presence * float64(overlap) / float64(neighbor.Block.Width)
+		if neighbor.Measured {
+			load.measured = math.Max(load.measured, share)
+			load.hasMeasured = true
+		} else {
+			load.estimated = 1 - (1-load.estimated)*(1-share)
+		}
+	}
+
+	free := 1.0
+	for _, load := range loads {
+		if load.hasMeasured {
+			free *= 1 - load.measured
+		} else {
+			free *= 1 - load.estimated
+		}
+	}
+	return 1 - free
+}
+
+// airtime is the share of time a neighbor occupies its channel: its BSS Load
+// utilization, otherwise a guess from its estimated station count
+func (s airtimeScorer) airtime(neighbor OccupiedBlock) float64 {
+	if neighbor.Measured {
+		return math.Min(float64(neighbor.Utilization)/100, 1)
+	}
+	return math.Min(float64(neighbor.Stations)*s.weights.StationAirtime, 1)
+}
+
+// audible reports whether a neighbor is strong enough to defer to: preamble
+// detection applies on the same primary channel, energy detection elsewhere
+func (s airtimeScorer) audible(neighbor OccupiedBlock, primary int) bool {
+	threshold := s.weights.EnergyDetectDBm
+	if neighbor.Channel == primary {
+		threshold = s.weights.PreambleDetectDBm
+	}
+	return float64(neighbor.Signal) >= threshold
+}
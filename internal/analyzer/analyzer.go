@@ -2,24 +2,28 @@ package analyzer
 
 import (
 	"fmt"
+	"math"
 	"sort"
-)
+	"strings"
 
-// ChannelInfo represents aggregated information about a WiFi channel
-// This should match the one in scanner package
-type ChannelInfo struct {
-	Channel       int
-	NetworkCount  int
-	StrongestRSSI int
-}
+	"github.com/svgreg/wifi-bander/internal/regdb"
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
 
 // WiFiNetwork interface to avoid circular imports
 type WiFiNetwork interface {
+	GetSSID() string
+	GetBSSID() string
 	GetBand() string
 	GetChannel() int
 	GetSignal() int
 	GetStationCount() int
+	HasBSSLoad() bool
+	GetChannelUtilization() int
 	GetChannelWidth() string
+	GetFrequency() int
+	GetCenterFrequency() int
+	GetSecondaryOffset() int
 }
 
 // NetworkInfo is a minimal struct for networks used in analysis
@@ -35,118 +39,92 @@ func (n NetworkInfo) GetChannel() int      { return n.Channel }
 func (n NetworkInfo) GetSignal() int       { return n.Signal }
 func (n NetworkInfo) GetStationCount() int { return n.StationCount }
 
-// Comprehensive channel definitions by region/standard
-var (
-	// 2.4GHz channels (by region)
-	Channels24GHz_US     = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
-	Channels24GHz_Europe = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}
-	Channels24GHz_Japan  = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14}
-
-	// Non-overlapping 2.4GHz channels (universal optimum)
-	Channels24GHz_NonOverlapping = []int{1, 6, 11}
+// Non-overlapping 2.4GHz channels (universal optimum). The band plan lives in the
+// spectrum package and each country's allowed channels in regdb.
+var Channels24GHz_NonOverlapping = []int{1, 6, 11}
 
-	// 5GHz channels (comprehensive list)
-	// UNII-1 Band (5.15-5.25 GHz) - Low power, indoor use
-	Channels5GHz_UNII1 = []int{36, 40, 44, 48}
+// OccupiedBlock is the spectrum one network occupies
+type OccupiedBlock struct {
+	Band    string
+	Channel int // Primary channel
+	Block   spectrum.Block
+	Signal  int
 
-	// UNII-2A Band (5.25-5.35 GHz) - DFS required
-	Channels5GHz_UNII2A = []int{52, 56, 60, 64}
+	Stations    int  // Associated stations, a signal-based estimate unless Measured
+	Utilization int  // Channel utilization percentage from BSS Load
+	Measured    bool // Stations and Utilization come from an advertised BSS Load element
 
-	// UNII-2C Band (5.47-5.725 GHz) - DFS required
-	Channels5GHz_UNII2C = []int{100, 104, 108, 112, 116, 120, 124, 128, 132, 136, 140, 144}
+	Presence float64 // Share of the scored period the network was present, 1 for a single scan
+}
 
-	// UNII-3 Band (5.725-5.875 GHz) - Higher power, outdoor use
-	Channels5GHz_UNII3 = []int{149, 153, 157, 161, 165}
+// Occupancy lists the spectrum every network in a scan occupies
+type Occupancy []OccupiedBlock
 
-	// UNII-4 Band (5.85-5.925 GHz) - Newer allocation
-	Channels5GHz_UNII4 = []int{169, 173, 177}
-)
+// NewOccupancy works out the occupied block of each network
+func NewOccupancy(networks []WiFiNetwork) Occupancy {
+	occupancy := make(Occupancy, 0, len(networks))
+	for _, network := range networks {
+		occupancy = append(occupancy, newOccupiedBlock(network))
+	}
+	return occupancy
+}
 
-// getAllAvailable5GHzChannels returns a comprehensive list of 5GHz channels
-func getAllAvailable5GHzChannels() []int {
-	var all []int
-	all = append(all, Channels5GHz_UNII1...)
-	all = append(all, Channels5GHz_UNII2A...)
-	all = append(all, Channels5GHz_UNII2C...)
-	all = append(all, Channels5GHz_UNII3...)
-	all = append(all, Channels5GHz_UNII4...)
-	return all
+// newOccupiedBlock records the spectrum and load of one network
+func newOccupiedBlock(network WiFiNetwork) OccupiedBlock {
+	return OccupiedBlock{
+		Band:        network.GetBand(),
+		Channel:     network.GetChannel(),
+		Block:       GetOccupiedBlock(network),
+		Signal:      network.GetSignal(),
+		Stations:    network.GetStationCount(),
+		Utilization: network.GetChannelUtilization(),
+		Measured:    network.HasBSSLoad(),
+		Presence:    presenceOf(network),
+	}
 }
 
-// getAllAvailable24GHzChannels returns channels 1-13 (European standard, most comprehensive)
-func getAllAvailable24GHzChannels() []int {
-	return Channels24GHz_Europe // Most comprehensive common standard
+// presenceReporter is implemented by networks aggregated over time
+type presenceReporter interface {
+	Presence() float64
 }
 
-// CalculateCongestionScore calculates the congestion score for a network
-func CalculateCongestionScore(network WiFiNetwork, channelMap interface{}) int {
-	// Convert interface{} to map[int]*ChannelInfo
-	var channelInfoMap map[int]*ChannelInfo
-	switch v := channelMap.(type) {
-	case map[int]*ChannelInfo:
-		channelInfoMap = v
-	default:
-		// If it's from scanner package, create a compatible map
-		return calculateWithGenericMap(network, channelMap)
+// presenceOf returns the share of time a network was present; a network from a
+// single scan was present throughout
+func presenceOf(network WiFiNetwork) float64 {
+	if reporter, ok := network.(presenceReporter); ok {
+		return reporter.Presence()
 	}
+	return 1
+}
 
-	score := 0
-	channel := network.GetChannel()
-
-	// Base score from number of networks on the same channel
-	if channelInfo, exists := channelInfoMap[channel]; exists {
-		score += channelInfo.NetworkCount * 10
+// GetOccupiedBlock returns the spectrum a network occupies: the advertised block
+// center when known, otherwise the standard bonded block for its primary channel,
+// width and secondary offset. Unknown widths use the band's usual assumption.
+func GetOccupiedBlock(network WiFiNetwork) spectrum.Block {
+	width, err := spectrum.ParseWidth(getChannelWidth(network))
+	if err != nil {
+		width = spectrum.Width20
 	}
-
-	// Add penalty for overlapping channels (2.4GHz)
-	if network.GetBand() == "2.4G" {
-		for i := -2; i <= 2; i++ {
-			if i == 0 {
-				continue
-			}
-			adjChannel := channel + i
-			if channelInfo, exists := channelInfoMap[adjChannel]; exists {
-				score += channelInfo.NetworkCount * 5
-			}
-		}
+	if center := network.GetCenterFrequency(); center != 0 {
+		return spectrum.Block{Center: center, Width: width}
 	}
 
-	// Use estimated station count
-	score += network.GetStationCount() * 8
-
-	// Penalty for strong signals
-	signal := network.GetSignal()
-	if signal > -50 {
-		score += 20
-	} else if signal > -70 {
-		score += 10
+	if channel, err := spectrum.ChannelFromFrequency(network.GetFrequency()); err == nil {
+		if block, err := spectrum.BondedBlock(channel, width, network.GetSecondaryOffset()); err == nil {
+			return block
+		}
 	}
-
-	return score
+	return spectrum.Block{Center: network.GetFrequency(), Width: spectrum.Width20}
 }
 
-// calculateWithGenericMap handles different ChannelInfo types via reflection-like approach
-func calculateWithGenericMap(network WiFiNetwork, channelMap interface{}) int {
-	score := 0
-
-	// This is a simplified version that works with any compatible map
-	// We'll extract the basic scoring logic without type dependencies
-
-	// Base station count scoring
-	score += network.GetStationCount() * 8
-
-	// Penalty for strong signals
-	signal := network.GetSignal()
-	if signal > -50 {
-		score += 20
-	} else if signal > -70 {
-		score += 10
+// CalculateCongestionScore rates how congested a network's spectrum is under a
+// scoring model, from the spectrum and load of its neighbors. A nil scorer means
+// the default model.
+func CalculateCongestionScore(network WiFiNetwork, occupancy Occupancy, scorer Scorer) int {
+	if scorer == nil {
+		scorer = DefaultScorer()
 	}
-
-	// Add a base congestion penalty
-	score += 25 // Default congestion assumption
-
-	return score
+	return int(math.Round(scorer.CongestionScore(network, occupancy)))
 }
 
 // getDetectedChannels extracts all channels actually detected during scanning
@@ -176,22 +154,50 @@ type ChannelRecommendation struct {
 	InterferenceLevel string
 	Reasoning         string
 	SignalImpact      float64
-	FrequencyGap      int // MHz to nearest neighbor
+	FrequencyGap      int    // MHz to nearest neighbor
+	Regulatory        string // Restrictions in the regulatory domain, e.g. "max 23 dBm, indoor only"
+
+	Width      spectrum.Width // Channel width to configure with Channel as the primary
+	Block      spectrum.Block // Spectrum occupied at that width
+	Throughput float64        // PHY rate relative to 20MHz
+	TradeOff   string         // Throughput gained against interference added compared with 20MHz
+
+	Model   string  // Scoring model that produced the recommendation
+	Weights Weights // Weights the model used
+
+	// Set for recommendations from a History
+	Scans           int     // Scans in the window
+	Stability       float64 // Time-weighted share of those scans that ranked this channel and width in the top 3
+	Confidence      float64 // Stability discounted while the window holds few scans
+	ConfidenceLevel string  // High, Medium or Low
 }
 
-// GetChannelRecommendations returns top 3 recommended channels per band based on advanced criteria
-func GetChannelRecommendations(networks []WiFiNetwork) map[string][]ChannelRecommendation {
+// GetChannelRecommendations returns the top 3 primary channel and width combinations per band, with
+// non-overlapping blocks so each is a real alternative. Only blocks the regulatory domain allows
+// are considered; a nil domain means the world domain and a nil scorer the default model.
+func GetChannelRecommendations(networks []WiFiNetwork, domain *regdb.Domain, scorer Scorer) map[string][]ChannelRecommendation {
+	if domain == nil {
+		domain = regdb.WorldDomain()
+	}
+	if scorer == nil {
+		scorer = DefaultScorer()
+	}
+
 	// Analyze current network landscape
 	channelAnalysis24 := analyzeChannelLandscape(networks, "2.4G")
 	channelAnalysis5 := analyzeChannelLandscape(networks, "5G")
+	channelAnalysis6 := analyzeChannelLandscape(networks, "6G")
 
 	recommendations := make(map[string][]ChannelRecommendation)
 
 	// Get 2.4GHz recommendations
-	recommendations["2.4G"] = getBest24GHzChannels(channelAnalysis24)
+	recommendations["2.4G"] = getBest24GHzChannels(channelAnalysis24, domain, scorer)
 
 	// Get 5GHz recommendations
-	recommendations["5G"] = getBest5GHzChannels(channelAnalysis5)
+	recommendations["5G"] = getBest5GHzChannels(channelAnalysis5, domain, scorer)
+
+	// Get 6GHz recommendations
+	recommendations["6G"] = getBest6GHzChannels(channelAnalysis6, domain, scorer)
 
 	return recommendations
 }
@@ -203,7 +209,9 @@ type NetworkAnalysis struct {
 	Signal        int
 	ChannelWidth  string
 	NetworkCount  int
+	Occupancy     float64 // Time-weighted network count, NetworkCount for a single scan
 	StrongestRSSI int
+	Occupied      []OccupiedBlock // Spectrum each network on the channel occupies
 }
 
 // analyzeChannelLandscape creates a comprehensive analysis of the current WiFi landscape
@@ -216,14 +224,20 @@ func analyzeChannelLandscape(networks []WiFiNetwork, band string) map[int]*Netwo
 		}
 
 		ch := network.GetChannel()
-		freq := channelToFrequency(ch)
+		freq, err := spectrum.Frequency(spectrum.Band(band), ch)
+		if err != nil {
+			continue
+		}
 		signal := network.GetSignal()
+		occupied := newOccupiedBlock(network)
 
 		if existing, exists := analysis[ch]; exists {
 			existing.NetworkCount++
+			existing.Occupancy += occupied.Presence
 			if signal > existing.StrongestRSSI {
 				existing.StrongestRSSI = signal
 			}
+			existing.Occupied = append(existing.Occupied, occupied)
 		} else {
 			analysis[ch] = &NetworkAnalysis{
 				Channel:       ch,
@@ -231,7 +245,9 @@ func analyzeChannelLandscape(networks []WiFiNetwork, band string) map[int]*Netwo
 				Signal:        signal,
 				ChannelWidth:  getChannelWidth(network),
 				NetworkCount:  1,
+				Occupancy:     occupied.Presence,
 				StrongestRSSI: signal,
+				Occupied:      []OccupiedBlock{occupied},
 			}
 		}
 	}
@@ -245,190 +261,73 @@ func getChannelWidth(network WiFiNetwork) string {
 		return width
 	}
 	// Default assumptions based on band
-	if network.GetBand() == "5G" {
+	if band := network.GetBand(); band == "5G" || band == "6G" {
 		return "80MHz"
 	}
 	return "20MHz"
 }
 
-// getBest24GHzChannels finds optimal 2.4GHz channels with sophisticated scoring
-func getBest24GHzChannels(analysis map[int]*NetworkAnalysis) []ChannelRecommendation {
-	// Available 2.4GHz channels (1-13, EU standard)
-	availableChannels := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}
-
+// getBest24GHzChannels finds optimal 2.4GHz channels and widths with sophisticated scoring
+func getBest24GHzChannels(analysis map[int]*NetworkAnalysis, domain *regdb.Domain, scorer Scorer) []ChannelRecommendation {
 	var recommendations []ChannelRecommendation
 
-	for _, ch := range availableChannels {
-		freq := channelToFrequency(ch)
-		score := calculate24GHzScore(ch, freq, analysis)
-
-		recommendation := ChannelRecommendation{
-			Channel:           ch,
-			Frequency:         freq,
-			Score:             score,
-			InterferenceLevel: getInterferenceLevel(score),
-			Reasoning:         getReasoning24GHz(ch, analysis),
-			SignalImpact:      calculateSignalImpact(ch, analysis),
-			FrequencyGap:      calculateFrequencyGap(freq, analysis),
-		}
-
-		recommendations = append(recommendations, recommendation)
-	}
-
-	// Sort by score (lower is better)
-	sort.Slice(recommendations, func(i, j int) bool {
-		return recommendations[i].Score < recommendations[j].Score
-	})
-
-	// Return top 3
-	if len(recommendations) > 3 {
-		recommendations = recommendations[:3]
-	}
-
-	return recommendations
-}
-
-// calculate24GHzScore calculates interference score for 2.4GHz channels
-func calculate24GHzScore(channel, frequency int, analysis map[int]*NetworkAnalysis) float64 {
-	score := 0.0
-
-	// Base score: prefer non-overlapping channels (1, 6, 11)
-	nonOverlapping := []int{1, 6, 11}
-	isNonOverlapping := false
-	for _, noCh := range nonOverlapping {
-		if channel == noCh {
-			isNonOverlapping = true
-			break
-		}
-	}
-
-	if !isNonOverlapping {
-		score += 20.0 // Penalty for overlapping channels
-	}
-
-	// Calculate interference from all existing networks
-	for _, net := range analysis {
-		freqDiff := abs(frequency - net.Frequency)
-
-		// Strong penalty for same channel
-		if freqDiff == 0 {
-			score += float64(net.NetworkCount) * 50.0
-			// Additional penalty for strong signals
-			if net.StrongestRSSI > -60 {
-				score += 30.0
+	for _, channel := range domain.Channels(spectrum.Band2GHz) {
+		narrow := 0.0
+		for _, candidate := range candidateBlocks(channel, domain) {
+			score := scorer.CandidateScore(candidate, analysis)
+			if candidate.Block.Width == spectrum.Width20 {
+				narrow = score
 			}
-			continue
-		}
-
-		// Adjacent channel interference (2.4GHz channels are 5MHz apart)
-		if freqDiff <= 15 { // Within 3 channels
-			interferenceWeight := 30.0 - (float64(freqDiff) * 2.0) // Decreases with distance
-			score += interferenceWeight * float64(net.NetworkCount)
-
-			// Signal strength impact
-			signalPenalty := calculateSignalPenalty(net.StrongestRSSI, freqDiff)
-			score += signalPenalty
+			reasoning := getReasoning24GHz(channel.Number, candidate.Block, analysis)
+			recommendations = append(recommendations, newRecommendation(candidate, score, narrow, reasoning, analysis, scorer))
 		}
 	}
 
-	return score
+	return pickBlocks(recommendations)
 }
 
-// getBest5GHzChannels finds optimal 5GHz channels
-func getBest5GHzChannels(analysis map[int]*NetworkAnalysis) []ChannelRecommendation {
-	// All available 5GHz channels
-	allChannels5G := getAllAvailable5GHzChannels()
-
+// getBest5GHzChannels finds optimal 5GHz channels and widths
+func getBest5GHzChannels(analysis map[int]*NetworkAnalysis, domain *regdb.Domain, scorer Scorer) []ChannelRecommendation {
+	// Every block the domain allows on every 5GHz channel
 	var recommendations []ChannelRecommendation
 
-	for _, ch := range allChannels5G {
-		freq := channelToFrequency(ch)
-		score := calculate5GHzScore(ch, freq, analysis)
-
-		recommendation := ChannelRecommendation{
-			Channel:           ch,
-			Frequency:         freq,
-			Score:             score,
-			InterferenceLevel: getInterferenceLevel(score),
-			Reasoning:         getReasoning5GHz(ch, analysis),
-			SignalImpact:      calculateSignalImpact(ch, analysis),
-			FrequencyGap:      calculateFrequencyGap(freq, analysis),
+	for _, channel := range domain.Channels(spectrum.Band5GHz) {
+		narrow := 0.0
+		for _, candidate := range candidateBlocks(channel, domain) {
+			score := scorer.CandidateScore(candidate, analysis)
+			if candidate.Block.Width == spectrum.Width20 {
+				narrow = score
+			}
+			reasoning := getReasoning5GHz(channel.Number, candidate.Block, candidate.Rule.DFS, analysis)
+			recommendations = append(recommendations, newRecommendation(candidate, score, narrow, reasoning, analysis, scorer))
 		}
-
-		recommendations = append(recommendations, recommendation)
 	}
 
-	// Sort by score (lower is better)
-	sort.Slice(recommendations, func(i, j int) bool {
-		return recommendations[i].Score < recommendations[j].Score
-	})
-
-	// Return top 3
-	if len(recommendations) > 3 {
-		recommendations = recommendations[:3]
-	}
-
-	return recommendations
+	return pickBlocks(recommendations)
 }
 
-// calculate5GHzScore calculates interference score for 5GHz channels
-func calculate5GHzScore(channel, frequency int, analysis map[int]*NetworkAnalysis) float64 {
-	score := 0.0
-
-	// Prefer non-DFS channels (UNII-1 and UNII-3)
-	isDFS := (channel >= 52 && channel <= 64) || (channel >= 100 && channel <= 144)
-	if isDFS {
-		score += 10.0 // Small penalty for DFS channels
-	}
-
-	// Calculate interference from existing networks
-	for _, net := range analysis {
-		freqDiff := abs(frequency - net.Frequency)
+// getBest6GHzChannels finds optimal 6GHz channels and widths
+func getBest6GHzChannels(analysis map[int]*NetworkAnalysis, domain *regdb.Domain, scorer Scorer) []ChannelRecommendation {
+	var recommendations []ChannelRecommendation
 
-		// Same channel penalty
-		if freqDiff == 0 {
-			score += float64(net.NetworkCount) * 40.0
-			if net.StrongestRSSI > -60 {
-				score += 25.0
+	for _, channel := range domain.Channels(spectrum.Band6GHz) {
+		narrow := 0.0
+		for _, candidate := range candidateBlocks(channel, domain) {
+			score := scorer.CandidateScore(candidate, analysis)
+			if candidate.Block.Width == spectrum.Width20 {
+				narrow = score
 			}
-			continue
-		}
-
-		// 5GHz interference calculation (considering 80MHz channel widths)
-		interferenceRange := 80 // MHz, typical 5GHz channel width
-		if freqDiff <= interferenceRange {
-			interferenceWeight := float64(interferenceRange-freqDiff) / 10.0
-			score += interferenceWeight * float64(net.NetworkCount)
-
-			signalPenalty := calculateSignalPenalty(net.StrongestRSSI, freqDiff)
-			score += signalPenalty * 0.8 // 5GHz less prone to interference
+			reasoning := getReasoning6GHz(channel.Number, candidate.Block, analysis)
+			recommendations = append(recommendations, newRecommendation(candidate, score, narrow, reasoning, analysis, scorer))
 		}
 	}
 
-	return score
-}
-
-// calculateSignalPenalty calculates penalty based on signal strength and frequency distance
-func calculateSignalPenalty(signalStrength, freqDiff int) float64 {
-	// Convert dBm to penalty weight (stronger signals cause more interference)
-	signalWeight := 0.0
-	if signalStrength > -40 {
-		signalWeight = 20.0
-	} else if signalStrength > -60 {
-		signalWeight = 10.0
-	} else if signalStrength > -80 {
-		signalWeight = 5.0
-	}
-
-	// Apply distance factor
-	distanceFactor := 1.0 / (1.0 + float64(freqDiff)/10.0)
-	return signalWeight * distanceFactor
+	return pickBlocks(recommendations)
 }
 
-// calculateSignalImpact calculates the signal impact score for a channel
-func calculateSignalImpact(channel int, analysis map[int]*NetworkAnalysis) float64 {
+// calculateSignalImpact calculates the signal impact score for a channel at freq MHz
+func calculateSignalImpact(freq int, analysis map[int]*NetworkAnalysis) float64 {
 	impact := 0.0
-	freq := channelToFrequency(channel)
 
 	for _, net := range analysis {
 		freqDiff := abs(freq - net.Frequency)
@@ -459,7 +358,7 @@ func calculateFrequencyGap(frequency int, analysis map[int]*NetworkAnalysis) int
 }
 
 // getReasoning24GHz provides reasoning for 2.4GHz channel recommendation
-func getReasoning24GHz(channel int, analysis map[int]*NetworkAnalysis) string {
+func getReasoning24GHz(channel int, candidate spectrum.Block, analysis map[int]*NetworkAnalysis) string {
 	nonOverlapping := []int{1, 6, 11}
 	isNonOverlapping := false
 	for _, noCh := range nonOverlapping {
@@ -470,6 +369,9 @@ func getReasoning24GHz(channel int, analysis map[int]*NetworkAnalysis) string {
 	}
 
 	if analysis[channel] == nil {
+		if neighbor := strongestOverlap(candidate, analysis); neighbor != nil {
+			return fmt.Sprintf("Fair: No networks on this channel, but %s", describeOverlap(*neighbor))
+		}
 		if isNonOverlapping {
 			return "Optimal: Non-overlapping channel with no detected networks"
 		}
@@ -486,10 +388,15 @@ func getReasoning24GHz(channel int, analysis map[int]*NetworkAnalysis) string {
 }
 
 // getReasoning5GHz provides reasoning for 5GHz channel recommendation
-func getReasoning5GHz(channel int, analysis map[int]*NetworkAnalysis) string {
-	isDFS := (channel >= 52 && channel <= 64) || (channel >= 100 && channel <= 144)
-
+func getReasoning5GHz(channel int, candidate spectrum.Block, isDFS bool, analysis map[int]*NetworkAnalysis) string {
 	if analysis[channel] == nil {
+		if neighbor := strongestOverlap(candidate, analysis); neighbor != nil {
+			dfsNote := ""
+			if isDFS {
+				dfsNote = ", DFS required"
+			}
+			return fmt.Sprintf("Fair: No networks on this channel, but %s%s", describeOverlap(*neighbor), dfsNote)
+		}
 		if isDFS {
 			return "Good: DFS channel with no detected networks, radar detection required"
 		}
@@ -511,6 +418,59 @@ func getReasoning5GHz(channel int, analysis map[int]*NetworkAnalysis) string {
 		status, net.NetworkCount, net.StrongestRSSI, dfsNote)
 }
 
+// getReasoning6GHz provides reasoning for 6GHz channel recommendation
+func getReasoning6GHz(channel int, candidate spectrum.Block, analysis map[int]*NetworkAnalysis) string {
+	isPSC := spectrum.Channel{Band: spectrum.Band6GHz, Number: channel}.IsPSC()
+
+	if analysis[channel] == nil {
+		if neighbor := strongestOverlap(candidate, analysis); neighbor != nil {
+			return fmt.Sprintf("Fair: No networks on this channel, but %s", describeOverlap(*neighbor))
+		}
+		if isPSC {
+			return "Excellent: Preferred Scanning Channel with no detected networks"
+		}
+		return "Good: No networks detected, but not a PSC so clients may be slower to find it"
+	}
+
+	net := analysis[channel]
+	status := "Fair"
+	if net.NetworkCount == 1 && net.StrongestRSSI < -70 {
+		status = "Good"
+	}
+
+	pscNote := ""
+	if isPSC {
+		pscNote = ", PSC"
+	}
+
+	return fmt.Sprintf("%s: %d network(s), strongest at %d dBm%s",
+		status, net.NetworkCount, net.StrongestRSSI, pscNote)
+}
+
+// strongestOverlap returns the strongest neighbor whose block overlaps the candidate block
+func strongestOverlap(candidate spectrum.Block, analysis map[int]*NetworkAnalysis) *OccupiedBlock {
+	var strongest *OccupiedBlock
+	for _, net := range analysis {
+		for i, neighbor := range net.Occupied {
+			if candidate.Overlap(neighbor.Block) == 0 {
+				continue
+			}
+			if strongest == nil || neighbor.Signal > strongest.Signal {
+				strongest = &net.Occupied[i]
+			}
+		}
+	}
+	return strongest
+}
+
+// describeOverlap explains a neighbor's overlap, e.g. "overlaps the 80MHz block of channel 36 (-62 dBm)"
+func describeOverlap(neighbor OccupiedBlock) string {
+	if neighbor.Block.Width == spectrum.Width20 {
+		return fmt.Sprintf("overlaps channel %d (%d dBm)", neighbor.Channel, neighbor.Signal)
+	}
+	return fmt.Sprintf("overlaps the %s block of channel %d (%d dBm)", neighbor.Block.Width, neighbor.Channel, neighbor.Signal)
+}
+
 // getInterferenceLevel converts score to human-readable interference level
 func getInterferenceLevel(score float64) string {
 	switch {
@@ -535,52 +495,35 @@ func abs(x int) int {
 	return x
 }
 
-// channelToFrequency converts channel to frequency - moved here for local access
-func channelToFrequency(channel int) int {
-	// 2.4GHz band (channels 1-14)
-	if channel >= 1 && channel <= 13 {
-		return 2412 + (channel-1)*5
+// GetChannelInfo returns detailed information about channel allocations,
+// with "allowed" listing each band's channels permitted in the domain
+func GetChannelInfo(domain *regdb.Domain) map[string]interface{} {
+	if domain == nil {
+		domain = regdb.WorldDomain()
 	}
-	if channel == 14 {
-		return 2484
-	}
-
-	// 5GHz bands
-	if channel >= 36 && channel <= 48 {
-		return 5180 + (channel-36)*5
-	}
-	if channel >= 52 && channel <= 64 {
-		return 5260 + (channel-52)*5
-	}
-	if channel >= 100 && channel <= 144 {
-		return 5500 + (channel-100)*5
-	}
-	if channel >= 149 && channel <= 165 {
-		return 5745 + (channel-149)*5
-	}
-	if channel >= 169 && channel <= 177 {
-		return 5845 + (channel-169)*5
-	}
-
-	return 2412 // Default fallback
-}
-
-// GetChannelInfo returns detailed information about channel allocations
-func GetChannelInfo() map[string]interface{} {
 	return map[string]interface{}{
 		"2.4GHz": map[string]interface{}{
 			"non_overlapping": Channels24GHz_NonOverlapping,
-			"us_channels":     Channels24GHz_US,
-			"eu_channels":     Channels24GHz_Europe,
-			"jp_channels":     Channels24GHz_Japan,
-		},
-		"5GHz": map[string]interface{}{
-			"unii_1":  Channels5GHz_UNII1,
-			"unii_2a": Channels5GHz_UNII2A,
-			"unii_2c": Channels5GHz_UNII2C,
-			"unii_3":  Channels5GHz_UNII3,
-			"unii_4":  Channels5GHz_UNII4,
-			"all":     getAllAvailable5GHzChannels(),
+			"all":             spectrum.ChannelNumbers(spectrum.Band2GHz),
+			"allowed":         domain.ChannelNumbers(spectrum.Band2GHz),
 		},
+		"5GHz": subBandInfo(spectrum.Band5GHz, domain),
+		"6GHz": subBandInfo(spectrum.Band6GHz, domain),
+	}
+}
+
+// subBandInfo lists a band's channels per sub-band, keyed like "unii_2a", plus "all", "allowed" and for 6GHz "psc"
+func subBandInfo(band spectrum.Band, domain *regdb.Domain) map[string]interface{} {
+	info := map[string]interface{}{
+		"all":     spectrum.ChannelNumbers(band),
+		"allowed": domain.ChannelNumbers(band),
+	}
+	for _, sub := range spectrum.SubBands(band) {
+		key := strings.ToLower(strings.ReplaceAll(sub.Name, "-", "_"))
+		info[key] = sub.Channels
+	}
+	if band == spectrum.Band6GHz {
+		info["psc"] = spectrum.PreferredScanningChannels()
 	}
+	return info
 }
@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+)
+
+// testNetwork is a scanned network as the analyzer sees it
+type testNetwork struct {
+	SSID, BSSID string
+	Band        string
+	Channel     int
+	Frequency   int
+	Width       string
+	Center      int // CenterFrequency
+	Secondary   int // SecondaryOffset
+	Signal      int
+	Stations    int
+	BSSLoad     bool
+	Utilization int
+	Security    string
+	Vendor      string
+}
+
+func (n testNetwork) GetSSID() string            { return n.SSID }
+func (n testNetwork) GetBSSID() string           { return n.BSSID }
+func (n testNetwork) GetBand() string            { return n.Band }
+func (n testNetwork) GetChannel() int            { return n.Channel }
+func (n testNetwork) GetSignal() int             { return n.Signal }
+func (n testNetwork) GetStationCount() int       { return n.Stations }
+func (n testNetwork) HasBSSLoad() bool           { return n.BSSLoad }
+func (n testNetwork) GetChannelUtilization() int { return n.Utilization }
+func (n testNetwork) GetChannelWidth() string    { return n.Width }
+func (n testNetwork) GetFrequency() int          { return n.Frequency }
+func (n testNetwork) GetCenterFrequency() int    { return n.Center }
+func (n testNetwork) GetSecondaryOffset() int    { return n.Secondary }
+func (n testNetwork) GetSecurity() string        { return n.Security }
+func (n testNetwork) GetVendor() string          { return n.Vendor }
+
+// on5G is a 20 MHz network on a 5 GHz channel
+func on5G(bssid string, channel, signal int) testNetwork {
+	return testNetwork{SSID: "Net-" + bssid, BSSID: bssid, Band: "5G", Channel: channel, Frequency: 5000 + 5*channel, Width: "20MHz", Signal: signal}
+}
+
+func TestCongestionScoreUsesBSSLoad(t *testing.T) {
+	weights := DefaultWeights()
+	busy := on5G("00:11:32:aa:bb:cc", 36, -80)
+	busy.Stations, busy.BSSLoad, busy.Utilization = 5, true, 60
+	estimated := on5G("00:11:32:aa:bb:cd", 36, -80)
+	estimated.Stations, estimated.Utilization = 5, 60 // Utilization without BSS Load is not trusted
+	crowded := busy
+	crowded.Stations = 50
+
+	tests := []struct {
+		name    string
+		network testNetwork
+		want    float64 // Load penalty on top of the network itself
+	}{
+		{"measured utilization and stations", busy, 60*weights.Utilization + 5*weights.MeasuredStation},
+		{"measured stations are capped", crowded, 60*weights.Utilization + weights.MaxMeasuredStations*weights.MeasuredStation},
+		{"estimated stations", estimated, 5 * weights.EstimatedStation},
+	}
+
+	for _, test := range tests {
+		occupancy := NewOccupancy([]WiFiNetwork{test.network})
+		got := float64(CalculateCongestionScore(test.network, occupancy, nil)) - weights.CoChannelNetwork
+		if got != test.want {
+			t.Errorf("%s: load penalty %.0f, want %.0f", test.name, got, test.want)
+		}
+	}
+
+	// The airtime model takes a measured channel as busy as it says, and guesses otherwise
+	airtime, err := NewScorer("airtime", weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := airtime.CongestionScore(busy, NewOccupancy([]WiFiNetwork{busy})); got != 60 {
+		t.Errorf("airtime congestion with 60%% utilization = %.1f, want 60", got)
+	}
+	if got, want := airtime.CongestionScore(estimated, NewOccupancy([]WiFiNetwork{estimated})), 5*weights.StationAirtime*weights.AirtimeScale; math.Abs(got-want) > 1e-9 {
+		t.Errorf("airtime congestion from 5 estimated stations = %.1f, want %.1f", got, want)
+	}
+}
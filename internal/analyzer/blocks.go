@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/svgreg/wifi-bander/internal/regdb"
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+// maxRecommendedWidth caps recommendations at 160MHz, since 320MHz needs Wi-Fi 7 at both ends
+const maxRecommendedWidth = spectrum.Width160
+
+// relativeThroughput is each width's PHY rate relative to 20MHz, from its data subcarriers (52/108/234/468)
+var relativeThroughput = map[spectrum.Width]float64{
+	spectrum.Width20:  1.0,
+	spectrum.Width40:  108.0 / 52.0,
+	spectrum.Width80:  234.0 / 52.0,
+	spectrum.Width160: 468.0 / 52.0,
+}
+
+// candidateBlocks lists the blocks the domain allows with channel as primary, 20MHz
+// first. 2.4GHz 40MHz blocks are tried with the secondary channel on either side.
+func candidateBlocks(channel spectrum.Channel, domain *regdb.Domain) []Candidate {
+	var candidates []Candidate
+	for _, width := range spectrum.Widths(channel.Band) {
+		if width > maxRecommendedWidth {
+			continue
+		}
+
+		offsets := []int{0}
+		if channel.Band == spectrum.Band2GHz && width == spectrum.Width40 {
+			offsets = []int{1, -1}
+		}
+		for _, offset := range offsets {
+			block, err := spectrum.BondedBlock(channel, width, offset)
+			if err != nil {
+				continue
+			}
+			if rule, ok := domain.Permit(block); ok {
+				candidates = append(candidates, Candidate{Channel: channel, Block: block, Rule: rule})
+			}
+		}
+	}
+	return candidates
+}
+
+// newRecommendation builds the recommendation for one candidate block. score is its
+// interference score and narrow the same primary channel's score at 20MHz; wider
+// blocks are credited for their throughput by the scorer's weights.
+func newRecommendation(candidate Candidate, score, narrow float64, reasoning string,
+	analysis map[int]*NetworkAnalysis, scorer Scorer) ChannelRecommendation {
+	freq := candidate.Channel.Frequency()
+	throughput := relativeThroughput[candidate.Block.Width]
+	weights := scorer.Weights()
+
+	return ChannelRecommendation{
+		Channel:           candidate.Channel.Number,
+		Frequency:         freq,
+		Score:             score - weights.ThroughputCredit*math.Log2(throughput),
+		InterferenceLevel: getInterferenceLevel(score),
+		Reasoning:         reasoning,
+		SignalImpact:      calculateSignalImpact(freq, analysis),
+		FrequencyGap:      calculateFrequencyGap(freq, analysis),
+		Regulatory:        candidate.Rule.String(),
+		Width:             candidate.Block.Width,
+		Block:             candidate.Block,
+		Throughput:        throughput,
+		TradeOff:          describeTradeOff(throughput, score, narrow),
+		Model:             scorer.Name(),
+		Weights:           weights,
+	}
+}
+
+// describeTradeOff compares a block's throughput and interference with 20MHz on the same primary
+func describeTradeOff(throughput, score, narrow float64) string {
+	if throughput == 1 {
+		return "20MHz: lowest throughput, most robust against interference"
+	}
+
+	gain := fmt.Sprintf("%.1fx the throughput of 20MHz", throughput)
+	level, narrowLevel := getInterferenceLevel(score), getInterferenceLevel(narrow)
+	switch {
+	case score <= narrow:
+		return gain + " with no added interference"
+	case level == narrowLevel:
+		return fmt.Sprintf("%s; interference rises %.0f points but stays %s", gain, score-narrow, level)
+	default:
+		return fmt.Sprintf("%s for %s interference instead of %s", gain, level, narrowLevel)
+	}
+}
+
+// pickBlocks returns the 3 best-scoring recommendations whose blocks do not overlap,
+// so the alternatives are not the same spectrum with another primary or width
+func pickBlocks(recommendations []ChannelRecommendation) []ChannelRecommendation {
+	// Sort by score (lower is better)
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Score < recommendations[j].Score
+	})
+
+	var picked []ChannelRecommendation
+	for _, rec := range recommendations {
+		overlaps := false
+		for _, chosen := range picked {
+			if chosen.Block.Overlap(rec.Block) > 0 {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+
+		picked = append(picked, rec)
+		if len(picked) == 3 {
+			break
+		}
+	}
+	return picked
+}
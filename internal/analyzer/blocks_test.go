@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/svgreg/wifi-bander/internal/regdb"
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+func TestCandidateBlocks(t *testing.T) {
+	tests := []struct {
+		country string
+		channel spectrum.Channel
+		want    []string // Width and center of each candidate block
+	}{
+		{"US", spectrum.Channel{Band: spectrum.Band5GHz, Number: 36}, []string{"20MHz@5180", "40MHz@5190", "80MHz@5210", "160MHz@5250"}},
+		{"US", spectrum.Channel{Band: spectrum.Band5GHz, Number: 144}, []string{"20MHz@5720", "40MHz@5710", "80MHz@5690"}},
+		{"US", spectrum.Channel{Band: spectrum.Band2GHz, Number: 6}, []string{"20MHz@2437", "40MHz@2447", "40MHz@2427"}},
+		{"US", spectrum.Channel{Band: spectrum.Band2GHz, Number: 1}, []string{"20MHz@2412", "40MHz@2422"}},
+		// 320MHz is left out even where the domain allows it
+		{"US", spectrum.Channel{Band: spectrum.Band6GHz, Number: 37}, []string{"20MHz@6135", "40MHz@6125", "80MHz@6145", "160MHz@6185"}},
+		// The world domain ends at 5835 MHz, before 165's 40MHz block does
+		{regdb.World, spectrum.Channel{Band: spectrum.Band5GHz, Number: 165}, []string{"20MHz@5825"}},
+	}
+
+	for _, test := range tests {
+		domain, err := regdb.Lookup(test.country)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, candidate := range candidateBlocks(test.channel, domain) {
+			got = append(got, fmt.Sprintf("%s@%d", candidate.Block.Width, candidate.Block.Center))
+		}
+		if strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("%s %s: candidates %v, want %v", test.country, test.channel, got, test.want)
+		}
+	}
+}
+
+func TestRecommendationsArePrimaryAndWidth(t *testing.T) {
+	us, err := regdb.Lookup("US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	networks := []WiFiNetwork{wide36(), on5G("02:00:00:00:00:02", 149, -55), on5G("02:00:00:00:00:03", 100, -75)}
+	recommendations := GetChannelRecommendations(networks, us, nil)
+
+	for band, recs := range recommendations {
+		if len(recs) != 3 {
+			t.Errorf("%s: %d recommendations, want 3", band, len(recs))
+		}
+		for i, rec := range recs {
+			channel := spectrum.Channel{Band: spectrum.Band(band), Number: rec.Channel}
+			block, err := spectrum.BondedBlock(channel, rec.Width, 0)
+			if channel.Band == spectrum.Band2GHz && rec.Width == spectrum.Width40 && block != rec.Block {
+				block, err = spectrum.BondedBlock(channel, rec.Width, -1)
+			}
+			if err != nil || block != rec.Block || rec.Frequency != channel.Frequency() {
+				t.Errorf("%s #%d: channel %d at %s occupies %+v, want %+v", band, i+1, rec.Channel, rec.Width, rec.Block, block)
+			}
+			if rec.Throughput != relativeThroughput[rec.Width] || rec.TradeOff == "" || rec.Regulatory == "" {
+				t.Errorf("%s #%d: throughput %.2f, trade-off %q, regulatory %q", band, i+1, rec.Throughput, rec.TradeOff, rec.Regulatory)
+			}
+			// The picks are alternatives, not the same spectrum twice
+			for _, other := range recs[:i] {
+				if other.Block.Overlap(rec.Block) > 0 {
+					t.Errorf("%s: channel %d at %s overlaps channel %d at %s", band, rec.Channel, rec.Width, other.Channel, other.Width)
+				}
+			}
+		}
+	}
+
+	// Bonding into the neighbor's 80MHz block never pays for its throughput
+	for _, rec := range recommendations["5G"] {
+		if rec.Block.Overlap(GetOccupiedBlock(wide36())) > 0 && rec.Width > spectrum.Width20 {
+			t.Errorf("recommended channel %d at %s inside the busy 36-48 block", rec.Channel, rec.Width)
+		}
+	}
+}
+
+func TestDescribeTradeOff(t *testing.T) {
+	tests := []struct {
+		throughput, score, narrow float64
+		want                      string
+	}{
+		{1, 40, 40, "20MHz: lowest throughput, most robust against interference"},
+		{relativeThroughput[spectrum.Width80], 10, 10, "4.5x the throughput of 20MHz with no added interference"},
+		{relativeThroughput[spectrum.Width40], 45, 30, "2.1x the throughput of 20MHz; interference rises 15 points but stays Low"},
+		{relativeThroughput[spectrum.Width160], 120, 10, "9.0x the throughput of 20MHz for High interference instead of Minimal"},
+	}
+
+	for _, test := range tests {
+		if got := describeTradeOff(test.throughput, test.score, test.narrow); got != test.want {
+			t.Errorf("describeTradeOff(%.2f, %.0f, %.0f) = %q, want %q", test.throughput, test.score, test.narrow, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/regdb"
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+// confidentScans is how many scans a window needs before stability is taken at face value
+const confidentScans = 6
+
+// History keeps a rolling window of observations per BSSID and channel, so that
+// recommendations rest on how the spectrum has been used over time rather than
+// on one snapshot whose top pick flips as neighbors' RSSI jitters
+type History struct {
+	Window   time.Duration // Scans older than this are dropped; zero keeps only the latest
+	HalfLife time.Duration // Older scans weigh less, halving every HalfLife; zero weighs the window evenly
+
+	scans  []time.Time
+	tracks map[trackKey]*track
+}
+
+// trackKey identifies one BSS on one channel; networks without a BSSID fall back to their SSID
+type trackKey struct {
+	BSSID   string
+	SSID    string
+	Band    string
+	Channel int
+}
+
+// track is one BSS's observations on one channel within the window
+type track struct {
+	samples []sample
+}
+
+// sample is one observation of a BSS
+type sample struct {
+	at      time.Time
+	network WiFiNetwork
+}
+
+// NewHistory creates a history over window, with scans halving in weight every half window
+func NewHistory(window time.Duration) *History {
+	return &History{Window: window, HalfLife: window / 2}
+}
+
+// Add records one scan taken at the given time and drops what has left the window.
+// A scan timestamped before the latest one, as after the clock was set back, counts
+// as part of the latest scan; only Reset starts afresh.
+func (h *History) Add(networks []WiFiNetwork, at time.Time) {
+	if n := len(h.scans); n > 0 && at.Before(h.scans[n-1]) {
+		at = h.scans[n-1]
+	}
+	if h.tracks == nil {
+		h.tracks = make(map[trackKey]*track)
+	}
+	if n := len(h.scans); n == 0 || at.After(h.scans[n-1]) {
+		h.scans = append(h.scans, at)
+	}
+
+	for _, network := range networks {
+		key := newTrackKey(network)
+		t, ok := h.tracks[key]
+		if !ok {
+			t = &track{}
+			h.tracks[key] = t
+		}
+		// The same BSS twice in one scan keeps its stronger observation
+		if n := len(t.samples); n > 0 && t.samples[n-1].at.Equal(at) {
+			if network.GetSignal() > t.samples[n-1].network.GetSignal() {
+				t.samples[n-1].network = network
+			}
+			continue
+		}
+		t.samples = append(t.samples, sample{at: at, network: network})
+	}
+
+	h.prune(at)
+}
+
+// Reset forgets every scan, as when a replayed session starts over
+func (h *History) Reset() {
+	h.scans, h.tracks = nil, nil
+}
+
+// newTrackKey identifies the network's BSS and channel
+func newTrackKey(network WiFiNetwork) trackKey {
+	key := trackKey{Band: network.GetBand(), Channel: network.GetChannel()}
+	if bssid := network.GetBSSID(); bssid != "" && bssid != "Unknown" {
+		key.BSSID = bssid
+	} else {
+		key.SSID = network.GetSSID()
+	}
+	return key
+}
+
+// prune drops scans and samples older than the window, keeping at least the latest scan
+func (h *History) prune(latest time.Time) {
+	cutoff := latest.Add(-h.Window)
+	first := 0
+	for first < len(h.scans)-1 && h.scans[first].Before(cutoff) {
+		first++
+	}
+	h.scans = h.scans[first:]
+	oldest := h.scans[0]
+
+	for key, t := range h.tracks {
+		kept := t.samples[:0]
+		for _, s := range t.samples {
+			if !s.at.Before(oldest) {
+				kept = append(kept, s)
+			}
+		}
+		t.samples = kept
+		if len(kept) == 0 {
+			delete(h.tracks, key)
+		}
+	}
+}
+
+// Scans returns how many scans the window holds
+func (h *History) Scans() int {
+	return len(h.scans)
+}
+
+// Span returns the time between the oldest and latest scan in the window
+func (h *History) Span() time.Duration {
+	if len(h.scans) == 0 {
+		return 0
+	}
+	return h.scans[len(h.scans)-1].Sub(h.scans[0])
+}
+
+// scanWeights weighs each scan by the interval it stands for, decayed by its age,
+// normalized to sum to 1. Scans at the same instant share the weight equally.
+func (h *History) scanWeights() map[time.Time]float64 {
+	weights := make(map[time.Time]float64, len(h.scans))
+	if len(h.scans) == 0 {
+		return weights
+	}
+	latest := h.scans[len(h.scans)-1]
+
+	total := 0.0
+	for i, at := range h.scans {
+		interval := 1.0
+		if i > 0 {
+			interval = at.Sub(h.scans[i-1]).Seconds()
+		} else if len(h.scans) > 1 {
+			interval = h.scans[1].Sub(at).Seconds()
+		}
+		weight := interval
+		if h.HalfLife > 0 {
+			weight *= math.Exp2(-latest.Sub(at).Seconds() / h.HalfLife.Seconds())
+		}
+		weights[at] = weight
+		total += weight
+	}
+
+	for at := range weights {
+		if total > 0 {
+			weights[at] /= total
+		} else {
+			weights[at] = 1 / float64(len(weights))
+		}
+	}
+	return weights
+}
+
+// historyNetwork is a BSS aggregated over the window: its latest observation with the
+// peak signal it reached and the time-weighted share of the window it was present
+type historyNetwork struct {
+	WiFiNetwork
+	peak     int
+	presence float64
+}
+
+func (n historyNetwork) GetSignal() int    { return n.peak }
+func (n historyNetwork) Presence() float64 { return n.presence }
+
+// Networks returns one network per BSSID and channel seen in the window, with its
+// peak signal and time-weighted presence
+func (h *History) Networks() []WiFiNetwork {
+	weights := h.scanWeights()
+
+	var networks []WiFiNetwork
+	for _, t := range h.tracks {
+		latest := t.samples[len(t.samples)-1].network
+		aggregated := historyNetwork{WiFiNetwork: latest, peak: latest.GetSignal()}
+		for _, s := range t.samples {
+			aggregated.presence += weights[s.at]
+			if signal := s.network.GetSignal(); signal > aggregated.peak {
+				aggregated.peak = signal
+			}
+		}
+		networks = append(networks, aggregated)
+	}
+
+	// Map iteration order is random; keep results reproducible
+	sort.Slice(networks, func(i, j int) bool {
+		a, b := newTrackKey(networks[i]), newTrackKey(networks[j])
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+		return a.BSSID+a.SSID < b.BSSID+b.SSID
+	})
+	return networks
+}
+
+// snapshot returns the networks observed in the scan at the given time
+func (h *History) snapshot(at time.Time) []WiFiNetwork {
+	var networks []WiFiNetwork
+	for _, t := range h.tracks {
+		for _, s := range t.samples {
+			if s.at.Equal(at) {
+				networks = append(networks, s.network)
+			}
+		}
+	}
+	return networks
+}
+
+// Recommendations ranks channels on time-weighted occupancy and peak signal over the
+// window. Each recommendation's stability is the time-weighted share of scans in
+// which its channel and width ranked in the top 3 on their own; its confidence is
+// that stability discounted while the window holds few scans.
+func (h *History) Recommendations(domain *regdb.Domain, scorer Scorer) map[string][]ChannelRecommendation {
+	recommendations := GetChannelRecommendations(h.Networks(), domain, scorer)
+	weights := h.scanWeights()
+
+	stability := make(map[string]map[recommendationKey]float64)
+	for at, weight := range weights {
+		for band, recs := range GetChannelRecommendations(h.snapshot(at), domain, scorer) {
+			if stability[band] == nil {
+				stability[band] = make(map[recommendationKey]float64)
+			}
+			for _, rec := range recs {
+				stability[band][recommendationKey{rec.Channel, rec.Width}] += weight
+			}
+		}
+	}
+
+	coverage := math.Min(1, float64(len(h.scans))/confidentScans)
+	for band, recs := range recommendations {
+		for i := range recs {
+			rec := &recs[i]
+			rec.Scans = len(h.scans)
+			rec.Stability = math.Min(1, stability[band][recommendationKey{rec.Channel, rec.Width}])
+			rec.Confidence = rec.Stability * coverage
+			rec.ConfidenceLevel = getConfidenceLevel(rec.Confidence)
+		}
+	}
+	return recommendations
+}
+
+// recommendationKey identifies a recommendation across scans by its primary channel and width
+type recommendationKey struct {
+	Channel int
+	Width   spectrum.Width
+}
+
+// getConfidenceLevel converts a confidence to a descriptive level
+func getConfidenceLevel(confidence float64) string {
+	switch {
+	case confidence >= 0.75:
+		return "High"
+	case confidence >= 0.4:
+		return "Medium"
+	default:
+		return "Low"
+	}
+}
@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryWindow(t *testing.T) {
+	h := NewHistory(time.Minute)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	office, neighbor := on5G("00:11:32:aa:bb:cc", 36, -50), on5G("02:00:00:00:00:01", 40, -70)
+
+	h.Add([]WiFiNetwork{office, neighbor}, start)
+	h.Add([]WiFiNetwork{office}, start.Add(30*time.Second))
+	if h.Scans() != 2 || h.Span() != 30*time.Second {
+		t.Fatalf("history holds %d scans over %v, want 2 over 30s", h.Scans(), h.Span())
+	}
+
+	// Older scans leave the window, taking networks seen only in them along
+	h.Add([]WiFiNetwork{office}, start.Add(90*time.Second))
+	if h.Scans() != 2 || len(h.Networks()) != 1 {
+		t.Errorf("after the window moved: %d scans, %d networks; want 2 and 1", h.Scans(), len(h.Networks()))
+	}
+}
+
+func TestHistorySameTime(t *testing.T) {
+	h := NewHistory(time.Minute)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Two radios reporting one BSS in one scan keep its stronger observation
+	h.Add([]WiFiNetwork{on5G("00:11:32:aa:bb:cc", 36, -60)}, at)
+	h.Add([]WiFiNetwork{on5G("00:11:32:aa:bb:cc", 36, -50)}, at)
+	networks := h.Networks()
+	if h.Scans() != 1 || len(networks) != 1 || networks[0].GetSignal() != -50 {
+		t.Errorf("history holds %d scans of %d networks, want 1 scan of the stronger observation", h.Scans(), len(networks))
+	}
+}
+
+func TestHistoryOutOfOrderAndReset(t *testing.T) {
+	h := NewHistory(time.Minute)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	office := on5G("00:11:32:aa:bb:cc", 36, -50)
+
+	h.Add([]WiFiNetwork{office}, start)
+	h.Add([]WiFiNetwork{office}, start.Add(20*time.Second))
+
+	// A scan from before the latest one, as after the clock was set back, joins the
+	// latest scan rather than wiping the history
+	h.Add([]WiFiNetwork{on5G("02:00:00:00:00:01", 40, -70)}, start.Add(10*time.Second))
+	if h.Scans() != 2 || h.Span() != 20*time.Second || len(h.Networks()) != 2 {
+		t.Errorf("after an out-of-order scan: %d scans over %v, %d networks; want 2 over 20s, 2 networks",
+			h.Scans(), h.Span(), len(h.Networks()))
+	}
+
+	h.Reset()
+	if h.Scans() != 0 || len(h.Networks()) != 0 {
+		t.Errorf("after Reset: %d scans, %d networks", h.Scans(), len(h.Networks()))
+	}
+	h.Add([]WiFiNetwork{office}, start)
+	if h.Scans() != 1 || len(h.Networks()) != 1 {
+		t.Errorf("after starting over: %d scans, %d networks", h.Scans(), len(h.Networks()))
+	}
+}
@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"math"
+
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+// overlapScorer is the default model: every network in or overlapping a block adds
+// a penalty in proportion to the MHz it shares, with networks on the same primary
+// channel weighed most and strong signals adding more
+type overlapScorer struct {
+	weights Weights
+}
+
+func (s overlapScorer) Name() string     { return "overlap" }
+func (s overlapScorer) Weights() Weights { return s.weights }
+
+// CongestionScore counts networks sharing the primary channel (itself included)
+// fully and others by the share of its block they overlap, then adds its load
+func (s overlapScorer) CongestionScore(network WiFiNetwork, occupancy Occupancy) float64 {
+	score := 0.0
+	own := GetOccupiedBlock(network)
+
+	for _, neighbor := range occupancy {
+		if neighbor.Band != network.GetBand() {
+			continue
+		}
+		overlap := own.Overlap(neighbor.Block)
+		if overlap == 0 {
+			continue
+		}
+		if neighbor.Channel == network.GetChannel() {
+			score += s.weights.CoChannelNetwork * neighbor.Presence
+		} else {
+			score += s.weights.OverlapNetwork * neighbor.Presence * float64(overlap) / float64(own.Width)
+		}
+	}
+
+	score += s.loadPenalty(network)
+
+	// Penalty for strong signals
+	signal := network.GetSignal()
+	if signal > -50 {
+		score += s.weights.StrongSignal
+	} else if signal > -70 {
+		score += s.weights.ModerateSignal
+	}
+
+	return score
+}
+
+// loadPenalty scores how busy the network's channel is from its BSS Load,
+// falling back to the estimated station count when none was advertised
+func (s overlapScorer) loadPenalty(network WiFiNetwork) float64 {
+	if !network.HasBSSLoad() {
+		return float64(network.GetStationCount()) * s.weights.EstimatedStation
+	}
+	stations := math.Min(float64(network.GetStationCount()), s.weights.MaxMeasuredStations)
+	return float64(network.GetChannelUtilization())*s.weights.Utilization + stations*s.weights.MeasuredStation
+}
+
+// CandidateScore calculates the interference score for a block from the networks
+// on its primary channel and every neighbor block it overlaps
+func (s overlapScorer) CandidateScore(candidate Candidate, analysis map[int]*NetworkAnalysis) float64 {
+	score := preferencePenalty(candidate, s.weights)
+
+	sameChannel, sameChannelStrong, signalScale := s.weights.SameChannel, s.weights.SameChannelStrong, 1.0
+	switch candidate.Channel.Band {
+	case spectrum.Band2GHz:
+		sameChannel, sameChannelStrong = s.weights.SameChannel24, s.weights.SameChannelStrong24
+	case spectrum.Band5GHz:
+		signalScale = s.weights.SignalScale5
+	case spectrum.Band6GHz:
+		signalScale = s.weights.SignalScale6
+	}
+
+	for _, net := range analysis {
+		// Strong penalty for same channel
+		if net.Channel == candidate.Channel.Number {
+			score += net.Occupancy * sameChannel
+			if net.StrongestRSSI > -60 {
+				score += sameChannelStrong
+			}
+			continue
+		}
+
+		// Overlapping blocks contend on the shared 20MHz channels; on 2.4GHz channels are
+		// 5MHz apart but 20MHz wide, and on 5/6GHz wide neighbors cover many candidates
+		for _, neighbor := range net.Occupied {
+			// Neighbors present for part of the time interfere for that part
+			overlap := overlapShare(candidate.Block, neighbor.Block) * neighbor.Presence
+			if overlap == 0 {
+				continue
+			}
+			score += s.weights.OverlapShare * overlap
+			score += s.signalPenalty(neighbor.Signal, overlap) * signalScale
+		}
+	}
+
+	return score
+}
+
+// signalPenalty calculates penalty based on signal strength and how many 20MHz channels overlap
+func (s overlapScorer) signalPenalty(signalStrength int, overlap float64) float64 {
+	// Convert dBm to penalty weight (stronger signals cause more interference)
+	signalWeight := 0.0
+	if signalStrength > -40 {
+		signalWeight = s.weights.SignalStrong
+	} else if signalStrength > -60 {
+		signalWeight = s.weights.SignalModerate
+	} else if signalStrength > -80 {
+		signalWeight = s.weights.SignalWeak
+	}
+
+	return signalWeight * overlap
+}
+
+// overlapShare returns how much of a neighbor's block the candidate overlaps, in 20MHz channels
+func overlapShare(candidate, block spectrum.Block) float64 {
+	return float64(candidate.Overlap(block)) / float64(spectrum.Width20)
+}
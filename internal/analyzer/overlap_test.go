@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/svgreg/wifi-bander/internal/regdb"
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+// wide36 is a network on channel 36 bonded to 80 MHz, covering channels 36-48
+func wide36() testNetwork {
+	network := on5G("02:00:00:00:00:01", 36, -70)
+	network.Width, network.Center = "80MHz", 5210
+	return network
+}
+
+func TestCandidateScorePenalizesOverlappedChannels(t *testing.T) {
+	us, err := regdb.Lookup("US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scorer := DefaultScorer()
+	weights := scorer.Weights()
+	analysis := analyzeChannelLandscape([]WiFiNetwork{wide36()}, "5G")
+
+	// Each 20MHz shared with the -70 dBm neighbor costs the overlap share and a weak signal penalty
+	perChannel := weights.OverlapShare + weights.SignalWeak*weights.SignalScale5
+	tests := []struct {
+		number int
+		width  spectrum.Width
+		want   float64 // Penalty from the neighbor
+	}{
+		{36, spectrum.Width20, weights.SameChannel},
+		{40, spectrum.Width20, perChannel},
+		{44, spectrum.Width20, perChannel},
+		{48, spectrum.Width20, perChannel},
+		{52, spectrum.Width20, 0},
+		{44, spectrum.Width40, 2 * perChannel},
+		{52, spectrum.Width80, 0}, // Adjacent block, touching at 5250 MHz
+		{56, spectrum.Width160, 4 * perChannel},
+	}
+
+	for _, test := range tests {
+		channel := spectrum.Channel{Band: spectrum.Band5GHz, Number: test.number}
+		block, err := spectrum.BondedBlock(channel, test.width, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rule, _ := us.Permit(block)
+		candidate := Candidate{Channel: channel, Block: block, Rule: rule}
+
+		penalty := scorer.CandidateScore(candidate, analysis) - scorer.CandidateScore(candidate, nil)
+		if penalty != test.want {
+			t.Errorf("channel %d at %s: penalty %.1f, want %.1f", test.number, test.width, penalty, test.want)
+		}
+	}
+}
+
+func TestCongestionScoreByOverlap(t *testing.T) {
+	scorer := DefaultScorer()
+	weights := scorer.Weights()
+	narrow := func(channel int) testNetwork { return on5G("02:00:00:00:00:02", channel, -80) }
+
+	tests := []struct {
+		name      string
+		network   WiFiNetwork
+		neighbors []WiFiNetwork
+		want      float64
+	}{
+		{"alone", narrow(40), nil, weights.CoChannelNetwork},
+		{"inside a wide neighbor", narrow(40), []WiFiNetwork{wide36()}, weights.CoChannelNetwork + weights.OverlapNetwork},
+		{"co-channel with a wide neighbor", narrow(36), []WiFiNetwork{wide36()}, 2 * weights.CoChannelNetwork},
+		{"beside a wide neighbor", narrow(52), []WiFiNetwork{wide36()}, weights.CoChannelNetwork},
+		// A 20MHz neighbor covers a quarter of an 80MHz block
+		{"wide over a narrow neighbor", func() WiFiNetwork { n := wide36(); n.Signal = -80; return n }(),
+			[]WiFiNetwork{narrow(44)}, weights.CoChannelNetwork + weights.OverlapNetwork/4},
+	}
+
+	for _, test := range tests {
+		occupancy := NewOccupancy(append([]WiFiNetwork{test.network}, test.neighbors...))
+		if got := scorer.CongestionScore(test.network, occupancy); got != test.want {
+			t.Errorf("%s: congestion %.1f, want %.1f", test.name, got, test.want)
+		}
+	}
+}
+
+func TestGetOccupiedBlock(t *testing.T) {
+	ht40 := testNetwork{Band: "2.4G", Channel: 6, Frequency: 2437, Width: "40MHz", Secondary: -1}
+	unknown := testNetwork{Band: "5G", Channel: 100, Frequency: 5500, Width: "Unknown"}
+
+	tests := []struct {
+		network WiFiNetwork
+		want    spectrum.Block
+	}{
+		{wide36(), spectrum.Block{Center: 5210, Width: spectrum.Width80}},
+		{ht40, spectrum.Block{Center: 2427, Width: spectrum.Width40}},
+		{unknown, spectrum.Block{Center: 5530, Width: spectrum.Width80}}, // 5/6GHz networks are assumed to use 80MHz
+		{on5G("02:00:00:00:00:03", 144, -60), spectrum.Block{Center: 5720, Width: spectrum.Width20}},
+	}
+
+	for _, test := range tests {
+		if got := GetOccupiedBlock(test.network); got != test.want {
+			t.Errorf("channel %d: block %+v, want %+v", test.network.GetChannel(), got, test.want)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/ie"
+)
+
+// AccessPoint is a network with the identity fields rogue detection compares
+type AccessPoint interface {
+	WiFiNetwork
+	GetSecurity() string
+	GetVendor() string
+}
+
+// Severity ranks rogue findings
+type Severity int
+
+const (
+	SeverityLow Severity = iota
+	SeverityMedium
+	SeverityHigh
+)
+
+// String returns the severity name
+func (s Severity) String() string {
+	switch s {
+	case SeverityHigh:
+		return "High"
+	case SeverityMedium:
+		return "Medium"
+	default:
+		return "Low"
+	}
+}
+
+// Kinds of rogue findings
+const (
+	FindingVendorMismatch   = "vendor-mismatch"
+	FindingSecurityMismatch = "security-mismatch"
+	FindingSpoofedBSSID     = "spoofed-bssid"
+	FindingStrongerTwin     = "stronger-twin"
+)
+
+// RogueFinding is a suspicious pattern among the BSSIDs advertising one SSID
+type RogueFinding struct {
+	SSID     string
+	Kind     string
+	Severity Severity
+	BSSIDs   []string // The BSSIDs the finding is about
+	Detail   string
+}
+
+// RogueDetector groups networks by SSID and flags patterns typical of rogue APs and
+// evil twins. It remembers BSSIDs across scans to spot a stronger twin appearing.
+type RogueDetector struct {
+	TwinMargin int           // dB a new BSSID must beat the established ones by to be a stronger twin
+	TwinWindow time.Duration // How long after its first sighting a twin stays flagged
+
+	ssids map[string]*ssidHistory
+}
+
+// rogueMemory is how long a BSSID that is no longer seen is remembered; one that
+// returns later counts as new
+const rogueMemory = time.Hour
+
+// ssidHistory records when an SSID and each of its BSSIDs were seen
+type ssidHistory struct {
+	since  time.Time // First scan the SSID was seen in
+	bssids map[string]*sighting
+}
+
+// sighting is the first and latest scan a BSSID was seen in
+type sighting struct {
+	first, last time.Time
+}
+
+// NewRogueDetector creates a detector flagging twins 6 dB stronger than the
+// established BSSIDs for 10 minutes after they appear
+func NewRogueDetector() *RogueDetector {
+	return &RogueDetector{TwinMargin: 6, TwinWindow: 10 * time.Minute}
+}
+
+// Analyze checks a scan taken at the given time and returns findings, most severe first
+func (d *RogueDetector) Analyze(networks []AccessPoint, at time.Time) []RogueFinding {
+	if d.ssids == nil {
+		d.ssids = make(map[string]*ssidHistory)
+	}
+
+	groups := make(map[string][]AccessPoint)
+	global := make(map[string]bool) // Octets 2-5 of every globally administered BSSID
+	for _, network := range networks {
+		bssid := strings.ToLower(network.GetBSSID())
+		if mac, ok := parseMAC(bssid); ok && mac[0]&0x02 == 0 {
+			global[bssid[3:14]] = true
+		}
+		// Hidden networks have no name to impersonate
+		if network.GetSSID() != "" {
+			groups[network.GetSSID()] = append(groups[network.GetSSID()], network)
+		}
+	}
+
+	var findings []RogueFinding
+	for ssid, group := range groups {
+		findings = append(findings, vendorMismatch(ssid, group)...)
+		findings = append(findings, securityMismatch(ssid, group)...)
+		findings = append(findings, spoofedBSSIDs(ssid, group, global)...)
+		findings = append(findings, d.strongerTwins(ssid, group, at)...)
+	}
+	d.forget(at)
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return findings[i].Severity > findings[j].Severity
+		}
+		if findings[i].SSID != findings[j].SSID {
+			return findings[i].SSID < findings[j].SSID
+		}
+		return findings[i].Kind < findings[j].Kind
+	})
+	return findings
+}
+
+// vendorMismatch flags an SSID served by access points from different vendors;
+// one deployment rarely mixes vendors, a rogue AP often does
+func vendorMismatch(ssid string, group []AccessPoint) []RogueFinding {
+	byVendor := make(map[string][]string)
+	for _, network := range group {
+		vendor := network.GetVendor()
+		if vendor == "" || vendor == "Unknown" {
+			continue
+		}
+		byVendor[vendor] = appendUnique(byVendor[vendor], network.GetBSSID())
+	}
+	if len(byVendor) < 2 {
+		return nil
+	}
+
+	var vendors, bssids []string
+	for vendor, members := range byVendor {
+		vendors = append(vendors, fmt.Sprintf("%s (%d)", vendor, len(members)))
+		bssids = append(bssids, members...)
+	}
+	sort.Strings(vendors)
+	sort.Strings(bssids)
+	return []RogueFinding{{
+		SSID:     ssid,
+		Kind:     FindingVendorMismatch,
+		Severity: SeverityMedium,
+		BSSIDs:   bssids,
+		Detail:   "Access points from different vendors: " + strings.Join(vendors, ", "),
+	}}
+}
+
+// securityMismatch flags an SSID advertised with incompatible security. An open
+// BSSID under a protected name is the classic evil twin; clients that remember the
+// name may join it without a prompt.
+func securityMismatch(ssid string, group []AccessPoint) []RogueFinding {
+	byClass := make(map[ie.SecurityClass][]AccessPoint)
+	for _, network := range group {
+		class := ie.ClassifySecurity(network.GetSecurity())
+		if class != ie.SecurityUnknown {
+			byClass[class] = append(byClass[class], network)
+		}
+	}
+	if len(byClass) < 2 {
+		return nil
+	}
+
+	finding := RogueFinding{SSID: ssid, Kind: FindingSecurityMismatch, Severity: SeverityMedium}
+	weakest := ie.SecurityEnterprise + 1
+	for class := range byClass {
+		if class < weakest {
+			weakest = class
+		}
+	}
+	for _, network := range byClass[weakest] {
+		finding.BSSIDs = appendUnique(finding.BSSIDs, network.GetBSSID())
+	}
+	sort.Strings(finding.BSSIDs)
+
+	var others []string
+	for class, members := range byClass {
+		if class != weakest {
+			others = appendUnique(others, members[0].GetSecurity())
+		}
+	}
+	sort.Strings(others)
+
+	weak := byClass[weakest][0].GetSecurity()
+	if weakest == ie.SecurityOpen {
+		finding.Severity = SeverityHigh
+		finding.Detail = fmt.Sprintf("%s network under an SSID protected elsewhere with %s", weak, strings.Join(others, ", "))
+	} else {
+		finding.Detail = fmt.Sprintf("%s alongside %s under one SSID", weak, strings.Join(others, ", "))
+	}
+	return []RogueFinding{finding}
+}
+
+// spoofedBSSIDs flags locally administered BSSIDs advertising an enterprise SSID
+// that genuine, globally administered access points also serve. APs derive
+// locally administered BSSIDs for extra SSIDs from their own address, so those
+// matching a nearby globally administered BSSID apart from the first and last
+// octet are not flagged.
+func spoofedBSSIDs(ssid string, group []AccessPoint, global map[string]bool) []RogueFinding {
+	enterprise, genuine := false, false
+	var local []string
+	for _, network := range group {
+		if ie.ClassifySecurity(network.GetSecurity()) == ie.SecurityEnterprise {
+			enterprise = true
+		}
+		bssid := strings.ToLower(network.GetBSSID())
+		mac, ok := parseMAC(bssid)
+		if !ok {
+			continue
+		}
+		if mac[0]&0x02 == 0 {
+			genuine = true
+		} else if !global[bssid[3:14]] {
+			local = appendUnique(local, network.GetBSSID())
+		}
+	}
+	if !enterprise || !genuine || len(local) == 0 {
+		return nil
+	}
+
+	sort.Strings(local)
+	return []RogueFinding{{
+		SSID:     ssid,
+		Kind:     FindingSpoofedBSSID,
+		Severity: SeverityHigh,
+		BSSIDs:   local,
+		Detail:   "Locally administered BSSID, not derived from a nearby AP, advertising an enterprise SSID",
+	}}
+}
+
+// strongerTwins flags BSSIDs that appeared for an already established SSID with a
+// signal well above the BSSIDs seen before them, as an attacker close to the victim
+// would be. A twin that also differs in security class is High severity.
+func (d *RogueDetector) strongerTwins(ssid string, group []AccessPoint, at time.Time) []RogueFinding {
+	history := d.ssids[ssid]
+	if history == nil {
+		history = &ssidHistory{since: at, bssids: make(map[string]*sighting)}
+		d.ssids[ssid] = history
+	}
+	for _, network := range group {
+		if seen, ok := history.bssids[network.GetBSSID()]; ok {
+			seen.last = at
+		} else {
+			history.bssids[network.GetBSSID()] = &sighting{first: at, last: at}
+		}
+	}
+
+	var findings []RogueFinding
+	for _, twin := range group {
+		first := history.bssids[twin.GetBSSID()].first
+		// BSSIDs of an SSID's first scan establish it
+		if !first.After(history.since) || at.Sub(first) > d.TwinWindow {
+			continue
+		}
+
+		strongest, security, found := 0, ie.SecurityUnknown, false
+		for _, other := range group {
+			if !history.bssids[other.GetBSSID()].first.Before(first) {
+				continue
+			}
+			if !found || other.GetSignal() > strongest {
+				strongest, security, found = other.GetSignal(), ie.ClassifySecurity(other.GetSecurity()), true
+			}
+		}
+		if !found || twin.GetSignal() < strongest+d.TwinMargin {
+			continue
+		}
+
+		finding := RogueFinding{
+			SSID:     ssid,
+			Kind:     FindingStrongerTwin,
+			Severity: SeverityMedium,
+			BSSIDs:   []string{twin.GetBSSID()},
+			Detail: fmt.Sprintf("Appeared %s ago at %d dBm, %d dB stronger than the BSSIDs seen before it",
+				at.Sub(first).Round(time.Second), twin.GetSignal(), twin.GetSignal()-strongest),
+		}
+		if class := ie.ClassifySecurity(twin.GetSecurity()); class != security && class != ie.SecurityUnknown {
+			finding.Severity = SeverityHigh
+			finding.Detail += ", with different security"
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// forget drops BSSIDs not seen for rogueMemory, and SSIDs left without any
+func (d *RogueDetector) forget(at time.Time) {
+	for ssid, history := range d.ssids {
+		for bssid, seen := range history.bssids {
+			if at.Sub(seen.last) > rogueMemory {
+				delete(history.bssids, bssid)
+			}
+		}
+		if len(history.bssids) == 0 {
+			delete(d.ssids, ssid)
+		}
+	}
+}
+
+// parseMAC parses a MAC address written as six colon-separated two-digit octets
+func parseMAC(s string) ([6]byte, bool) {
+	var mac [6]byte
+	parts := strings.Split(s, ":")
+	if len(parts) != 6 {
+		return mac, false
+	}
+	for i, part := range parts {
+		if len(part) != 2 {
+			return mac, false
+		}
+		octet, err := strconv.ParseUint(part, 16, 8)
+		if err != nil {
+			return mac, false
+		}
+		mac[i] = byte(octet)
+	}
+	return mac, true
+}
+
+// appendUnique appends s unless the list already holds it
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
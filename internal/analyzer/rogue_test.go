@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ap is an access point advertising ssid
+func ap(ssid, bssid string, signal int, security, vendor string) testNetwork {
+	return testNetwork{SSID: ssid, BSSID: bssid, Band: "5G", Channel: 36, Frequency: 5180, Width: "20MHz",
+		Signal: signal, Security: security, Vendor: vendor}
+}
+
+func TestRogueDetectorAnalyze(t *testing.T) {
+	// Scans at an offset from the first; findings of the last one are checked
+	type scan struct {
+		offset   time.Duration
+		networks []testNetwork
+	}
+	const (
+		genuine = "00:11:32:aa:bb:cc" // Globally administered
+		second  = "00:11:32:aa:bb:dd"
+		derived = "06:11:32:aa:bb:c1" // Locally administered, derived from genuine
+		spoofed = "02:de:ad:be:ef:01" // Locally administered, unrelated
+	)
+
+	tests := []struct {
+		name  string
+		scans []scan
+		want  []string // "kind severity bssids", most severe first
+	}{
+		{
+			name:  "one deployment",
+			scans: []scan{{0, []testNetwork{ap("Corp", genuine, -60, "WPA2 Enterprise", "Cisco"), ap("Corp", second, -70, "WPA2 Enterprise", "Cisco")}}},
+		},
+		{
+			name: "vendors differ",
+			scans: []scan{{0, []testNetwork{
+				ap("Corp", genuine, -60, "WPA2 Personal", "Cisco"),
+				ap("Corp", second, -70, "WPA2 Personal", "Aruba"),
+				ap("Corp", "00:11:32:aa:bb:ee", -75, "WPA2 Personal", "Unknown"),
+			}}},
+			want: []string{"vendor-mismatch Medium " + genuine + "," + second},
+		},
+		{
+			name:  "open under a protected SSID",
+			scans: []scan{{0, []testNetwork{ap("Corp", genuine, -60, "WPA2 Personal", ""), ap("Corp", second, -70, "Open", "")}}},
+			want:  []string{"security-mismatch High " + second},
+		},
+		{
+			name:  "OWE under a protected SSID",
+			scans: []scan{{0, []testNetwork{ap("Corp", genuine, -60, "WPA3 Personal", ""), ap("Corp", second, -70, "OWE", "")}}},
+			want:  []string{"security-mismatch High " + second},
+		},
+		{
+			name:  "personal under an enterprise SSID",
+			scans: []scan{{0, []testNetwork{ap("Corp", genuine, -60, "WPA2 Enterprise", ""), ap("Corp", second, -70, "WPA2 Personal", "")}}},
+			want:  []string{"security-mismatch Medium " + second},
+		},
+		{
+			name: "transition mode",
+			scans: []scan{{0, []testNetwork{
+				ap("Home", genuine, -60, "WPA2 Personal", ""),
+				ap("Home", second, -65, "WPA3 Personal", ""),
+				ap("Home", "00:11:32:aa:bb:ee", -70, "WPA2/WPA3 Personal", ""),
+			}}},
+		},
+		{
+			// iwlist cannot tell Personal from Enterprise
+			name:  "generation without key management",
+			scans: []scan{{0, []testNetwork{ap("Corp", genuine, -60, "WPA2 Enterprise", ""), ap("Corp", second, -70, "WPA2", "")}}},
+		},
+		{
+			name:  "spoofed BSSID under an enterprise SSID",
+			scans: []scan{{0, []testNetwork{ap("Corp", genuine, -60, "WPA2 Enterprise", ""), ap("Corp", spoofed, -70, "WPA2 Enterprise", "")}}},
+			want:  []string{"spoofed-bssid High " + spoofed},
+		},
+		{
+			name:  "locally administered BSSID derived from a nearby AP",
+			scans: []scan{{0, []testNetwork{ap("Corp", genuine, -60, "WPA2 Enterprise", ""), ap("Corp", derived, -60, "WPA2 Enterprise", "")}}},
+		},
+		{
+			name: "derived from an AP serving another SSID",
+			scans: []scan{{0, []testNetwork{
+				ap("Guest", genuine, -60, "Open", ""),
+				ap("Corp", second, -60, "WPA2 Enterprise", ""),
+				ap("Corp", derived, -60, "WPA2 Enterprise", ""),
+			}}},
+		},
+		{
+			name:  "locally administered BSSID under a personal SSID",
+			scans: []scan{{0, []testNetwork{ap("Home", genuine, -60, "WPA2 Personal", ""), ap("Home", spoofed, -70, "WPA2 Personal", "")}}},
+		},
+		{
+			name:  "BSSIDs of the first scan are established",
+			scans: []scan{{0, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", ""), ap("Home", second, -40, "WPA2 Personal", "")}}},
+		},
+		{
+			name: "stronger twin",
+			scans: []scan{
+				{0, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", "")}},
+				{time.Minute, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", ""), ap("Home", second, -64, "WPA2 Personal", "")}},
+			},
+			want: []string{"stronger-twin Medium " + second},
+		},
+		{
+			name: "new BSSID within the margin",
+			scans: []scan{
+				{0, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", "")}},
+				{time.Minute, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", ""), ap("Home", second, -65, "WPA2 Personal", "")}},
+			},
+		},
+		{
+			name: "stronger twin with different security",
+			scans: []scan{
+				{0, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", "")}},
+				{time.Minute, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", ""), ap("Home", second, -50, "Open", "")}},
+			},
+			want: []string{"security-mismatch High " + second, "stronger-twin High " + second},
+		},
+		{
+			name: "stronger twin within the window",
+			scans: []scan{
+				{0, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", "")}},
+				{time.Minute, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", ""), ap("Home", second, -60, "WPA2 Personal", "")}},
+				{11 * time.Minute, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", ""), ap("Home", second, -60, "WPA2 Personal", "")}},
+			},
+			want: []string{"stronger-twin Medium " + second},
+		},
+		{
+			name: "stronger twin after the window",
+			scans: []scan{
+				{0, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", "")}},
+				{time.Minute, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", ""), ap("Home", second, -60, "WPA2 Personal", "")}},
+				{11*time.Minute + time.Second, []testNetwork{ap("Home", genuine, -70, "WPA2 Personal", ""), ap("Home", second, -60, "WPA2 Personal", "")}},
+			},
+		},
+		{
+			name: "hidden networks",
+			scans: []scan{{0, []testNetwork{
+				ap("", genuine, -60, "WPA2 Personal", "Cisco"),
+				ap("", spoofed, -70, "Open", "Aruba"),
+			}}},
+		},
+	}
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			detector := NewRogueDetector()
+			var findings []RogueFinding
+			for _, scan := range test.scans {
+				networks := make([]AccessPoint, len(scan.networks))
+				for i, network := range scan.networks {
+					networks[i] = network
+				}
+				findings = detector.Analyze(networks, start.Add(scan.offset))
+			}
+
+			var got []string
+			for _, finding := range findings {
+				got = append(got, fmt.Sprintf("%s %s %s", finding.Kind, finding.Severity, strings.Join(finding.BSSIDs, ",")))
+				if finding.Detail == "" {
+					t.Errorf("%s finding without detail", finding.Kind)
+				}
+			}
+			if strings.Join(got, "; ") != strings.Join(test.want, "; ") {
+				t.Errorf("findings %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRogueDetectorForgetsBSSIDs(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	home := ap("Home", "00:11:32:aa:bb:cc", -70, "WPA2 Personal", "")
+	twin := ap("Home", "00:11:32:aa:bb:dd", -50, "WPA2 Personal", "")
+
+	for _, test := range []struct {
+		absent time.Duration // How long the established twin is gone
+		want   int
+	}{
+		{time.Minute, 0},
+		{2 * time.Hour, 1}, // Forgotten, so it returns as a new BSSID
+	} {
+		detector := NewRogueDetector()
+		detector.Analyze([]AccessPoint{home, twin}, start)
+		detector.Analyze([]AccessPoint{home}, start.Add(test.absent))
+		findings := detector.Analyze([]AccessPoint{home, twin}, start.Add(test.absent+time.Minute))
+		if len(findings) != test.want || (test.want > 0 && findings[0].Kind != FindingStrongerTwin) {
+			t.Errorf("twin back after %v: findings %+v, want %d stronger twin", test.absent, findings, test.want)
+		}
+	}
+}
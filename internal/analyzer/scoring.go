@@ -0,0 +1,258 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/svgreg/wifi-bander/internal/regdb"
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+// DefaultModel is the scoring model used when none is chosen
+const DefaultModel = "overlap"
+
+// ErrUnknownModel is returned (wrapped) for a scoring model name that is not registered
+var ErrUnknownModel = errors.New("unknown scoring model")
+
+// Scorer turns a scan into scores; lower is better. CongestionScore rates a scanned
+// network, CandidateScore a block the user could configure. Neither includes the
+// throughput credit for wider blocks, which recommendations add from the weights.
+type Scorer interface {
+	Name() string
+	Weights() Weights
+	CongestionScore(network WiFiNetwork, occupancy Occupancy) float64
+	CandidateScore(candidate Candidate, analysis map[int]*NetworkAnalysis) float64
+}
+
+// Candidate is a block a primary channel can anchor, with the regulatory rule covering it
+type Candidate struct {
+	Channel spectrum.Channel
+	Block   spectrum.Block
+	Rule    regdb.Rule
+}
+
+// Weights are the tunable constants of the scoring models. Each model uses the
+// subset it needs; a weights file only has to list the ones it changes.
+type Weights struct {
+	// Congestion of scanned networks (overlap model)
+	CoChannelNetwork    float64 `json:"co_channel_network"`    // Per network sharing the primary channel
+	OverlapNetwork      float64 `json:"overlap_network"`       // Per overlapping network, times the share of the block it covers
+	StrongSignal        float64 `json:"strong_signal"`         // Own signal above -50 dBm
+	ModerateSignal      float64 `json:"moderate_signal"`       // Own signal above -70 dBm
+	Utilization         float64 `json:"utilization"`           // Per percent of BSS Load channel utilization
+	MeasuredStation     float64 `json:"measured_station"`      // Per station advertised in BSS Load
+	MaxMeasuredStations float64 `json:"max_measured_stations"` // Advertised stations counted at most
+	EstimatedStation    float64 `json:"estimated_station"`     // Per estimated station without BSS Load
+
+	// Channel recommendations (overlap model)
+	SameChannel24       float64 `json:"same_channel_24"`        // Per 2.4GHz network on the candidate primary
+	SameChannelStrong24 float64 `json:"same_channel_strong_24"` // Once, if one of them is above -60 dBm
+	SameChannel         float64 `json:"same_channel"`           // Per 5/6GHz network on the candidate primary
+	SameChannelStrong   float64 `json:"same_channel_strong"`    // Once, if one of them is above -60 dBm
+	OverlapShare        float64 `json:"overlap_share"`          // Per 20MHz a neighbor's block shares with the candidate
+	SignalStrong        float64 `json:"signal_strong"`          // Per 20MHz shared with a neighbor above -40 dBm
+	SignalModerate      float64 `json:"signal_moderate"`        // ... above -60 dBm
+	SignalWeak          float64 `json:"signal_weak"`            // ... above -80 dBm
+	SignalScale5        float64 `json:"signal_scale_5"`         // Signal penalty multiplier on 5GHz
+	SignalScale6        float64 `json:"signal_scale_6"`         // Signal penalty multiplier on 6GHz
+
+	// Channel preferences (all models)
+	OverlappingChannel24 float64 `json:"overlapping_channel_24"` // 2.4GHz primary other than 1, 6 or 11
+	Wide24               float64 `json:"wide_24"`                // 40MHz on 2.4GHz
+	DFS                  float64 `json:"dfs"`                    // Block needs DFS
+	NonPSC               float64 `json:"non_psc"`                // 6GHz primary that is not a Preferred Scanning Channel
+	PowerPerDB           float64 `json:"power_per_db"`           // Per dB the EIRP limit is below 20 dBm
+	ThroughputCredit     float64 `json:"throughput_credit"`      // Credit per doubling of throughput over 20MHz
+
+	// Airtime model
+	AirtimeScale      float64 `json:"airtime_scale"`       // Score of a medium that is always busy
+	StationAirtime    float64 `json:"station_airtime"`     // Airtime share per estimated station without BSS Load
+	PreambleDetectDBm float64 `json:"preamble_detect_dbm"` // Neighbors on the same primary defer above this signal
+	EnergyDetectDBm   float64 `json:"energy_detect_dbm"`   // Other overlapping neighbors defer above this signal
+}
+
+// DefaultWeights returns the weights the models are tuned with
+func DefaultWeights() Weights {
+	return Weights{
+		CoChannelNetwork:    10,
+		OverlapNetwork:      10,
+		StrongSignal:        20,
+		ModerateSignal:      10,
+		Utilization:         0.5,
+		MeasuredStation:     1,
+		MaxMeasuredStations: 20,
+		EstimatedStation:    8,
+
+		SameChannel24:       50,
+		SameChannelStrong24: 30,
+		SameChannel:         40,
+		SameChannelStrong:   25,
+		OverlapShare:        30,
+		SignalStrong:        20,
+		SignalModerate:      10,
+		SignalWeak:          5,
+		SignalScale5:        0.8, // 5GHz less prone to interference
+		SignalScale6:        0.7, // Higher path loss at 6GHz
+
+		OverlappingChannel24: 20,
+		Wide24:               20, // 40MHz takes two of the three non-overlapping channels
+		DFS:                  10,
+		NonPSC:               15, // Slower client discovery
+		PowerPerDB:           3,
+		ThroughputCredit:     12, // One doubling is worth less than one more busy 20MHz channel
+
+		AirtimeScale:      100,
+		StationAirtime:    0.04,
+		PreambleDetectDBm: -82,
+		EnergyDetectDBm:   -62,
+	}
+}
+
+// LoadWeights reads a JSON object of weights; weights the file leaves out keep
+// their defaults and unknown names are an error, so typos are not silently ignored
+func LoadWeights(path string) (Weights, error) {
+	weights := DefaultWeights()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return weights, fmt.Errorf("reading weights: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&weights); err != nil {
+		return weights, fmt.Errorf("parsing weights %s: %w", path, err)
+	}
+	return weights, nil
+}
+
+// String lists the weights that differ from the defaults as "name=value", or "default weights"
+func (w Weights) String() string {
+	defaults := reflect.ValueOf(DefaultWeights())
+	value := reflect.ValueOf(w)
+
+	var changed []string
+	for i := 0; i < value.NumField(); i++ {
+		if value.Field(i).Float() == defaults.Field(i).Float() {
+			continue
+		}
+		name, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+		changed = append(changed, name+"="+strconv.FormatFloat(value.Field(i).Float(), 'g', -1, 64))
+	}
+	if len(changed) == 0 {
+		return "default weights"
+	}
+	return strings.Join(changed, ", ")
+}
+
+// ScoringModel describes a named Scorer implementation
+type ScoringModel struct {
+	Name        string
+	Description string
+	New         func(weights Weights) Scorer
+}
+
+// models holds the known scoring models, the default first
+var models = []ScoringModel{
+	{
+		Name:        "overlap",
+		Description: "penalties per network by the MHz its block overlaps, co-channel networks weighed most",
+		New:         func(weights Weights) Scorer { return overlapScorer{weights} },
+	},
+	{
+		Name:        "airtime",
+		Description: "share of time audible neighbors keep the block busy, from BSS Load where advertised",
+		New:         func(weights Weights) Scorer { return airtimeScorer{weights} },
+	},
+}
+
+// RegisterModel adds a scoring model; names must be unique
+func RegisterModel(model ScoringModel) error {
+	if model.Name == "" || model.New == nil {
+		return fmt.Errorf("scoring model needs a name and a constructor")
+	}
+	if _, ok := LookupModel(model.Name); ok {
+		return fmt.Errorf("scoring model %q is already registered", model.Name)
+	}
+	models = append(models, model)
+	return nil
+}
+
+// Models returns the registered scoring models, the default first
+func Models() []ScoringModel {
+	return append([]ScoringModel(nil), models...)
+}
+
+// LookupModel finds a registered scoring model by name
+func LookupModel(name string) (ScoringModel, bool) {
+	for _, model := range models {
+		if model.Name == name {
+			return model, true
+		}
+	}
+	return ScoringModel{}, false
+}
+
+// NewScorer creates the named model with the given weights
+func NewScorer(name string, weights Weights) (Scorer, error) {
+	model, ok := LookupModel(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownModel, name)
+	}
+	return model.New(weights), nil
+}
+
+// DefaultScorer returns the default model with the default weights
+func DefaultScorer() Scorer {
+	scorer, _ := NewScorer(DefaultModel, DefaultWeights())
+	return scorer
+}
+
+// preferencePenalty scores what every model holds against a block regardless of
+// neighbors: overlapping 2.4GHz primaries, 2.4GHz 40MHz, DFS, non-PSC 6GHz
+// primaries and EIRP limits below a typical 20 dBm AP
+func preferencePenalty(candidate Candidate, weights Weights) float64 {
+	score := 0.0
+
+	switch candidate.Channel.Band {
+	case spectrum.Band2GHz:
+		if !isNonOverlapping24(candidate.Channel.Number) {
+			score += weights.OverlappingChannel24
+		}
+		if candidate.Block.Width > spectrum.Width20 {
+			score += weights.Wide24
+		}
+	case spectrum.Band6GHz:
+		// Clients discover APs on PSCs without relying on out-of-band discovery
+		if !candidate.Channel.IsPSC() {
+			score += weights.NonPSC
+		}
+	}
+
+	// Prefer blocks where the domain does not require DFS (typically UNII-1 and UNII-3)
+	if candidate.Rule.DFS {
+		score += weights.DFS
+	}
+
+	// Each dB below 20 dBm costs coverage, e.g. the 14 dBm allowance above 5725 MHz in Europe
+	if candidate.Rule.MaxEIRP < 20 {
+		score += float64(20-candidate.Rule.MaxEIRP) * weights.PowerPerDB
+	}
+
+	return score
+}
+
+// isNonOverlapping24 reports whether a 2.4GHz channel is one of 1, 6 and 11
+func isNonOverlapping24(channel int) bool {
+	for _, noCh := range Channels24GHz_NonOverlapping {
+		if channel == noCh {
+			return true
+		}
+	}
+	return false
+}
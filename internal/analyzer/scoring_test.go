@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/svgreg/wifi-bander/internal/regdb"
+)
+
+func TestNewScorer(t *testing.T) {
+	weights := DefaultWeights()
+	weights.DFS = 0
+
+	for _, name := range []string{"overlap", "airtime"} {
+		scorer, err := NewScorer(name, weights)
+		if err != nil || scorer.Name() != name || scorer.Weights() != weights {
+			t.Errorf("NewScorer(%q) = %v, %v", name, scorer, err)
+		}
+	}
+	if _, err := NewScorer("Overlap", weights); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("NewScorer(Overlap) error %v, want ErrUnknownModel", err)
+	}
+
+	if scorer := DefaultScorer(); scorer.Name() != DefaultModel || scorer.Weights() != DefaultWeights() {
+		t.Errorf("DefaultScorer() = %s with %s", scorer.Name(), scorer.Weights())
+	}
+	if Models()[0].Name != DefaultModel {
+		t.Errorf("first model %q, want the default", Models()[0].Name)
+	}
+}
+
+func TestRegisterModel(t *testing.T) {
+	registered := models
+	t.Cleanup(func() { models = registered })
+
+	custom := ScoringModel{Name: "custom", New: func(weights Weights) Scorer { return overlapScorer{weights} }}
+	if err := RegisterModel(custom); err != nil {
+		t.Fatalf("RegisterModel: %v", err)
+	}
+	if _, err := NewScorer("custom", DefaultWeights()); err != nil {
+		t.Errorf("NewScorer(custom): %v", err)
+	}
+	for _, model := range []ScoringModel{custom, {Name: "airtime", New: custom.New}, {Name: "unnamed"}, {New: custom.New}} {
+		if err := RegisterModel(model); err == nil {
+			t.Errorf("RegisterModel(%q) succeeded", model.Name)
+		}
+	}
+}
+
+func TestLoadWeights(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	weights, err := LoadWeights(write("office.json", `{"co_channel_network": 15, "dfs": 0}`))
+	if err != nil {
+		t.Fatalf("LoadWeights: %v", err)
+	}
+	want := DefaultWeights()
+	want.CoChannelNetwork, want.DFS = 15, 0
+	if weights != want {
+		t.Errorf("LoadWeights = %+v, want the defaults with two changes", weights)
+	}
+	if got := weights.String(); got != "co_channel_network=15, dfs=0" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := DefaultWeights().String(); got != "default weights" {
+		t.Errorf("default String() = %q", got)
+	}
+
+	// A misspelt weight is an error rather than a silently kept default
+	if _, err := LoadWeights(write("typo.json", `{"co_chanel_network": 15}`)); err == nil || !strings.Contains(err.Error(), "co_chanel_network") {
+		t.Errorf("LoadWeights with an unknown weight = %v", err)
+	}
+	if _, err := LoadWeights(write("invalid.json", `{"dfs": "none"}`)); err == nil {
+		t.Error("LoadWeights with a string weight succeeded")
+	}
+	if _, err := LoadWeights(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadWeights of a missing file = %v", err)
+	}
+}
+
+func TestRecommendationsRecordModel(t *testing.T) {
+	weights := DefaultWeights()
+	weights.ThroughputCredit = 0
+	networks := []WiFiNetwork{wide36(), on5G("02:00:00:00:00:02", 149, -55)}
+
+	for _, name := range []string{"overlap", "airtime"} {
+		scorer, err := NewScorer(name, weights)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for band, recs := range GetChannelRecommendations(networks, regdb.WorldDomain(), scorer) {
+			for _, rec := range recs {
+				if rec.Model != name || rec.Weights != weights {
+					t.Errorf("%s %s channel %d: model %q with %s", name, band, rec.Channel, rec.Model, rec.Weights)
+				}
+			}
+		}
+	}
+}
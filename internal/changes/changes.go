@@ -0,0 +1,320 @@
+package changes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/analyzer"
+	"github.com/svgreg/wifi-bander/internal/scanner"
+)
+
+// DefaultSignalThreshold is the signal change in dB reported when none is chosen
+const DefaultSignalThreshold = 10
+
+// EventType is the kind of change between scans
+type EventType string
+
+const (
+	APAppeared      EventType = "appeared"
+	APDisappeared   EventType = "disappeared"
+	ChannelChanged  EventType = "channel"
+	WidthChanged    EventType = "width"
+	SecurityChanged EventType = "security"
+	SignalChanged   EventType = "signal"
+)
+
+// Event is one change to a BSS between scans
+type Event struct {
+	Type    EventType
+	Time    time.Time           // When the scan that showed the change was taken
+	Network scanner.WiFiNetwork // Current observation; the last one seen for APDisappeared
+
+	Old, New string // Previous and current value of the field that changed, empty for appearances and disappearances
+	Delta    int    // Signal change in dB for SignalChanged
+
+	// The BSS now overlaps the channel of a network this host is connected to
+	OnConnectedChannel bool
+}
+
+// String describes the event in one line
+func (e Event) String() string {
+	name := e.Network.SSID
+	if name == "" {
+		name = "<hidden>"
+	}
+	name = fmt.Sprintf("%s (%s)", name, e.Network.BSSID)
+
+	switch e.Type {
+	case APAppeared:
+		return fmt.Sprintf("%s appeared on %s channel %d at %d dBm", name, e.Network.Band, e.Network.Channel, e.Network.Signal)
+	case APDisappeared:
+		return fmt.Sprintf("%s disappeared from %s channel %d", name, e.Network.Band, e.Network.Channel)
+	case ChannelChanged:
+		return fmt.Sprintf("%s moved from channel %s to %s", name, e.Old, e.New)
+	case WidthChanged:
+		return fmt.Sprintf("%s changed width from %s to %s", name, e.Old, e.New)
+	case SecurityChanged:
+		return fmt.Sprintf("%s changed security from %s to %s", name, e.Old, e.New)
+	case SignalChanged:
+		return fmt.Sprintf("%s signal %+d dB, %s → %s dBm", name, e.Delta, e.Old, e.New)
+	}
+	return fmt.Sprintf("%s: %s", name, e.Type)
+}
+
+// Detector compares consecutive scans and reports what changed. The first scan
+// only sets the baseline. Events are returned from Detect and sent to subscribers.
+type Detector struct {
+	// SignalThreshold is the change in dB, since the signal was last reported, that is an event
+	SignalThreshold int
+	// MissedScans is how many consecutive scans a BSS must be missing from to have disappeared;
+	// scans routinely miss a weak BSS, so 1 reports every miss
+	MissedScans int
+
+	mu          sync.Mutex
+	primed      bool
+	known       map[string]*entry
+	subscribers []chan Event
+	dropped     int
+}
+
+// entry is the last known state of one BSS
+type entry struct {
+	network  scanner.WiFiNetwork
+	baseline int // Signal when last reported
+	missed   int
+}
+
+// NewDetector creates a detector reporting signal changes above threshold dB and
+// disappearances after two missed scans
+func NewDetector(threshold int) *Detector {
+	return &Detector{SignalThreshold: threshold, MissedScans: 2}
+}
+
+// Subscribe returns a channel receiving every event from later scans. Events are
+// dropped rather than stalling scans when a subscriber falls more than buffer behind.
+func (d *Detector) Subscribe(buffer int) <-chan Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ch := make(chan Event, buffer)
+	d.subscribers = append(d.subscribers, ch)
+	return ch
+}
+
+// Close closes every subscriber channel
+func (d *Detector) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, ch := range d.subscribers {
+		close(ch)
+	}
+	d.subscribers = nil
+}
+
+// Primed reports whether a baseline scan has been seen, so that Detect reports changes
+func (d *Detector) Primed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.primed
+}
+
+// Dropped returns how many events subscribers missed because their buffer was full
+func (d *Detector) Dropped() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dropped
+}
+
+// Detect compares a scan taken at the given time with the previous ones and returns
+// the changes, new and moved networks first
+func (d *Detector) Detect(networks []scanner.WiFiNetwork, at time.Time) []Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.known == nil {
+		d.known = make(map[string]*entry)
+	}
+
+	var events []Event
+	seen := make(map[string]bool, len(networks))
+	for _, network := range networks {
+		key := Identity(network)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		previous, ok := d.known[key]
+		if !ok {
+			d.known[key] = &entry{network: network, baseline: network.Signal}
+			if d.primed {
+				events = append(events, Event{Type: APAppeared, Network: network})
+			}
+			continue
+		}
+		events = append(events, d.compare(previous, network)...)
+		previous.network, previous.missed = keepReported(previous.network, network), 0
+	}
+
+	for key, previous := range d.known {
+		if seen[key] {
+			continue
+		}
+		previous.missed++
+		if previous.missed >= max(d.MissedScans, 1) {
+			delete(d.known, key)
+			events = append(events, Event{Type: APDisappeared, Network: previous.network})
+		}
+	}
+	d.primed = true
+
+	connected := NewConnected(networks)
+	for i := range events {
+		events[i].Time = at
+		if events[i].Type != APDisappeared {
+			events[i].OnConnectedChannel = connected.Overlaps(events[i].Network)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if rank(events[i].Type) != rank(events[j].Type) {
+			return rank(events[i].Type) < rank(events[j].Type)
+		}
+		return events[i].Network.BSSID < events[j].Network.BSSID
+	})
+
+	d.publish(events)
+	return events
+}
+
+// compare returns the changes to one BSS since it was last seen
+func (d *Detector) compare(previous *entry, network scanner.WiFiNetwork) []Event {
+	var events []Event
+	old := previous.network
+
+	if old.Channel != network.Channel || old.Band != network.Band {
+		events = append(events, Event{
+			Type:    ChannelChanged,
+			Network: network,
+			Old:     fmt.Sprintf("%d (%s)", old.Channel, old.Band),
+			New:     fmt.Sprintf("%d (%s)", network.Channel, network.Band),
+		})
+	}
+	// Backends that cannot tell a width or security report them empty or "Unknown"; that is not a change
+	if old.ChannelWidth != network.ChannelWidth && reported(old.ChannelWidth) && reported(network.ChannelWidth) {
+		events = append(events, Event{Type: WidthChanged, Network: network, Old: old.ChannelWidth, New: network.ChannelWidth})
+	}
+	if old.Security != network.Security && reported(old.Security) && reported(network.Security) {
+		events = append(events, Event{Type: SecurityChanged, Network: network, Old: old.Security, New: network.Security})
+	}
+
+	delta := network.Signal - previous.baseline
+	if d.SignalThreshold > 0 && abs(delta) > d.SignalThreshold {
+		events = append(events, Event{
+			Type:    SignalChanged,
+			Network: network,
+			Old:     fmt.Sprint(previous.baseline),
+			New:     fmt.Sprint(network.Signal),
+			Delta:   delta,
+		})
+		previous.baseline = network.Signal
+	}
+	return events
+}
+
+// reported reports whether a backend could tell a width or security at all
+func reported(value string) bool {
+	return value != "" && value != "Unknown"
+}
+
+// keepReported returns the latest observation of a BSS, keeping the width and
+// security last reported where it lacks them, so a change across a scan that
+// could not tell is still caught
+func keepReported(old, network scanner.WiFiNetwork) scanner.WiFiNetwork {
+	if !reported(network.ChannelWidth) {
+		network.ChannelWidth = old.ChannelWidth
+	}
+	if !reported(network.Security) {
+		network.Security = old.Security
+	}
+	return network
+}
+
+// publish sends events to every subscriber without blocking
+func (d *Detector) publish(events []Event) {
+	for _, event := range events {
+		for _, ch := range d.subscribers {
+			select {
+			case ch <- event:
+			default:
+				d.dropped++
+			}
+		}
+	}
+}
+
+// Identity keys a BSS by its BSSID, or by SSID and band when the backend cannot report one
+func Identity(network scanner.WiFiNetwork) string {
+	if network.BSSID != "" && network.BSSID != "Unknown" {
+		return strings.ToLower(network.BSSID)
+	}
+	return network.SSID + "/" + network.Band
+}
+
+// Connected holds the channel blocks of the networks this host is connected to
+type Connected []analyzer.OccupiedBlock
+
+// NewConnected finds the connected networks in a scan
+func NewConnected(networks []scanner.WiFiNetwork) Connected {
+	var connected Connected
+	for _, network := range networks {
+		if network.Connected {
+			connected = append(connected, analyzer.NewOccupancy([]analyzer.WiFiNetwork{network})...)
+		}
+	}
+	return connected
+}
+
+// Overlaps reports whether a network, other than a connected one itself, shares
+// spectrum with a connected network
+func (c Connected) Overlaps(network scanner.WiFiNetwork) bool {
+	if network.Connected {
+		return false
+	}
+	block := analyzer.GetOccupiedBlock(network)
+	for _, own := range c {
+		if own.Band == network.Band && block.Overlap(own.Block) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rank orders event types for display: new and moved networks first
+func rank(t EventType) int {
+	switch t {
+	case APAppeared:
+		return 0
+	case ChannelChanged:
+		return 1
+	case WidthChanged:
+		return 2
+	case SecurityChanged:
+		return 3
+	case SignalChanged:
+		return 4
+	}
+	return 5
+}
+
+// abs returns the absolute value of x
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,192 @@
+package changes
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/scanner"
+)
+
+// office is a BSS as a backend reports it
+func office(signal int) scanner.WiFiNetwork {
+	return scanner.WiFiNetwork{SSID: "Office", BSSID: "00:11:32:AA:BB:CC", Band: "5G", Channel: 36, Frequency: 5180,
+		ChannelWidth: "80MHz", Signal: signal, Security: "WPA2 Personal"}
+}
+
+// summarize lists events as "type old→new" strings
+func summarize(events []Event) []string {
+	var summary []string
+	for _, event := range events {
+		line := string(event.Type)
+		if event.Old != "" || event.New != "" {
+			line += " " + event.Old + "→" + event.New
+		}
+		summary = append(summary, line)
+	}
+	return summary
+}
+
+func TestDetectorPrimingScan(t *testing.T) {
+	d := NewDetector(DefaultSignalThreshold)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if d.Primed() {
+		t.Error("primed before any scan")
+	}
+	// The first scan is the baseline, not a burst of appearances
+	if events := d.Detect([]scanner.WiFiNetwork{office(-50)}, at); len(events) != 0 {
+		t.Errorf("first scan reported %v", summarize(events))
+	}
+	if !d.Primed() {
+		t.Error("not primed after the first scan")
+	}
+
+	newcomer := office(-60)
+	newcomer.BSSID = "02:00:00:00:00:01"
+	events := d.Detect([]scanner.WiFiNetwork{office(-50), newcomer}, at.Add(time.Minute))
+	if len(events) != 1 || events[0].Type != APAppeared || events[0].Network.BSSID != newcomer.BSSID || !events[0].Time.Equal(at.Add(time.Minute)) {
+		t.Errorf("second scan reported %+v, want the newcomer appearing", events)
+	}
+}
+
+func TestDetectorMissedScans(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		missedScans int
+		want        string // Event per scan after the baseline, the BSS missing from all of them
+	}{
+		{2, ", disappeared, "},
+		{1, "disappeared, , "},
+		{0, "disappeared, , "}, // Treated as 1
+		{3, ", , disappeared"},
+	}
+
+	for _, test := range tests {
+		d := NewDetector(DefaultSignalThreshold)
+		d.MissedScans = test.missedScans
+		d.Detect([]scanner.WiFiNetwork{office(-50)}, at)
+
+		var got []string
+		for i := 1; i <= 3; i++ {
+			got = append(got, strings.Join(summarize(d.Detect(nil, at.Add(time.Duration(i)*time.Minute))), " "))
+		}
+		if strings.Join(got, ", ") != test.want {
+			t.Errorf("MissedScans %d: scans reported %q, want %q", test.missedScans, strings.Join(got, ", "), test.want)
+		}
+	}
+
+	// Coming back before enough misses resets the count
+	d := NewDetector(DefaultSignalThreshold)
+	for i, present := range []bool{true, false, true, false, true} {
+		var networks []scanner.WiFiNetwork
+		if present {
+			networks = append(networks, office(-50))
+		}
+		if events := d.Detect(networks, at.Add(time.Duration(i)*time.Minute)); len(events) != 0 {
+			t.Errorf("scan %d reported %v for a BSS missing once at a time", i+1, summarize(events))
+		}
+	}
+}
+
+func TestDetectorSignalBaseline(t *testing.T) {
+	d := NewDetector(10)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	d.Detect([]scanner.WiFiNetwork{office(-50)}, at)
+
+	// Drift is measured from the last reported signal, not the previous scan
+	steps := []struct {
+		signal int
+		want   string
+	}{
+		{-55, ""},
+		{-60, ""},
+		{-61, "signal -50→-61"},
+		{-66, ""},
+		{-50, "signal -61→-50"},
+		{-41, ""},
+	}
+	for i, step := range steps {
+		got := strings.Join(summarize(d.Detect([]scanner.WiFiNetwork{office(step.signal)}, at.Add(time.Duration(i+1)*time.Minute))), ", ")
+		if got != step.want {
+			t.Errorf("%d dBm: reported %q, want %q", step.signal, got, step.want)
+		}
+	}
+
+	// A zero threshold turns signal events off
+	quiet := NewDetector(0)
+	quiet.Detect([]scanner.WiFiNetwork{office(-50)}, at)
+	if events := quiet.Detect([]scanner.WiFiNetwork{office(-90)}, at.Add(time.Minute)); len(events) != 0 {
+		t.Errorf("threshold 0 reported %v", summarize(events))
+	}
+}
+
+func TestDetectorUnreportedFields(t *testing.T) {
+	d := NewDetector(DefaultSignalThreshold)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	d.Detect([]scanner.WiFiNetwork{office(-50)}, at)
+
+	unknown := office(-50)
+	unknown.ChannelWidth, unknown.Security = "Unknown", "Unknown"
+	empty := office(-50)
+	empty.ChannelWidth, empty.Security = "", ""
+	changed := office(-50)
+	changed.Channel, changed.Frequency, changed.ChannelWidth, changed.Security = 40, 5200, "40MHz", "WPA3 Personal"
+
+	// Scans by a backend that cannot tell are not changes, and the last reported
+	// values are kept to compare the next scan that can
+	for i, network := range []scanner.WiFiNetwork{unknown, empty, office(-50), unknown} {
+		if events := d.Detect([]scanner.WiFiNetwork{network}, at.Add(time.Duration(i+1)*time.Minute)); len(events) != 0 {
+			t.Errorf("scan %d reported %v", i+2, summarize(events))
+		}
+	}
+	got := summarize(d.Detect([]scanner.WiFiNetwork{changed}, at.Add(10*time.Minute)))
+	want := []string{"channel 36 (5G)→40 (5G)", "width 80MHz→40MHz", "security WPA2 Personal→WPA3 Personal"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("reported %v, want %v", got, want)
+	}
+}
+
+func TestDetectorSubscribeDrops(t *testing.T) {
+	d := NewDetector(DefaultSignalThreshold)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	slow, fast := d.Subscribe(1), d.Subscribe(8)
+	d.Detect(nil, at)
+
+	var networks []scanner.WiFiNetwork
+	for _, bssid := range []string{"02:00:00:00:00:01", "02:00:00:00:00:02", "02:00:00:00:00:03"} {
+		network := office(-60)
+		network.BSSID = bssid
+		networks = append(networks, network)
+	}
+
+	// Nobody reads while the scan is analyzed; Detect must not wait for them
+	done := make(chan []Event)
+	go func() { done <- d.Detect(networks, at.Add(time.Minute)) }()
+	select {
+	case events := <-done:
+		if len(events) != 3 {
+			t.Errorf("Detect returned %d events, want 3", len(events))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Detect blocked on a full subscriber")
+	}
+	if d.Dropped() != 2 {
+		t.Errorf("Dropped() = %d, want the 2 events the slow subscriber had no room for", d.Dropped())
+	}
+
+	d.Close()
+	count := func(ch <-chan Event) int {
+		n := 0
+		for range ch {
+			n++
+		}
+		return n
+	}
+	if got := count(slow); got != 1 {
+		t.Errorf("slow subscriber received %d events, want 1", got)
+	}
+	if got := count(fast); got != 3 {
+		t.Errorf("fast subscriber received %d events, want 3", got)
+	}
+}
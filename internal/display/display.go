@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/svgreg/wifi-bander/internal/analyzer"
+	"github.com/svgreg/wifi-bander/internal/changes"
+	"github.com/svgreg/wifi-bander/internal/regdb"
+	"github.com/svgreg/wifi-bander/internal/spectrum"
 )
 
 // WiFiNetwork interface for display purposes
@@ -17,6 +21,8 @@ type WiFiNetwork interface {
 	GetChannel() int
 	GetSignal() int
 	GetStationCount() int
+	HasBSSLoad() bool
+	GetChannelUtilization() int
 	GetCongestionScore() int
 	GetFrequency() int
 	GetSecurity() string
@@ -28,11 +34,13 @@ type WiFiNetwork interface {
 	GetQuality() int
 	GetNoise() int
 	GetSNR() int
+	GetLastSeen() time.Time
+	GetRadioSignals() map[string]int
 }
 
 // DisplayResults shows the WiFi scan results in a comprehensive formatted table
 func DisplayResults(networks []WiFiNetwork) {
-	fmt.Printf("\n=== WiFi Network Analysis - %s ===\n", time.Now().Format("15:04:05"))
+	fmt.Printf("\n=== WiFi Network Analysis - %s ===\n", formatScanTime(networks))
 
 	if len(networks) == 0 {
 		fmt.Println("No networks detected.")
@@ -42,9 +50,17 @@ func DisplayResults(networks []WiFiNetwork) {
 	// Create a new tabwriter with wider spacing for comprehensive data
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
 
+	// With several radios, add a column comparing what each one saw
+	radios := radioNames(networks)
+	radioHeader, radioRule := "", ""
+	if len(radios) > 1 {
+		radioHeader = "RSSI " + strings.Join(radios, "/") + "\t"
+		radioRule = strings.Repeat("-", len(radioHeader)-1) + "\t"
+	}
+
 	// Comprehensive header
-	fmt.Fprintln(w, "SSID\tBand\tCh\tSignal\tQuality\tSecurity\tPHY Mode\tWidth\tVendor\tCongestion\tFreq\t")
-	fmt.Fprintln(w, "----\t----\t--\t------\t-------\t--------\t--------\t-----\t------\t----------\t----\t")
+	fmt.Fprintln(w, "SSID\tBand\tCh\tSignal\tQuality\tSecurity\tPHY Mode\tWidth\tVendor\tLoad\tCongestion\tFreq\t"+radioHeader)
+	fmt.Fprintln(w, "----\t----\t--\t------\t-------\t--------\t--------\t-----\t------\t----\t----------\t----\t"+radioRule)
 
 	// Print each network's comprehensive information
 	for _, net := range networks {
@@ -56,7 +72,12 @@ func DisplayResults(networks []WiFiNetwork) {
 		phyMode := truncateString(net.GetPHYMode(), 15)   // Increased from 10 to 15
 		vendor := truncateString(net.GetVendor(), 8)
 
-		fmt.Fprintf(w, "%s\t%s\t%d\t%d dBm\t%d%%\t%s\t%s\t%s\t%s\t%s\t%d\t\n",
+		radioColumn := ""
+		if len(radios) > 1 {
+			radioColumn = formatRadioSignals(net.GetRadioSignals(), radios) + "\t"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d dBm\t%d%%\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
 			ssid,
 			net.GetBand(),
 			net.GetChannel(),
@@ -66,8 +87,10 @@ func DisplayResults(networks []WiFiNetwork) {
 			phyMode,
 			net.GetChannelWidth(),
 			vendor,
+			formatLoad(net),
 			congestionLevel,
 			net.GetFrequency(),
+			radioColumn,
 		)
 	}
 
@@ -76,6 +99,44 @@ func DisplayResults(networks []WiFiNetwork) {
 
 	// Show network count summary
 	fmt.Printf("\nTotal networks detected: %d\n", len(networks))
+	fmt.Println("Load: stations and channel utilization from BSS Load; ~ marks a signal-based estimate")
+}
+
+// formatLoad shows the advertised stations and utilization, or "~N sta" for an estimate
+func formatLoad(network WiFiNetwork) string {
+	if !network.HasBSSLoad() {
+		return fmt.Sprintf("~%d sta", network.GetStationCount())
+	}
+	return fmt.Sprintf("%d sta %d%%", network.GetStationCount(), network.GetChannelUtilization())
+}
+
+// radioNames returns the sorted interface names that contributed to any network
+func radioNames(networks []WiFiNetwork) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, network := range networks {
+		for name := range network.GetRadioSignals() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatRadioSignals lists each radio's RSSI in the given order, "--" where a radio did not see the network
+func formatRadioSignals(signals map[string]int, radios []string) string {
+	values := make([]string, len(radios))
+	for i, radio := range radios {
+		if signal, ok := signals[radio]; ok {
+			values[i] = fmt.Sprintf("%d", signal)
+		} else {
+			values[i] = "--"
+		}
+	}
+	return strings.Join(values, "/")
 }
 
 // truncateString truncates a string to a maximum length for table formatting
@@ -89,9 +150,28 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
+// formatScanTime returns the time of the most recent observation, falling back to now.
+// Captures from another day include the date.
+func formatScanTime(networks []WiFiNetwork) string {
+	var latest time.Time
+	for _, network := range networks {
+		if seen := network.GetLastSeen(); seen.After(latest) {
+			latest = seen
+		}
+	}
+	if latest.IsZero() {
+		latest = time.Now()
+	}
+
+	if latest.Format("2006-01-02") != time.Now().Format("2006-01-02") {
+		return latest.Format("2006-01-02 15:04:05")
+	}
+	return latest.Format("15:04:05")
+}
+
 // DisplayCompactResults shows a compact view of the networks
 func DisplayCompactResults(networks []WiFiNetwork) {
-	fmt.Printf("\n=== WiFi Networks (Compact View) - %s ===\n", time.Now().Format("15:04:05"))
+	fmt.Printf("\n=== WiFi Networks (Compact View) - %s ===\n", formatScanTime(networks))
 
 	if len(networks) == 0 {
 		fmt.Println("No networks detected.")
@@ -123,24 +203,47 @@ func DisplayCompactResults(networks []WiFiNetwork) {
 	w.Flush()
 }
 
-// DisplayRecommendations shows channel recommendations
-func DisplayRecommendations(networks []analyzer.WiFiNetwork) {
-	recommendations := analyzer.GetChannelRecommendations(networks)
+// DisplayRecommendations shows channel recommendations permitted in the regulatory
+// domain over the scans in history, ranked by the scoring model (nil for the default)
+func DisplayRecommendations(history *analyzer.History, domain *regdb.Domain, scorer analyzer.Scorer) {
+	if domain == nil {
+		domain = regdb.WorldDomain()
+	}
+	if scorer == nil {
+		scorer = analyzer.DefaultScorer()
+	}
+	recommendations := history.Recommendations(domain, scorer)
 
 	fmt.Println("\n=== Channel Recommendations (Top 3 Optimal Choices) ===")
 	fmt.Println("Advanced analysis considering frequency separation, signal strength, and interference patterns")
+	fmt.Printf("Regulatory domain: %s\n", domain)
+	fmt.Printf("Scoring model: %s (%s)\n", scorer.Name(), scorer.Weights())
+	if history.Scans() > 1 {
+		fmt.Printf("Based on %d scans over the last %s; confidence is how steadily each pick held up\n",
+			history.Scans(), history.Span().Round(time.Second))
+	} else {
+		fmt.Println("Based on a single scan; confidence grows as scans accumulate")
+	}
 
 	for band, recs := range recommendations {
 		fmt.Printf("\n🔸 %s Band Recommendations:\n", band)
 
 		if len(recs) == 0 {
-			fmt.Printf("  No recommendations available for %s band\n", band)
+			switch {
+			case len(domain.ChannelNumbers(spectrum.Band(band))) > 0:
+				fmt.Printf("  No recommendations available for %s band\n", band)
+			case domain.Country == regdb.World:
+				// Countries differ too much on 6 GHz for any of it to be allowed worldwide
+				fmt.Printf("  The %s band is not permitted in every country; choose yours with -country to see its channels\n", band)
+			default:
+				fmt.Printf("  The %s band is not permitted in %s\n", band, domain)
+			}
 			continue
 		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "Rank\tChannel\tFreq(MHz)\tInterference\tGap(MHz)\tReasoning\t")
-		fmt.Fprintln(w, "----\t-------\t---------\t-----------\t--------\t---------\t")
+		fmt.Fprintln(w, "Rank\tChannel\tWidth\tBlock(MHz)\tInterference\tConfidence\tGap(MHz)\tRegulatory\tReasoning\t")
+		fmt.Fprintln(w, "----\t-------\t-----\t----------\t-----------\t----------\t--------\t----------\t---------\t")
 
 		for i, rec := range recs {
 			rank := fmt.Sprintf("#%d", i+1)
@@ -149,17 +252,28 @@ func DisplayRecommendations(networks []analyzer.WiFiNetwork) {
 				gap = fmt.Sprintf("%d", rec.FrequencyGap)
 			}
 
-			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t\n",
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d-%d\t%s\t%s %.0f%%\t%s\t%s\t%s\t\n",
 				rank,
 				rec.Channel,
-				rec.Frequency,
+				rec.Width,
+				rec.Block.Low(),
+				rec.Block.High(),
 				rec.InterferenceLevel,
+				rec.ConfidenceLevel,
+				rec.Confidence*100,
 				gap,
+				rec.Regulatory,
 				rec.Reasoning,
 			)
 		}
 		w.Flush()
 
+		// Show what each width buys against the interference it adds
+		fmt.Printf("\n  ⚖️  Throughput vs Interference:\n")
+		for _, rec := range recs {
+			fmt.Printf("     • Channel %d at %s: %s\n", rec.Channel, rec.Width, rec.TradeOff)
+		}
+
 		// Show frequency separation analysis
 		if len(recs) >= 2 {
 			fmt.Printf("\n  📊 Frequency Separation Analysis:\n")
@@ -171,9 +285,12 @@ func DisplayRecommendations(networks []analyzer.WiFiNetwork) {
 		}
 
 		// Show band-specific advice
-		if band == "2.4G" {
+		switch band {
+		case string(spectrum.Band2GHz):
 			fmt.Printf("\n  💡 2.4GHz Advice: Prefer channels 1, 6, or 11 (non-overlapping). Avoid channels with strong nearby signals.\n")
-		} else {
+		case string(spectrum.Band6GHz):
+			fmt.Printf("\n  💡 6GHz Advice: Requires Wi-Fi 6E/7 clients. Use a Preferred Scanning Channel so clients find the AP quickly.\n")
+		default:
 			fmt.Printf("\n  💡 5GHz Advice: More spectrum available. DFS channels may require radar detection but are often less congested.\n")
 		}
 	}
@@ -181,11 +298,98 @@ func DisplayRecommendations(networks []analyzer.WiFiNetwork) {
 	fmt.Println("\n🎯 Configuration Tips:")
 	fmt.Println("   • Choose the #1 ranked channel for optimal performance")
 	fmt.Println("   • Monitor performance and try #2 or #3 if issues occur")
-	fmt.Println("   • Consider channel width: 80MHz for 5GHz, 20MHz for 2.4GHz in crowded areas")
+	fmt.Println("   • Configure the recommended width with the channel as primary; narrow it if clients see interference")
 	fmt.Println("   • Update analysis periodically as WiFi landscape changes")
 	fmt.Println("\nPress Ctrl+C to exit...")
 }
 
+// DisplayRogueFindings shows SSIDs whose access points look like rogue APs or evil twins
+func DisplayRogueFindings(findings []analyzer.RogueFinding) {
+	fmt.Println("\n=== Rogue AP / Evil Twin Detection ===")
+	if len(findings) == 0 {
+		fmt.Println("No suspicious networks: every SSID is served consistently.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Severity\tSSID\tFinding\tBSSIDs\tDetail\t")
+	fmt.Fprintln(w, "--------\t----\t-------\t------\t------\t")
+	for _, finding := range findings {
+		bssids := strings.Join(finding.BSSIDs, ", ")
+		if len(finding.BSSIDs) > 2 {
+			bssids = fmt.Sprintf("%s, %s +%d", finding.BSSIDs[0], finding.BSSIDs[1], len(finding.BSSIDs)-2)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n",
+			finding.Severity,
+			truncateString(finding.SSID, 24),
+			finding.Kind,
+			bssids,
+			finding.Detail,
+		)
+	}
+	w.Flush()
+}
+
+// changeIcons marks each kind of change in the "what changed" section
+var changeIcons = map[changes.EventType]string{
+	changes.APAppeared:      "🆕",
+	changes.APDisappeared:   "👋",
+	changes.ChannelChanged:  "🔀",
+	changes.WidthChanged:    "↔️ ",
+	changes.SecurityChanged: "🔒",
+	changes.SignalChanged:   "📶",
+}
+
+// DisplayChanges shows what changed since the previous scan, flagging networks that
+// now share spectrum with the network this host is connected to
+func DisplayChanges(events []changes.Event) {
+	fmt.Println("\n=== What Changed Since the Last Scan ===")
+	if len(events) == 0 {
+		fmt.Println("No changes.")
+		return
+	}
+
+	for _, event := range events {
+		line := fmt.Sprintf("  %s %s", changeIcons[event.Type], event)
+		if event.OnConnectedChannel {
+			line += "  ⚠️  overlaps your channel"
+		}
+		fmt.Println(line)
+	}
+}
+
+// DisplayModelComparison ranks the same scans under several scoring models side by side
+func DisplayModelComparison(history *analyzer.History, domain *regdb.Domain, scorers []analyzer.Scorer) {
+	if domain == nil {
+		domain = regdb.WorldDomain()
+	}
+
+	fmt.Println("\n=== Scoring Model Comparison ===")
+	for _, scorer := range scorers {
+		fmt.Printf("%s: %s\n", scorer.Name(), scorer.Weights())
+	}
+	fmt.Println()
+
+	rankings := make([]map[string][]analyzer.ChannelRecommendation, len(scorers))
+	for i, scorer := range scorers {
+		rankings[i] = history.Recommendations(domain, scorer)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Band\tModel\t#1\t#2\t#3\t")
+	fmt.Fprintln(w, "----\t-----\t--\t--\t--\t")
+	for _, band := range spectrum.Bands() {
+		for i, scorer := range scorers {
+			picks := []string{"-", "-", "-"}
+			for rank, rec := range rankings[i][string(band)] {
+				picks[rank] = fmt.Sprintf("%d @%s (%.0f)", rec.Channel, rec.Width, rec.Score)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t\n", band, scorer.Name(), strings.Join(picks, "\t"))
+		}
+	}
+	w.Flush()
+}
+
 // abs helper function for frequency calculations
 func abs(x int) int {
 	if x < 0 {
@@ -209,15 +413,19 @@ func GetCongestionLevel(score int) string {
 }
 
 // DisplayChannelInfo shows detailed information about detected and available channels
-func DisplayChannelInfo(networks []analyzer.WiFiNetwork) {
+func DisplayChannelInfo(networks []analyzer.WiFiNetwork, domain *regdb.Domain) {
+	if domain == nil {
+		domain = regdb.WorldDomain()
+	}
 	fmt.Println("\n=== Channel Analysis ===")
 
 	// Get detected channels
-	detected24 := getDetectedChannelsByBand(networks, "2.4G")
-	detected5 := getDetectedChannelsByBand(networks, "5G")
+	detected24 := getDetectedChannelsByBand(networks, spectrum.Band2GHz)
+	detected5 := getDetectedChannelsByBand(networks, spectrum.Band5GHz)
+	detected6 := getDetectedChannelsByBand(networks, spectrum.Band6GHz)
 
 	// Get channel info
-	channelInfo := analyzer.GetChannelInfo()
+	channelInfo := analyzer.GetChannelInfo(domain)
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 
@@ -225,17 +433,29 @@ func DisplayChannelInfo(networks []analyzer.WiFiNetwork) {
 	fmt.Fprintln(w, "\n2.4GHz Band Analysis:")
 	fmt.Fprintf(w, "Detected channels:\t%v\t\n", detected24)
 	fmt.Fprintf(w, "Non-overlapping (optimal):\t%v\t\n", channelInfo["2.4GHz"].(map[string]interface{})["non_overlapping"])
-	fmt.Fprintf(w, "US standard (1-11):\t%v\t\n", channelInfo["2.4GHz"].(map[string]interface{})["us_channels"])
-	fmt.Fprintf(w, "EU standard (1-13):\t%v\t\n", channelInfo["2.4GHz"].(map[string]interface{})["eu_channels"])
+	fmt.Fprintf(w, "Allowed in %s:\t%v\t\n", domain.Country, channelInfo["2.4GHz"].(map[string]interface{})["allowed"])
 
-	// 5GHz information
+	// 5GHz information, listing each sub-band's channels allowed in the domain
 	fmt.Fprintln(w, "\n5GHz Band Analysis:")
 	fmt.Fprintf(w, "Detected channels:\t%v\t\n", detected5)
-	fmt.Fprintf(w, "UNII-1 (36-48):\t%v\t\n", channelInfo["5GHz"].(map[string]interface{})["unii_1"])
-	fmt.Fprintf(w, "UNII-2A (52-64, DFS):\t%v\t\n", channelInfo["5GHz"].(map[string]interface{})["unii_2a"])
-	fmt.Fprintf(w, "UNII-2C (100-144, DFS):\t%v\t\n", channelInfo["5GHz"].(map[string]interface{})["unii_2c"])
-	fmt.Fprintf(w, "UNII-3 (149-165):\t%v\t\n", channelInfo["5GHz"].(map[string]interface{})["unii_3"])
-	fmt.Fprintf(w, "UNII-4 (169-177):\t%v\t\n", channelInfo["5GHz"].(map[string]interface{})["unii_4"])
+	for _, sub := range spectrum.SubBands(spectrum.Band5GHz) {
+		if allowed := allowedChannels(sub, domain); len(allowed) > 0 {
+			fmt.Fprintf(w, "%s:\t%v\t\n", subBandLabel(sub), allowed)
+		} else {
+			fmt.Fprintf(w, "%s:\tnot permitted in %s\t\n", subBandLabel(sub), domain.Country)
+		}
+	}
+
+	// 6GHz information, only when 6GHz networks are around to keep the overview short
+	if len(detected6) > 0 {
+		fmt.Fprintln(w, "\n6GHz Band Analysis:")
+		fmt.Fprintf(w, "Detected channels:\t%v\t\n", detected6)
+		fmt.Fprintf(w, "Preferred Scanning Channels:\t%v\t\n", spectrum.PreferredScanningChannels())
+		for _, sub := range spectrum.SubBands(spectrum.Band6GHz) {
+			fmt.Fprintf(w, "%s:\t%d of %d channels allowed in %s\t\n",
+				subBandLabel(sub), len(allowedChannels(sub, domain)), len(sub.Channels), domain.Country)
+		}
+	}
 
 	w.Flush()
 
@@ -243,11 +463,40 @@ func DisplayChannelInfo(networks []analyzer.WiFiNetwork) {
 	displayChannelUsageStats(networks)
 }
 
+// subBandLabel formats a sub-band as "UNII-2A (52-64, DFS)"
+func subBandLabel(sub spectrum.SubBand) string {
+	first, last := sub.Channels[0], sub.Channels[0]
+	for _, ch := range sub.Channels {
+		if ch < first {
+			first = ch
+		}
+		if ch > last {
+			last = ch
+		}
+	}
+	label := fmt.Sprintf("%s (%d-%d", sub.Name, first, last)
+	if sub.DFS {
+		label += ", DFS"
+	}
+	return label + ")"
+}
+
+// allowedChannels returns the sub-band's channels the domain permits
+func allowedChannels(sub spectrum.SubBand, domain *regdb.Domain) []int {
+	var allowed []int
+	for _, number := range sub.Channels {
+		if _, ok := domain.Allows(spectrum.Channel{Band: sub.Band, Number: number}); ok {
+			allowed = append(allowed, number)
+		}
+	}
+	return allowed
+}
+
 // getDetectedChannelsByBand extracts detected channels for a specific band
-func getDetectedChannelsByBand(networks []analyzer.WiFiNetwork, band string) []int {
+func getDetectedChannelsByBand(networks []analyzer.WiFiNetwork, band spectrum.Band) []int {
 	channelSet := make(map[int]bool)
 	for _, network := range networks {
-		if network.GetBand() == band {
+		if network.GetBand() == string(band) {
 			channelSet[network.GetChannel()] = true
 		}
 	}
@@ -267,12 +516,16 @@ func displayChannelUsageStats(networks []analyzer.WiFiNetwork) {
 
 	usage24 := make(map[int]int)
 	usage5 := make(map[int]int)
+	usage6 := make(map[int]int)
 
 	// Count networks per channel
 	for _, network := range networks {
-		if network.GetBand() == "2.4G" {
+		switch network.GetBand() {
+		case string(spectrum.Band2GHz):
 			usage24[network.GetChannel()]++
-		} else {
+		case string(spectrum.Band6GHz):
+			usage6[network.GetChannel()]++
+		default:
 			usage5[network.GetChannel()]++
 		}
 	}
@@ -308,9 +561,7 @@ func displayChannelUsageStats(networks []analyzer.WiFiNetwork) {
 	if len(usage5) > 0 {
 		fmt.Fprintln(w, "\n5GHz Channel Usage:")
 
-		// Get all 5GHz channels from analyzer
-		channelInfo := analyzer.GetChannelInfo()
-		allChannels5G := channelInfo["5GHz"].(map[string]interface{})["all"].([]int)
+		allChannels5G := spectrum.ChannelNumbers(spectrum.Band5GHz)
 
 		// Channel headers
 		fmt.Fprint(w, "Channel\t")
@@ -337,5 +588,48 @@ func displayChannelUsageStats(networks []analyzer.WiFiNetwork) {
 		fmt.Fprintln(w, "\n5GHz Channel Usage: No 5GHz networks detected")
 	}
 
+	// 6GHz has 59 channels, so show the PSCs plus any channel in use
+	if len(usage6) > 0 {
+		fmt.Fprintln(w, "\n6GHz Channel Usage (* = Preferred Scanning Channel):")
+
+		channelSet := make(map[int]bool)
+		for _, ch := range spectrum.PreferredScanningChannels() {
+			channelSet[ch] = true
+		}
+		for ch := range usage6 {
+			channelSet[ch] = true
+		}
+		var channels6G []int
+		for ch := range channelSet {
+			channels6G = append(channels6G, ch)
+		}
+		sort.Ints(channels6G)
+
+		// Channel headers
+		fmt.Fprint(w, "Channel\t")
+		for _, ch := range channels6G {
+			if (spectrum.Channel{Band: spectrum.Band6GHz, Number: ch}).IsPSC() {
+				fmt.Fprintf(w, "%d*\t", ch)
+			} else {
+				fmt.Fprintf(w, "%d\t", ch)
+			}
+		}
+		fmt.Fprintln(w)
+
+		// Separator line
+		fmt.Fprint(w, "-------\t")
+		for range channels6G {
+			fmt.Fprint(w, "--\t")
+		}
+		fmt.Fprintln(w)
+
+		// Network counts
+		fmt.Fprint(w, "Networks\t")
+		for _, ch := range channels6G {
+			fmt.Fprintf(w, "%d\t", usage6[ch])
+		}
+		fmt.Fprintln(w)
+	}
+
 	w.Flush()
 }
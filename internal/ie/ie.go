@@ -0,0 +1,438 @@
+// Package ie decodes 802.11 information elements as found in beacons, probe
+// responses and the raw IE blobs exposed by nl80211, wpa_supplicant and iw.
+//
+// Information elements come from untrusted over-the-air frames, so every
+// decoder is bounds-checked and malformed elements are skipped rather than
+// trusted.
+package ie
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Element IDs (IEEE 802.11-2020 Table 9-92)
+const (
+	IDSSID                  = 0
+	IDSupportedRates        = 1
+	IDDSParameterSet        = 3
+	IDCountry               = 7
+	IDBSSLoad               = 11
+	IDHTCapabilities        = 45
+	IDRSN                   = 48
+	IDExtendedRates         = 50
+	IDMobilityDomain        = 54
+	IDHTOperation           = 61
+	IDRMEnabledCapabilities = 70
+	IDExtendedCapabilities  = 127
+	IDVHTCapabilities       = 191
+	IDVHTOperation          = 192
+	IDVendorSpecific        = 221
+	IDExtension             = 255
+)
+
+// Element ID extensions carried in IDExtension elements
+const (
+	ExtHECapabilities  = 35
+	ExtHEOperation     = 36
+	ExtEHTOperation    = 106
+	ExtEHTCapabilities = 108
+)
+
+// ErrTruncated is returned when an element claims more bytes than remain in the buffer
+var ErrTruncated = errors.New("ie: truncated information element")
+
+// Element is a single raw information element
+type Element struct {
+	ID   uint8
+	Ext  uint8 // Element ID extension, only meaningful when ID is IDExtension
+	Data []byte
+}
+
+// Parse splits a raw IE blob into elements. Elements parsed before a
+// truncated trailing element are returned together with ErrTruncated.
+func Parse(b []byte) ([]Element, error) {
+	var elements []Element
+
+	for len(b) > 0 {
+		if len(b) < 2 {
+			return elements, ErrTruncated
+		}
+		id, length := b[0], int(b[1])
+		if len(b) < 2+length {
+			return elements, ErrTruncated
+		}
+
+		element := Element{ID: id, Data: b[2 : 2+length]}
+		if id == IDExtension {
+			if length == 0 {
+				b = b[2+length:]
+				continue
+			}
+			element.Ext = element.Data[0]
+			element.Data = element.Data[1:]
+		}
+
+		elements = append(elements, element)
+		b = b[2+length:]
+	}
+
+	return elements, nil
+}
+
+// Elements holds the decoded information elements of a single BSS.
+// Pointer fields are nil when the corresponding element was absent or malformed.
+type Elements struct {
+	SSID           string
+	SupportedRates []int // In 500 kbit/s units, basic-rate flag stripped
+	DSChannel      int   // Current channel from the DS Parameter Set, 0 if absent
+
+	Country               *Country
+	BSSLoad               *BSSLoad
+	HTCapabilities        *HTCapabilities
+	HTOperation           *HTOperation
+	VHTCapabilities       *VHTCapabilities
+	VHTOperation          *VHTOperation
+	HECapabilities        bool
+	HEOperation           *HEOperation
+	EHTCapabilities       bool
+	RSN                   *RSN
+	WPA                   *RSN // Legacy WPA vendor element, decoded with the RSN layout
+	WPS                   *WPS
+	RMEnabledCapabilities *RMEnabledCapabilities
+	ExtendedCapabilities  *ExtendedCapabilities
+	MobilityDomain        *MobilityDomain
+
+	Vendor []Element // Vendor-specific elements not decoded above
+}
+
+// Country is the Country element (9.4.2.8)
+type Country struct {
+	Code        string // ISO 3166-1 alpha-2 code
+	Environment byte   // ' ' any, 'O' outdoor, 'I' indoor, 'X' non-country
+	Channels    []CountryChannels
+}
+
+// CountryChannels is a subband triplet of the Country element
+type CountryChannels struct {
+	FirstChannel int
+	Count        int
+	MaxPowerDBm  int
+}
+
+// BSSLoad is the BSS Load element (9.4.2.27)
+type BSSLoad struct {
+	StationCount       int
+	ChannelUtilization int // 0-255, fraction of time the AP sensed the medium busy
+	AdmissionCapacity  int // In units of 32 µs/s
+}
+
+// UtilizationPercent returns the channel utilization as a percentage
+func (b *BSSLoad) UtilizationPercent() int {
+	return b.ChannelUtilization * 100 / 255
+}
+
+// HTCapabilities is the HT Capabilities element (9.4.2.55)
+type HTCapabilities struct {
+	Info           uint16
+	SupportsHT40   bool
+	SpatialStreams int
+}
+
+// HTOperation is the HT Operation element (9.4.2.56)
+type HTOperation struct {
+	PrimaryChannel  int
+	SecondaryOffset int  // 1 above, -1 below, 0 none
+	AnyWidth        bool // STA Channel Width: 40 MHz operation allowed
+}
+
+// VHTCapabilities is the VHT Capabilities element (9.4.2.157)
+type VHTCapabilities struct {
+	Info           uint32
+	SupportedWidth int // 0: 80 MHz, 1: 160 MHz, 2: 160 and 80+80 MHz
+	SpatialStreams int
+}
+
+// VHTOperation is the VHT Operation element (9.4.2.158)
+type VHTOperation struct {
+	ChannelWidth   int // 0: 20/40 MHz, 1: 80/160/80+80 MHz, 2: 160 MHz (deprecated), 3: 80+80 MHz (deprecated)
+	CenterSegment0 int
+	CenterSegment1 int
+}
+
+// HEOperation is the HE Operation element (9.4.2.249)
+type HEOperation struct {
+	Parameters   uint32
+	BSSColor     int
+	VHTOperation *VHTOperation // Present in 5 GHz HE-only BSSs
+	SixGHz       *SixGHzOperation
+}
+
+// SixGHzOperation is the 6 GHz Operation Information field of the HE Operation element
+type SixGHzOperation struct {
+	PrimaryChannel int
+	ChannelWidth   int // 0: 20 MHz, 1: 40 MHz, 2: 80 MHz, 3: 160 or 80+80 MHz
+	CenterSegment0 int
+	CenterSegment1 int
+	MinimumRate    int // In 1 Mbit/s units
+}
+
+// RMEnabledCapabilities is the RM Enabled Capabilities element (9.4.2.44)
+type RMEnabledCapabilities struct {
+	Raw []byte
+}
+
+// NeighborReport reports whether the AP supports 802.11k neighbor reports
+func (r *RMEnabledCapabilities) NeighborReport() bool { return bitSet(r.Raw, 1) }
+
+// BeaconReport reports whether the AP supports any 802.11k beacon measurement mode
+func (r *RMEnabledCapabilities) BeaconReport() bool {
+	return bitSet(r.Raw, 4) || bitSet(r.Raw, 5) || bitSet(r.Raw, 6)
+}
+
+// ExtendedCapabilities is the Extended Capabilities element (9.4.2.26)
+type ExtendedCapabilities struct {
+	Raw []byte
+}
+
+// Has reports whether the given capability bit is set
+func (e *ExtendedCapabilities) Has(bit int) bool { return bitSet(e.Raw, bit) }
+
+// BSSTransition reports 802.11v BSS Transition Management support
+func (e *ExtendedCapabilities) BSSTransition() bool { return e.Has(19) }
+
+// Interworking reports 802.11u Interworking (Hotspot 2.0) support
+func (e *ExtendedCapabilities) Interworking() bool { return e.Has(31) }
+
+// MobilityDomain is the Mobility Domain element (9.4.2.46), advertised by 802.11r fast-transition networks
+type MobilityDomain struct {
+	MDID     uint16
+	FTOverDS bool
+}
+
+// Decode parses a raw IE blob and decodes every element it understands.
+// Malformed elements are ignored; ErrTruncated is returned alongside the
+// decoded data when the blob ends mid-element.
+func Decode(b []byte) (*Elements, error) {
+	elements, err := Parse(b)
+
+	e := &Elements{}
+	for _, element := range elements {
+		e.decode(element)
+	}
+
+	return e, err
+}
+
+// decode dispatches a single element to its decoder
+func (e *Elements) decode(element Element) {
+	data := element.Data
+
+	switch element.ID {
+	case IDSSID:
+		e.SSID = string(data)
+	case IDSupportedRates, IDExtendedRates:
+		for _, rate := range data {
+			e.SupportedRates = append(e.SupportedRates, int(rate&0x7f))
+		}
+	case IDDSParameterSet:
+		if len(data) >= 1 {
+			e.DSChannel = int(data[0])
+		}
+	case IDCountry:
+		e.Country = decodeCountry(data)
+	case IDBSSLoad:
+		if len(data) >= 5 {
+			e.BSSLoad = &BSSLoad{
+				StationCount:       int(binary.LittleEndian.Uint16(data[0:2])),
+				ChannelUtilization: int(data[2]),
+				AdmissionCapacity:  int(binary.LittleEndian.Uint16(data[3:5])),
+			}
+		}
+	case IDHTCapabilities:
+		if len(data) >= 26 {
+			info := binary.LittleEndian.Uint16(data[0:2])
+			e.HTCapabilities = &HTCapabilities{
+				Info:           info,
+				SupportsHT40:   info&0x0002 != 0,
+				SpatialStreams: htSpatialStreams(data[3:7]),
+			}
+		}
+	case IDHTOperation:
+		if len(data) >= 22 {
+			op := &HTOperation{
+				PrimaryChannel: int(data[0]),
+				AnyWidth:       data[1]&0x04 != 0,
+			}
+			switch data[1] & 0x03 {
+			case 1:
+				op.SecondaryOffset = 1
+			case 3:
+				op.SecondaryOffset = -1
+			}
+			e.HTOperation = op
+		}
+	case IDVHTCapabilities:
+		if len(data) >= 12 {
+			info := binary.LittleEndian.Uint32(data[0:4])
+			e.VHTCapabilities = &VHTCapabilities{
+				Info:           info,
+				SupportedWidth: int(info>>2) & 0x3,
+				SpatialStreams: vhtSpatialStreams(binary.LittleEndian.Uint16(data[4:6])),
+			}
+		}
+	case IDVHTOperation:
+		e.VHTOperation = decodeVHTOperation(data)
+	case IDRSN:
+		if rsn, err := decodeRSN(data, suiteOUIRSN); err == nil {
+			e.RSN = rsn
+		}
+	case IDRMEnabledCapabilities:
+		if len(data) >= 5 {
+			e.RMEnabledCapabilities = &RMEnabledCapabilities{Raw: data}
+		}
+	case IDExtendedCapabilities:
+		e.ExtendedCapabilities = &ExtendedCapabilities{Raw: data}
+	case IDMobilityDomain:
+		if len(data) >= 3 {
+			e.MobilityDomain = &MobilityDomain{
+				MDID:     binary.LittleEndian.Uint16(data[0:2]),
+				FTOverDS: data[2]&0x01 != 0,
+			}
+		}
+	case IDVendorSpecific:
+		e.decodeVendor(element)
+	case IDExtension:
+		switch element.Ext {
+		case ExtHECapabilities:
+			e.HECapabilities = true
+		case ExtHEOperation:
+			e.HEOperation = decodeHEOperation(data)
+		case ExtEHTCapabilities:
+			e.EHTCapabilities = true
+		}
+	}
+}
+
+// decodeVendor handles the Microsoft WPA and WPS vendor elements
+func (e *Elements) decodeVendor(element Element) {
+	data := element.Data
+	if len(data) >= 4 && data[0] == 0x00 && data[1] == 0x50 && data[2] == 0xf2 {
+		switch data[3] {
+		case 1:
+			if wpa, err := decodeRSN(data[4:], suiteOUIWPA); err == nil {
+				e.WPA = wpa
+				return
+			}
+		case 4:
+			e.WPS = decodeWPS(data[4:])
+			return
+		}
+	}
+	e.Vendor = append(e.Vendor, element)
+}
+
+// decodeCountry decodes the Country element
+func decodeCountry(data []byte) *Country {
+	if len(data) < 3 {
+		return nil
+	}
+
+	country := &Country{
+		Code:        string(data[0:2]),
+		Environment: data[2],
+	}
+	for triplet := data[3:]; len(triplet) >= 3; triplet = triplet[3:] {
+		// First channel values of 201 and above introduce operating-class triplets
+		if triplet[0] >= 201 {
+			continue
+		}
+		country.Channels = append(country.Channels, CountryChannels{
+			FirstChannel: int(triplet[0]),
+			Count:        int(triplet[1]),
+			MaxPowerDBm:  int(int8(triplet[2])),
+		})
+	}
+	return country
+}
+
+// decodeVHTOperation decodes the 3-byte VHT Operation Information field
+func decodeVHTOperation(data []byte) *VHTOperation {
+	if len(data) < 3 {
+		return nil
+	}
+	return &VHTOperation{
+		ChannelWidth:   int(data[0]),
+		CenterSegment0: int(data[1]),
+		CenterSegment1: int(data[2]),
+	}
+}
+
+// decodeHEOperation decodes the HE Operation element including its optional fields
+func decodeHEOperation(data []byte) *HEOperation {
+	if len(data) < 6 {
+		return nil
+	}
+
+	params := uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16
+	op := &HEOperation{
+		Parameters: params,
+		BSSColor:   int(data[3] & 0x3f),
+	}
+
+	rest := data[6:]
+	if params&(1<<14) != 0 {
+		if len(rest) < 3 {
+			return op
+		}
+		op.VHTOperation = decodeVHTOperation(rest[:3])
+		rest = rest[3:]
+	}
+	if params&(1<<15) != 0 {
+		if len(rest) < 1 {
+			return op
+		}
+		rest = rest[1:]
+	}
+	if params&(1<<17) != 0 && len(rest) >= 5 {
+		op.SixGHz = &SixGHzOperation{
+			PrimaryChannel: int(rest[0]),
+			ChannelWidth:   int(rest[1] & 0x03),
+			CenterSegment0: int(rest[2]),
+			CenterSegment1: int(rest[3]),
+			MinimumRate:    int(rest[4]),
+		}
+	}
+
+	return op
+}
+
+// htSpatialStreams counts spatial streams from the HT Rx MCS bitmask
+func htSpatialStreams(mcs []byte) int {
+	streams := 0
+	for i, b := range mcs {
+		if b != 0 {
+			streams = i + 1
+		}
+	}
+	return streams
+}
+
+// vhtSpatialStreams counts spatial streams from the VHT Rx MCS map
+func vhtSpatialStreams(mcsMap uint16) int {
+	streams := 0
+	for i := 0; i < 8; i++ {
+		if (mcsMap>>(2*i))&0x3 != 0x3 {
+			streams = i + 1
+		}
+	}
+	return streams
+}
+
+// bitSet reports whether bit n of a little-endian bitfield is set
+func bitSet(raw []byte, n int) bool {
+	if n < 0 || n/8 >= len(raw) {
+		return false
+	}
+	return raw[n/8]&(1<<(n%8)) != 0
+}
@@ -0,0 +1,394 @@
+package ie
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Beacon IEs captured from access points, written as hex with element boundaries spaced
+var (
+	// 5 GHz 802.11ax AP: WPA2/WPA3 transition, 80 MHz on channel 36, BSS Load, country US
+	beaconOffice = mustHex(`
+		0006 4f6666696365
+		0108 8c129824b048606c
+		0301 24
+		0706 555320240817
+		0b05 03002a0000
+		2d1a ef091bffff000000000000000000000000000000000000000000
+		3018 0100000fac040100000fac040200000fac02000fac088000
+		3d16 24050000000000000000000000000000000000000000
+		7f08 0400080000000040
+		bf0c 9159820ffaff0000faff0000
+		c005 012a00fcff
+		ff03 230102
+		ff07 24f401000afcff
+		dd07 0050f202000100`)
+
+	// 2.4 GHz 802.11g AP: WPA/WPA2 mixed mode with WPS, country DE
+	beaconCafe = mustHex(`
+		0008 436166652032 2e34
+		0108 82848b960c121824
+		0301 06
+		0706 444520010d14
+		dd16 0050f201 0100 0050f202 0100 0050f202 0100 0050f202
+		3014 0100000fac020100000fac040100000fac020000
+		dd18 0050f204 104a000110 1044000102 10210006 56656e646f72`)
+
+	// 6 GHz 802.11be AP: WPA3-SAE only, 80 MHz centered on channel 39
+	beaconLab = mustHex(`
+		0004 4c616236
+		0108 8c129824b048606c
+		3014 0100000fac040100000fac040100000fac08c000
+		ff0c 24000002 01fcff 2502270004
+		ff02 6c00`)
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// encodeElements writes parsed elements back into a raw IE blob
+func encodeElements(elements []Element) []byte {
+	var b []byte
+	for _, element := range elements {
+		data := element.Data
+		if element.ID == IDExtension {
+			data = append([]byte{element.Ext}, data...)
+		}
+		b = append(b, element.ID, byte(len(data)))
+		b = append(b, data...)
+	}
+	return b
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range [][]byte{beaconOffice, beaconCafe, beaconLab, {}, {0xff, 0x00}, beaconOffice[:40]} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		elements, err := Parse(b)
+		if err != nil && !errors.Is(err, ErrTruncated) {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		// Re-encoding what was parsed must parse to the same elements
+		encoded := encodeElements(elements)
+		if len(encoded) > len(b) {
+			t.Fatalf("parsed %d bytes of elements from a %d byte blob", len(encoded), len(b))
+		}
+		again, err := Parse(encoded)
+		if err != nil {
+			t.Fatalf("re-encoded elements do not parse: %v", err)
+		}
+		if len(again) != len(elements) {
+			t.Fatalf("re-encoded %d elements, parsed %d", len(elements), len(again))
+		}
+		for i := range again {
+			if again[i].ID != elements[i].ID || again[i].Ext != elements[i].Ext || !bytes.Equal(again[i].Data, elements[i].Data) {
+				t.Fatalf("element %d changed from %+v to %+v", i, elements[i], again[i])
+			}
+		}
+	})
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, seed := range [][]byte{beaconOffice, beaconCafe, beaconLab, beaconCafe[:60]} {
+		f.Add(seed, uint8(36), uint16(5180))
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte, channel uint8, frequency uint16) {
+		elements, _ := Decode(b)
+
+		for _, privacy := range []bool{false, true} {
+			if elements.Security(privacy) == "" {
+				t.Fatal("empty security label")
+			}
+		}
+		op := elements.Operation(int(channel))
+		switch op.Width {
+		case 20, 40, 80, 160:
+		default:
+			t.Fatalf("operating width %d", op.Width)
+		}
+		if elements.PHYMode(int(frequency)) == "" {
+			t.Fatal("empty PHY mode")
+		}
+		if elements.RMEnabledCapabilities != nil {
+			elements.RMEnabledCapabilities.NeighborReport()
+			elements.RMEnabledCapabilities.BeaconReport()
+		}
+		if elements.ExtendedCapabilities != nil {
+			elements.ExtendedCapabilities.BSSTransition()
+			elements.ExtendedCapabilities.Interworking()
+		}
+		for _, r := range []*RSN{elements.RSN, elements.WPA} {
+			if r != nil {
+				r.Ciphers()
+				r.MFPRequired()
+			}
+		}
+	})
+}
+
+func TestDecodeOffice(t *testing.T) {
+	e, err := Decode(beaconOffice)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if e.SSID != "Office" || e.DSChannel != 36 {
+		t.Errorf("SSID %q, DS channel %d", e.SSID, e.DSChannel)
+	}
+	wantCountry := &Country{Code: "US", Environment: ' ', Channels: []CountryChannels{{FirstChannel: 36, Count: 8, MaxPowerDBm: 23}}}
+	if !reflect.DeepEqual(e.Country, wantCountry) {
+		t.Errorf("Country = %+v, want %+v", e.Country, wantCountry)
+	}
+	if e.BSSLoad == nil || e.BSSLoad.StationCount != 3 || e.BSSLoad.UtilizationPercent() != 16 {
+		t.Errorf("BSSLoad = %+v", e.BSSLoad)
+	}
+	if e.HTCapabilities == nil || !e.HTCapabilities.SupportsHT40 || e.HTCapabilities.SpatialStreams != 2 {
+		t.Errorf("HTCapabilities = %+v", e.HTCapabilities)
+	}
+	if e.HTOperation == nil || e.HTOperation.PrimaryChannel != 36 || e.HTOperation.SecondaryOffset != 1 || !e.HTOperation.AnyWidth {
+		t.Errorf("HTOperation = %+v", e.HTOperation)
+	}
+	if e.VHTCapabilities == nil || e.VHTCapabilities.SpatialStreams != 2 || e.VHTCapabilities.SupportedWidth != 0 {
+		t.Errorf("VHTCapabilities = %+v", e.VHTCapabilities)
+	}
+	if want := (&VHTOperation{ChannelWidth: 1, CenterSegment0: 42}); !reflect.DeepEqual(e.VHTOperation, want) {
+		t.Errorf("VHTOperation = %+v, want %+v", e.VHTOperation, want)
+	}
+	if !e.HECapabilities || e.HEOperation == nil || e.HEOperation.BSSColor != 10 || e.HEOperation.SixGHz != nil {
+		t.Errorf("HE capabilities %v, operation %+v", e.HECapabilities, e.HEOperation)
+	}
+	if e.ExtendedCapabilities == nil || !e.ExtendedCapabilities.BSSTransition() || e.ExtendedCapabilities.Interworking() {
+		t.Errorf("ExtendedCapabilities = %+v", e.ExtendedCapabilities)
+	}
+
+	if e.RSN == nil || e.RSN.Ciphers() != "CCMP" || !e.RSN.HasAKM(AKMPSK) || !e.RSN.HasAKM(AKMSAE) ||
+		!e.RSN.MFPCapable() || e.RSN.MFPRequired() {
+		t.Errorf("RSN = %+v", e.RSN)
+	}
+	if len(e.Vendor) != 1 {
+		t.Errorf("kept %d undecoded vendor elements, want the WMM element", len(e.Vendor))
+	}
+
+	if got, want := e.Operation(36), (Operation{Width: 80, CenterChannel: 42, SecondaryOffset: 1}); got != want {
+		t.Errorf("Operation = %+v, want %+v", got, want)
+	}
+	if got := e.PHYMode(5180); got != "802.11ax" {
+		t.Errorf("PHYMode = %q", got)
+	}
+}
+
+func TestDecodeCafe(t *testing.T) {
+	e, err := Decode(beaconCafe)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if e.SSID != "Cafe 2.4" || e.DSChannel != 6 || e.Country == nil || e.Country.Code != "DE" {
+		t.Errorf("SSID %q, DS channel %d, country %+v", e.SSID, e.DSChannel, e.Country)
+	}
+	if e.WPA == nil || e.WPA.Ciphers() != "TKIP" || !e.WPA.HasAKM(AKMPSK) {
+		t.Errorf("WPA = %+v", e.WPA)
+	}
+	if e.RSN == nil || e.RSN.GroupCipher.Type != CipherTKIP || e.RSN.Ciphers() != "CCMP" {
+		t.Errorf("RSN = %+v", e.RSN)
+	}
+	if want := (&WPS{Version: 0x10, Configured: true, Manufacturer: "Vendor"}); !reflect.DeepEqual(e.WPS, want) {
+		t.Errorf("WPS = %+v, want %+v", e.WPS, want)
+	}
+	if len(e.Vendor) != 0 {
+		t.Errorf("WPA and WPS left in Vendor: %+v", e.Vendor)
+	}
+	if got := e.Operation(6); got.Width != 20 || got.CenterChannel != 6 {
+		t.Errorf("Operation = %+v", got)
+	}
+	if got := e.PHYMode(2437); got != "802.11g" {
+		t.Errorf("PHYMode = %q", got)
+	}
+}
+
+func TestDecodeLab(t *testing.T) {
+	e, err := Decode(beaconLab)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	want := &SixGHzOperation{PrimaryChannel: 37, ChannelWidth: 2, CenterSegment0: 39, MinimumRate: 4}
+	if e.HEOperation == nil || !reflect.DeepEqual(e.HEOperation.SixGHz, want) {
+		t.Fatalf("HEOperation = %+v, want 6 GHz info %+v", e.HEOperation, want)
+	}
+	if got := e.Operation(37); got != (Operation{Width: 80, CenterChannel: 39, SixGHz: true}) {
+		t.Errorf("Operation = %+v", got)
+	}
+	if !e.RSN.MFPRequired() {
+		t.Error("MFPRequired = false")
+	}
+	if got := e.PHYMode(5955); got != "802.11be" {
+		t.Errorf("PHYMode = %q", got)
+	}
+}
+
+func TestParseTruncated(t *testing.T) {
+	elements, err := Parse(beaconOffice[:24])
+	if !errors.Is(err, ErrTruncated) {
+		t.Fatalf("error = %v, want ErrTruncated", err)
+	}
+	if len(elements) != 3 {
+		t.Errorf("kept %d elements before the truncated one, want 3", len(elements))
+	}
+
+	// Elements too short for their decoder are ignored, not trusted
+	e, _ := Decode(mustHex("0b02 0300 3d01 24 c002 0100 3002 0100 0702 5553 ff01 24"))
+	if e.BSSLoad != nil || e.HTOperation != nil || e.VHTOperation != nil || e.Country != nil || e.HEOperation != nil {
+		t.Errorf("decoded short elements: %+v", e)
+	}
+	if e.RSN == nil || e.RSN.Ciphers() != "CCMP" || !e.RSN.HasAKM(AKM8021X) {
+		t.Errorf("version-only RSN did not take the defaults: %+v", e.RSN)
+	}
+}
+
+func TestDecodeRSNMalformed(t *testing.T) {
+	tests := []string{
+		"01",                               // Version cut short
+		"0100 000fac",                      // Group cipher cut short
+		"0100 000fac04 0200 000fac04",      // Pairwise count exceeds the suites present
+		"0100 000fac04 0100 000fac04 0100", // AKM count without suites
+	}
+	for _, test := range tests {
+		if rsn, err := decodeRSN(mustHex(test), suiteOUIRSN); err == nil {
+			t.Errorf("decodeRSN(%s) = %+v, want an error", test, rsn)
+		}
+	}
+}
+
+func TestDecodeWPSTruncated(t *testing.T) {
+	// The model name claims more bytes than remain; earlier attributes survive
+	wps := decodeWPS(mustHex("104a000110 10230010 4d6f64"))
+	if wps.Version != 0x10 || wps.ModelName != "" {
+		t.Errorf("decodeWPS = %+v", wps)
+	}
+}
+
+func TestCountryOperatingClassTriplets(t *testing.T) {
+	country := decodeCountry(mustHex("55534f 240417 c90100 95051e"))
+	want := []CountryChannels{{FirstChannel: 36, Count: 4, MaxPowerDBm: 23}, {FirstChannel: 149, Count: 5, MaxPowerDBm: 30}}
+	if country == nil || country.Environment != 'O' || !reflect.DeepEqual(country.Channels, want) {
+		t.Errorf("decodeCountry = %+v, want channels %+v", country, want)
+	}
+}
+
+// rsnElement builds an RSN element body advertising the given AKM suites with CCMP
+func rsnElement(akms ...byte) []byte {
+	b := mustHex("0100 000fac04 0100 000fac04")
+	b = append(b, byte(len(akms)), 0)
+	for _, akm := range akms {
+		b = append(b, 0x00, 0x0f, 0xac, akm)
+	}
+	return append([]byte{IDRSN, byte(len(b))}, b...)
+}
+
+// wpaElement builds a legacy WPA vendor element advertising the given AKM suites with TKIP
+func wpaElement(akms ...byte) []byte {
+	b := mustHex("0050f201 0100 0050f202 0100 0050f202")
+	b = append(b, byte(len(akms)), 0)
+	for _, akm := range akms {
+		b = append(b, 0x00, 0x50, 0xf2, akm)
+	}
+	return append([]byte{IDVendorSpecific, byte(len(b))}, b...)
+}
+
+func TestElementsSecurity(t *testing.T) {
+	tests := []struct {
+		name    string
+		ies     []byte
+		privacy bool
+		want    string
+	}{
+		{"open", nil, false, "Open"},
+		{"WEP", nil, true, "WEP"},
+		{"WPA2 Personal", rsnElement(AKMPSK), true, "WPA2 Personal"},
+		{"WPA2 Personal with FT and SHA-256", rsnElement(AKMFTPSK, AKMPSKSHA256), true, "WPA2 Personal"},
+		{"WPA3 Personal", rsnElement(AKMSAE), true, "WPA3 Personal"},
+		{"WPA3 Personal with the extended key", rsnElement(AKMSAEExtKey, AKMFTSAEExtKey), true, "WPA3 Personal"},
+		{"WPA2/WPA3 transition", rsnElement(AKMPSK, AKMSAE), true, "WPA2/WPA3 Personal"},
+		{"WPA2 Enterprise", rsnElement(AKM8021X), true, "WPA2 Enterprise"},
+		{"WPA2 Enterprise with FT", rsnElement(AKMFT8021X, AKM8021XSHA256), true, "WPA2 Enterprise"},
+		{"WPA3 Enterprise 192-bit", rsnElement(AKMSuiteB192), true, "WPA3 Enterprise"},
+		{"WPA3 Enterprise with SAE", rsnElement(AKMSAE, AKM8021X), true, "WPA3 Enterprise"},
+		{"OWE", rsnElement(AKMOWE), true, "OWE"},
+		{"OWE alongside PSK", rsnElement(AKMPSK, AKMOWE), true, "WPA2 Personal"},
+		{"WPA Personal", wpaElement(AKMPSK), true, "WPA Personal"},
+		{"WPA Enterprise", wpaElement(AKM8021X), true, "WPA Enterprise"},
+		{"WPA/WPA2 mixed", append(wpaElement(AKMPSK), rsnElement(AKMPSK)...), true, "WPA/WPA2 Personal"},
+		{"RSN with defaults", mustHex("3002 0100"), true, "WPA2 Enterprise"},
+		{"RSN with only unknown AKMs", rsnElement(7), true, "WPA2"},
+		{"AKM under a foreign OUI", mustHex("3012 0100 000fac04 0100 000fac04 0100 00113302"), true, "WPA2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e, err := Decode(test.ies)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if got := e.Security(test.privacy); got != test.want {
+				t.Errorf("Security = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestClassifySecurity(t *testing.T) {
+	tests := []struct {
+		label string
+		want  SecurityClass
+	}{
+		{"Open", SecurityOpen},
+		{"None", SecurityOpen}, // macOS
+		{"OWE", SecurityOpen},
+		{"WEP", SecurityWEP},
+		{"WPA Personal", SecurityPersonal},
+		{"WPA2 Personal", SecurityPersonal},
+		{"WPA2/WPA3 Personal", SecurityPersonal},
+		{"WPA3 Personal", SecurityPersonal},
+		{"WPA2-Personal", SecurityPersonal},
+		{"WPA2-PSK", SecurityPersonal},
+		{"WPA/WPA2 Enterprise", SecurityEnterprise},
+		{"WPA3 Enterprise", SecurityEnterprise},
+		{"wpa2-enterprise", SecurityEnterprise},
+		{"WPA2 802.1X", SecurityEnterprise},
+		// A generation without key management may be either
+		{"WPA", SecurityUnknown},
+		{"WPA2", SecurityUnknown},
+		{"WPA/WPA2", SecurityUnknown},
+		{"Unknown", SecurityUnknown},
+		{"", SecurityUnknown},
+	}
+
+	for _, test := range tests {
+		if got := ClassifySecurity(test.label); got != test.want {
+			t.Errorf("ClassifySecurity(%q) = %s, want %s", test.label, got, test.want)
+		}
+	}
+
+	// Every label SecurityLabel produces with key management has a class
+	for _, akms := range [][]int{{AKMPSK}, {AKMSAE}, {AKMPSK, AKMSAE}, {AKM8021X}, {AKMSuiteB192}, {AKMOWE}} {
+		for _, elements := range [][2]bool{{true, false}, {false, true}, {true, true}} {
+			if label := SecurityLabel(true, elements[0], elements[1], akms); ClassifySecurity(label) == SecurityUnknown {
+				t.Errorf("ClassifySecurity(%q) = Unknown", label)
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package ie
+
+// Operation summarizes the operating channel advertised by the HT, VHT and HE operation elements
+type Operation struct {
+	Width           int  // Channel width in MHz (20, 40, 80, 160)
+	CenterChannel   int  // Channel number at the center of the occupied block
+	SecondaryOffset int  // HT secondary channel offset: 1 above, -1 below, 0 none
+	Split           bool // 80+80 MHz operation; CenterChannel is the first segment
+	SixGHz          bool // Center channel is numbered in the 6 GHz band
+}
+
+// Operation derives the operating width and block center from the newest
+// operation element present. primaryChannel is used for 20 MHz operation and
+// for HT 40 MHz blocks, where no center channel is advertised.
+func (e *Elements) Operation(primaryChannel int) Operation {
+	op := Operation{Width: 20, CenterChannel: primaryChannel}
+	if e.HTOperation != nil && e.HTOperation.AnyWidth && e.HTOperation.SecondaryOffset != 0 {
+		op.Width = 40
+		op.SecondaryOffset = e.HTOperation.SecondaryOffset
+		op.CenterChannel = primaryChannel + 2*op.SecondaryOffset
+	}
+
+	vht := e.VHTOperation
+	if e.HEOperation != nil {
+		if six := e.HEOperation.SixGHz; six != nil {
+			op.SixGHz = true
+			op.CenterChannel = six.CenterSegment0
+			switch six.ChannelWidth {
+			case 0:
+				op.Width = 20
+			case 1:
+				op.Width = 40
+			case 2:
+				op.Width = 80
+			case 3:
+				op.Width = 160
+				switch diff := absDiff(six.CenterSegment0, six.CenterSegment1); {
+				case six.CenterSegment1 == 0:
+				case diff == 8:
+					op.CenterChannel = six.CenterSegment1
+				case diff > 16:
+					op.Split = true
+				}
+			}
+			return op
+		}
+		if vht == nil {
+			vht = e.HEOperation.VHTOperation
+		}
+	}
+
+	if vht != nil {
+		switch vht.ChannelWidth {
+		case 1:
+			op.Width = 80
+			op.CenterChannel = vht.CenterSegment0
+			if vht.CenterSegment1 != 0 {
+				switch diff := absDiff(vht.CenterSegment0, vht.CenterSegment1); {
+				case diff == 8:
+					op.Width = 160
+					op.CenterChannel = vht.CenterSegment1
+				case diff > 16:
+					op.Width = 160
+					op.Split = true
+				}
+			}
+		case 2:
+			op.Width = 160
+			op.CenterChannel = vht.CenterSegment0
+		case 3:
+			op.Width = 160
+			op.Split = true
+			op.CenterChannel = vht.CenterSegment0
+		}
+	}
+
+	return op
+}
+
+// PHYMode returns the highest 802.11 amendment the BSS advertises, e.g. "802.11ax".
+// frequency is the primary channel frequency in MHz, used to tell 802.11a from 802.11b/g.
+func (e *Elements) PHYMode(frequency int) string {
+	switch {
+	case e.EHTCapabilities:
+		return "802.11be"
+	case e.HECapabilities || e.HEOperation != nil:
+		return "802.11ax"
+	case e.VHTCapabilities != nil || e.VHTOperation != nil:
+		return "802.11ac"
+	case e.HTCapabilities != nil || e.HTOperation != nil:
+		return "802.11n"
+	case frequency > 5000:
+		return "802.11a"
+	}
+
+	// Rates above 11 Mbit/s are OFDM and imply 802.11g
+	for _, rate := range e.SupportedRates {
+		if rate > 22 {
+			return "802.11g"
+		}
+	}
+	return "802.11b"
+}
+
+// absDiff returns the absolute difference between two integers
+func absDiff(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
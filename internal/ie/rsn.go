@@ -0,0 +1,314 @@
+package ie
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// Suite OUIs for RSN (00-0F-AC) and the legacy Microsoft WPA element (00-50-F2)
+var (
+	suiteOUIRSN = [3]byte{0x00, 0x0f, 0xac}
+	suiteOUIWPA = [3]byte{0x00, 0x50, 0xf2}
+)
+
+// Cipher suite types (9.4.2.24.2)
+const (
+	CipherWEP40   = 1
+	CipherTKIP    = 2
+	CipherCCMP    = 4
+	CipherWEP104  = 5
+	CipherBIP     = 6
+	CipherGCMP    = 8
+	CipherGCMP256 = 9
+	CipherCCMP256 = 10
+)
+
+// AKM suite types (9.4.2.24.3)
+const (
+	AKM8021X         = 1
+	AKMPSK           = 2
+	AKMFT8021X       = 3
+	AKMFTPSK         = 4
+	AKM8021XSHA256   = 5
+	AKMPSKSHA256     = 6
+	AKMSAE           = 8
+	AKMFTSAE         = 9
+	AKMSuiteB        = 11
+	AKMSuiteB192     = 12
+	AKMFT8021XSHA384 = 13
+	AKMOWE           = 18
+	AKMSAEExtKey     = 24
+	AKMFTSAEExtKey   = 25
+)
+
+// errMalformedRSN is returned for RSN/WPA elements that end mid-field
+var errMalformedRSN = errors.New("ie: malformed RSN element")
+
+// Suite is a cipher or AKM suite selector
+type Suite struct {
+	OUI  [3]byte
+	Type int
+}
+
+// RSN is the decoded RSN element (9.4.2.24), also used for the legacy WPA vendor element
+type RSN struct {
+	Version         int
+	GroupCipher     Suite
+	PairwiseCiphers []Suite
+	AKMSuites       []Suite
+	Capabilities    uint16
+	oui             [3]byte
+}
+
+// MFPRequired reports whether management frame protection is mandatory
+func (r *RSN) MFPRequired() bool { return r.Capabilities&0x0040 != 0 }
+
+// MFPCapable reports whether management frame protection is supported
+func (r *RSN) MFPCapable() bool { return r.Capabilities&0x0080 != 0 }
+
+// HasAKM reports whether the element advertises any of the given AKM suite types
+func (r *RSN) HasAKM(types ...int) bool {
+	for _, suite := range r.AKMSuites {
+		if suite.OUI != r.oui {
+			continue
+		}
+		for _, t := range types {
+			if suite.Type == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeRSN decodes the RSN element body. Every field after the version is
+// optional, and a missing field implies the defaults defined by the standard.
+func decodeRSN(data []byte, oui [3]byte) (*RSN, error) {
+	if len(data) < 2 {
+		return nil, errMalformedRSN
+	}
+
+	rsn := &RSN{
+		Version:         int(binary.LittleEndian.Uint16(data[0:2])),
+		GroupCipher:     Suite{OUI: oui, Type: CipherCCMP},
+		PairwiseCiphers: []Suite{{OUI: oui, Type: CipherCCMP}},
+		AKMSuites:       []Suite{{OUI: oui, Type: AKM8021X}},
+		oui:             oui,
+	}
+	if oui == suiteOUIWPA {
+		rsn.GroupCipher.Type = CipherTKIP
+		rsn.PairwiseCiphers[0].Type = CipherTKIP
+	}
+	data = data[2:]
+
+	if len(data) == 0 {
+		return rsn, nil
+	}
+	if len(data) < 4 {
+		return nil, errMalformedRSN
+	}
+	rsn.GroupCipher = decodeSuite(data[0:4])
+	data = data[4:]
+
+	var err error
+	if len(data) == 0 {
+		return rsn, nil
+	}
+	if rsn.PairwiseCiphers, data, err = decodeSuiteList(data); err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return rsn, nil
+	}
+	if rsn.AKMSuites, data, err = decodeSuiteList(data); err != nil {
+		return nil, err
+	}
+
+	if len(data) >= 2 {
+		rsn.Capabilities = binary.LittleEndian.Uint16(data[0:2])
+	}
+
+	return rsn, nil
+}
+
+// decodeSuiteList decodes a count-prefixed list of suite selectors
+func decodeSuiteList(data []byte) ([]Suite, []byte, error) {
+	if len(data) < 2 {
+		return nil, nil, errMalformedRSN
+	}
+	count := int(binary.LittleEndian.Uint16(data[0:2]))
+	data = data[2:]
+	if count*4 > len(data) {
+		return nil, nil, errMalformedRSN
+	}
+
+	suites := make([]Suite, 0, count)
+	for i := 0; i < count; i++ {
+		suites = append(suites, decodeSuite(data[i*4:i*4+4]))
+	}
+	return suites, data[count*4:], nil
+}
+
+// decodeSuite decodes a single 4-byte suite selector
+func decodeSuite(data []byte) Suite {
+	return Suite{OUI: [3]byte{data[0], data[1], data[2]}, Type: int(data[3])}
+}
+
+// Security summarizes the advertised security in the style used across the
+// tool ("WPA2 Personal", "WPA2/WPA3 Personal", "WPA3 Enterprise", "Open").
+// privacy is the Privacy bit of the capability field, which distinguishes WEP from Open.
+func (e *Elements) Security(privacy bool) string {
+	var akms []int
+	for _, r := range []*RSN{e.RSN, e.WPA} {
+		if r != nil {
+			akms = append(akms, r.akmTypes()...)
+		}
+	}
+	return SecurityLabel(privacy, e.RSN != nil, e.WPA != nil, akms)
+}
+
+// SecurityLabel builds the label Elements.Security reports from the elements
+// present and the AKM suite types they advertise, so that backends which only
+// see a text rendering of the elements label a BSS the same way.
+func SecurityLabel(privacy, hasRSN, hasWPA bool, akms []int) string {
+	if !hasRSN && !hasWPA {
+		if privacy {
+			return "WEP"
+		}
+		return "Open"
+	}
+
+	var psk, sae, enterprise, owe, suiteB192 bool
+	for _, akm := range akms {
+		switch akm {
+		case AKMPSK, AKMFTPSK, AKMPSKSHA256:
+			psk = true
+		case AKMSAE, AKMFTSAE, AKMSAEExtKey, AKMFTSAEExtKey:
+			sae = true
+		case AKMSuiteB192:
+			suiteB192 = true
+			enterprise = true
+		case AKM8021X, AKMFT8021X, AKM8021XSHA256, AKMSuiteB, AKMFT8021XSHA384:
+			enterprise = true
+		case AKMOWE:
+			owe = true
+		}
+	}
+
+	generation := "WPA2"
+	switch {
+	case !hasRSN:
+		generation = "WPA"
+	case hasWPA:
+		generation = "WPA/WPA2"
+	case sae && psk:
+		generation = "WPA2/WPA3"
+	case sae:
+		generation = "WPA3"
+	case enterprise && suiteB192:
+		generation = "WPA3"
+	}
+
+	switch {
+	case owe && !psk && !sae && !enterprise:
+		return "OWE"
+	case enterprise:
+		return generation + " Enterprise"
+	case psk || sae:
+		return generation + " Personal"
+	}
+	return generation
+}
+
+// SecurityClass groups security labels by what a client needs to join, so that
+// labels which can legitimately share an SSID, such as WPA2 and WPA3 Personal in
+// transition mode, fall in one class
+type SecurityClass int
+
+const (
+	SecurityUnknown    SecurityClass = iota
+	SecurityOpen                     // No credentials: Open, and OWE, which encrypts without authenticating
+	SecurityWEP                      // A shared WEP key
+	SecurityPersonal                 // A passphrase or password: PSK or SAE
+	SecurityEnterprise               // 802.1X credentials
+)
+
+// String returns the class name
+func (c SecurityClass) String() string {
+	switch c {
+	case SecurityOpen:
+		return "Open"
+	case SecurityWEP:
+		return "WEP"
+	case SecurityPersonal:
+		return "Personal"
+	case SecurityEnterprise:
+		return "Enterprise"
+	default:
+		return "Unknown"
+	}
+}
+
+// ClassifySecurity maps a security label to its class. It takes the labels of
+// SecurityLabel and the spellings other backends report, such as "WPA2-Personal"
+// or "None". A generation without key management, such as the bare "WPA2" of
+// iwlist, could be either Personal or Enterprise and is Unknown.
+func ClassifySecurity(label string) SecurityClass {
+	s := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(label), "-", " "))
+	switch {
+	case s == "open" || s == "none" || s == "owe":
+		return SecurityOpen
+	case s == "wep":
+		return SecurityWEP
+	case strings.HasSuffix(s, " enterprise") || strings.Contains(s, "802.1x") || strings.Contains(s, "eap"):
+		return SecurityEnterprise
+	case strings.HasSuffix(s, " personal") || strings.Contains(s, "psk") || strings.Contains(s, "sae"):
+		return SecurityPersonal
+	}
+	return SecurityUnknown
+}
+
+// akmTypes returns the AKM suite types advertised under the element's own OUI
+func (r *RSN) akmTypes() []int {
+	var types []int
+	for _, suite := range r.AKMSuites {
+		if suite.OUI == r.oui {
+			types = append(types, suite.Type)
+		}
+	}
+	return types
+}
+
+// CipherName returns a short name for a cipher suite
+func CipherName(s Suite) string {
+	switch s.Type {
+	case CipherWEP40:
+		return "WEP-40"
+	case CipherTKIP:
+		return "TKIP"
+	case CipherCCMP:
+		return "CCMP"
+	case CipherWEP104:
+		return "WEP-104"
+	case CipherBIP:
+		return "BIP"
+	case CipherGCMP:
+		return "GCMP"
+	case CipherGCMP256:
+		return "GCMP-256"
+	case CipherCCMP256:
+		return "CCMP-256"
+	}
+	return "Unknown"
+}
+
+// Ciphers returns the pairwise cipher names of the element, e.g. "CCMP/TKIP"
+func (r *RSN) Ciphers() string {
+	names := make([]string, 0, len(r.PairwiseCiphers))
+	for _, suite := range r.PairwiseCiphers {
+		names = append(names, CipherName(suite))
+	}
+	return strings.Join(names, "/")
+}
@@ -0,0 +1,70 @@
+package ie
+
+import "encoding/binary"
+
+// WPS attribute types (Wi-Fi Simple Configuration Technical Specification, section 12)
+const (
+	wpsAttrVersion       = 0x104a
+	wpsAttrState         = 0x1044
+	wpsAttrAPSetupLocked = 0x1057
+	wpsAttrManufacturer  = 0x1021
+	wpsAttrModelName     = 0x1023
+	wpsAttrModelNumber   = 0x1024
+	wpsAttrSerialNumber  = 0x1042
+	wpsAttrDeviceName    = 0x1011
+	wpsStateConfigured   = 0x02
+)
+
+// WPS is the Wi-Fi Protected Setup vendor element
+type WPS struct {
+	Version       int
+	Configured    bool
+	APSetupLocked bool
+	Manufacturer  string
+	ModelName     string
+	ModelNumber   string
+	SerialNumber  string
+	DeviceName    string
+}
+
+// decodeWPS decodes the big-endian TLV attributes of a WPS element
+func decodeWPS(data []byte) *WPS {
+	wps := &WPS{}
+
+	for len(data) >= 4 {
+		attrType := binary.BigEndian.Uint16(data[0:2])
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if length > len(data)-4 {
+			break
+		}
+		value := data[4 : 4+length]
+		data = data[4+length:]
+
+		switch attrType {
+		case wpsAttrVersion:
+			if length >= 1 {
+				wps.Version = int(value[0])
+			}
+		case wpsAttrState:
+			if length >= 1 {
+				wps.Configured = value[0] == wpsStateConfigured
+			}
+		case wpsAttrAPSetupLocked:
+			if length >= 1 {
+				wps.APSetupLocked = value[0] != 0
+			}
+		case wpsAttrManufacturer:
+			wps.Manufacturer = string(value)
+		case wpsAttrModelName:
+			wps.ModelName = string(value)
+		case wpsAttrModelNumber:
+			wps.ModelNumber = string(value)
+		case wpsAttrSerialNumber:
+			wps.SerialNumber = string(value)
+		case wpsAttrDeviceName:
+			wps.DeviceName = string(value)
+		}
+	}
+
+	return wps
+}
@@ -0,0 +1,167 @@
+// Package regdb is an embedded regulatory database: which channels each country
+// allows, with their DFS, power and indoor-only restrictions.
+package regdb
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+// World is the country code of the conservative domain used when no country is known
+const World = "00"
+
+// ErrUnknownCountry is returned (wrapped) for a country code missing from the database
+var ErrUnknownCountry = errors.New("country not in the regulatory database")
+
+// domainData is the embedded database
+//
+//go:embed domains.json
+var domainData []byte
+
+// Rule permits transmission within a frequency range
+type Rule struct {
+	Low        int    `json:"low"`      // Lower edge in MHz
+	High       int    `json:"high"`     // Upper edge in MHz
+	MaxEIRP    int    `json:"max_eirp"` // dBm
+	DFS        bool   `json:"dfs,omitempty"`
+	IndoorOnly bool   `json:"indoor_only,omitempty"`
+	Note       string `json:"note,omitempty"`
+}
+
+// Domain is one country's regulatory rules
+type Domain struct {
+	Country string `json:"country"` // ISO 3166 alpha-2 code, or "00" for the world domain
+	Name    string `json:"name"`
+	Rules   []Rule `json:"rules"`
+}
+
+// domains is the parsed database keyed by country code
+var domains = loadDomains()
+
+// loadDomains parses the embedded database; a malformed file is a build defect
+func loadDomains() map[string]*Domain {
+	var list []*Domain
+	if err := json.Unmarshal(domainData, &list); err != nil {
+		panic(fmt.Sprintf("regdb: invalid domains.json: %v", err))
+	}
+
+	byCountry := make(map[string]*Domain, len(list))
+	for _, domain := range list {
+		sort.Slice(domain.Rules, func(i, j int) bool { return domain.Rules[i].Low < domain.Rules[j].Low })
+		byCountry[domain.Country] = domain
+	}
+	return byCountry
+}
+
+// Lookup finds a country's domain by its two-letter code; "UK" is accepted for GB
+func Lookup(country string) (*Domain, error) {
+	code := strings.ToUpper(strings.TrimSpace(country))
+	if code == "UK" {
+		code = "GB"
+	}
+	domain, ok := domains[code]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCountry, country)
+	}
+	return domain, nil
+}
+
+// WorldDomain returns the conservative domain allowed in every country
+func WorldDomain() *Domain {
+	return domains[World]
+}
+
+// Countries lists the country codes in the database, sorted
+func Countries() []string {
+	var codes []string
+	for code := range domains {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// String formats the domain as "DE (Germany)"
+func (d *Domain) String() string {
+	return d.Country + " (" + d.Name + ")"
+}
+
+// Permit reports whether a block of spectrum may be used, returning the combined
+// rule: the lowest power limit and every restriction of the rules it spans.
+// The block must be covered by contiguous rules with no gap.
+func (d *Domain) Permit(block spectrum.Block) (Rule, bool) {
+	low, high := block.Low(), block.High()
+	combined := Rule{Low: low, High: high}
+	covered := low
+
+	for _, rule := range d.Rules {
+		if rule.High <= low || rule.Low >= high {
+			continue
+		}
+		if rule.Low > covered {
+			return Rule{}, false
+		}
+		if rule.High > covered {
+			covered = rule.High
+		}
+		if combined.MaxEIRP == 0 || rule.MaxEIRP < combined.MaxEIRP {
+			combined.MaxEIRP = rule.MaxEIRP
+		}
+		combined.DFS = combined.DFS || rule.DFS
+		combined.IndoorOnly = combined.IndoorOnly || rule.IndoorOnly
+		if rule.Note != "" {
+			combined.Note = rule.Note
+		}
+	}
+
+	if covered < high {
+		return Rule{}, false
+	}
+	return combined, true
+}
+
+// Allows reports whether a 20MHz channel may be used and under which rule
+func (d *Domain) Allows(c spectrum.Channel) (Rule, bool) {
+	return d.Permit(spectrum.Block{Center: c.Frequency(), Width: spectrum.Width20})
+}
+
+// Channels lists the channels of a band the domain allows, in frequency order
+func (d *Domain) Channels(band spectrum.Band) []spectrum.Channel {
+	var allowed []spectrum.Channel
+	for _, c := range spectrum.Channels(band) {
+		if _, ok := d.Allows(c); ok {
+			allowed = append(allowed, c)
+		}
+	}
+	return allowed
+}
+
+// ChannelNumbers lists the channel numbers of a band the domain allows
+func (d *Domain) ChannelNumbers(band spectrum.Band) []int {
+	var numbers []int
+	for _, c := range d.Channels(band) {
+		numbers = append(numbers, c.Number)
+	}
+	return numbers
+}
+
+// String summarises the rule's restrictions, e.g. "max 20 dBm, DFS, indoor only"
+func (r Rule) String() string {
+	parts := []string{fmt.Sprintf("max %d dBm", r.MaxEIRP)}
+	if r.DFS {
+		parts = append(parts, "DFS")
+	}
+	if r.IndoorOnly {
+		parts = append(parts, "indoor only")
+	}
+	if r.Note != "" {
+		parts = append(parts, r.Note)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,167 @@
+package regdb
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		country string
+		want    string // Empty for an unknown country
+	}{
+		{"US", "US"},
+		{"de", "DE"},
+		{" us ", "US"},
+		{"UK", "GB"},
+		{"00", World},
+		{"XX", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		domain, err := Lookup(test.country)
+		if test.want == "" {
+			if !errors.Is(err, ErrUnknownCountry) {
+				t.Errorf("Lookup(%q) = %v, %v; want ErrUnknownCountry", test.country, domain, err)
+			}
+			continue
+		}
+		if err != nil || domain.Country != test.want {
+			t.Errorf("Lookup(%q) = %v, %v; want %s", test.country, domain, err, test.want)
+		}
+	}
+
+	if WorldDomain().Country != World {
+		t.Errorf("WorldDomain() = %v", WorldDomain())
+	}
+}
+
+func TestCountries(t *testing.T) {
+	countries := Countries()
+	if !slices.IsSorted(countries) {
+		t.Errorf("Countries() not sorted: %v", countries)
+	}
+	for _, code := range []string{World, "US", "DE", "GB"} {
+		if !slices.Contains(countries, code) {
+			t.Errorf("Countries() = %v, missing %s", countries, code)
+		}
+	}
+	for _, code := range countries {
+		if _, err := Lookup(code); err != nil {
+			t.Errorf("Lookup(%q) of a listed country: %v", code, err)
+		}
+	}
+}
+
+func TestAllows(t *testing.T) {
+	type want struct {
+		allowed    bool
+		maxEIRP    int
+		dfs        bool
+		indoorOnly bool
+	}
+	tests := []struct {
+		band   spectrum.Band
+		number int
+		us     want
+		de     want
+		world  want
+	}{
+		{spectrum.Band2GHz, 1, want{true, 30, false, false}, want{true, 20, false, false}, want{true, 20, false, false}},
+		{spectrum.Band2GHz, 11, want{true, 30, false, false}, want{true, 20, false, false}, want{true, 20, false, false}},
+		{spectrum.Band2GHz, 13, want{}, want{true, 20, false, false}, want{}},
+		{spectrum.Band2GHz, 14, want{}, want{}, want{}},
+		{spectrum.Band5GHz, 36, want{true, 30, false, false}, want{true, 23, false, true}, want{true, 20, false, true}},
+		{spectrum.Band5GHz, 52, want{true, 24, true, false}, want{true, 20, true, true}, want{true, 20, true, true}},
+		{spectrum.Band5GHz, 100, want{true, 24, true, false}, want{true, 27, true, false}, want{true, 20, true, false}},
+		// Straddles DE's 5725 MHz boundary: the lower limit of the two rules applies
+		{spectrum.Band5GHz, 144, want{true, 24, true, false}, want{true, 14, true, false}, want{true, 20, true, false}},
+		{spectrum.Band5GHz, 165, want{true, 30, false, false}, want{true, 14, false, false}, want{true, 20, false, false}},
+		{spectrum.Band5GHz, 173, want{true, 27, false, true}, want{true, 14, false, false}, want{}},
+		{spectrum.Band5GHz, 177, want{true, 27, false, true}, want{}, want{}},
+		{spectrum.Band6GHz, 2, want{true, 24, false, true}, want{}, want{}},
+		{spectrum.Band6GHz, 37, want{true, 24, false, true}, want{true, 23, false, true}, want{}},
+		{spectrum.Band6GHz, 101, want{true, 24, false, true}, want{}, want{}},
+	}
+
+	domains := []*Domain{mustLookup(t, "US"), mustLookup(t, "DE"), WorldDomain()}
+	for _, test := range tests {
+		c := spectrum.Channel{Band: test.band, Number: test.number}
+		for i, expected := range []want{test.us, test.de, test.world} {
+			rule, ok := domains[i].Allows(c)
+			got := want{ok, rule.MaxEIRP, rule.DFS, rule.IndoorOnly}
+			if got != expected {
+				t.Errorf("%s allows %s = %+v, want %+v", domains[i].Country, c, got, expected)
+			}
+		}
+	}
+}
+
+func TestPermitBondedBlocks(t *testing.T) {
+	de := mustLookup(t, "DE")
+	tests := []struct {
+		name    string
+		block   spectrum.Block
+		allowed bool
+		maxEIRP int
+	}{
+		{"within one rule", spectrum.Block{Center: 5210, Width: spectrum.Width80}, true, 23},
+		{"across contiguous rules", spectrum.Block{Center: 5250, Width: spectrum.Width160}, true, 20},
+		{"across a gap", spectrum.Block{Center: 5410, Width: spectrum.Width80}, false, 0},
+		{"past the last rule", spectrum.Block{Center: 6425, Width: spectrum.Width40}, false, 0},
+	}
+
+	for _, test := range tests {
+		rule, ok := de.Permit(test.block)
+		if ok != test.allowed || rule.MaxEIRP != test.maxEIRP {
+			t.Errorf("%s: Permit(%d-%d) = %+v, %v; want %v at %d dBm",
+				test.name, test.block.Low(), test.block.High(), rule, ok, test.allowed, test.maxEIRP)
+		}
+	}
+
+	// Restrictions of every rule spanned are kept
+	rule, _ := de.Permit(spectrum.Block{Center: 5250, Width: spectrum.Width160})
+	if !rule.DFS || !rule.IndoorOnly || rule.String() != "max 20 dBm, DFS, indoor only" {
+		t.Errorf("combined rule %q", rule)
+	}
+}
+
+func TestChannelNumbers(t *testing.T) {
+	tests := []struct {
+		country string
+		band    spectrum.Band
+		want    []int
+	}{
+		{"US", spectrum.Band2GHz, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}},
+		{"DE", spectrum.Band2GHz, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}},
+		{"DE", spectrum.Band5GHz, []int{36, 40, 44, 48, 52, 56, 60, 64, 100, 104, 108, 112, 116, 120, 124, 128, 132, 136, 140, 144,
+			149, 153, 157, 161, 165, 169, 173}},
+		{World, spectrum.Band5GHz, []int{36, 40, 44, 48, 52, 56, 60, 64, 100, 104, 108, 112, 116, 120, 124, 128, 132, 136, 140, 144,
+			149, 153, 157, 161, 165}},
+		{World, spectrum.Band6GHz, nil},
+	}
+
+	for _, test := range tests {
+		if got := mustLookup(t, test.country).ChannelNumbers(test.band); !slices.Equal(got, test.want) {
+			t.Errorf("%s %s channels = %v, want %v", test.country, test.band, got, test.want)
+		}
+	}
+
+	// Every US 6GHz channel is allowed, indoors
+	if got, all := mustLookup(t, "US").ChannelNumbers(spectrum.Band6GHz), spectrum.ChannelNumbers(spectrum.Band6GHz); !slices.Equal(got, all) {
+		t.Errorf("US 6G channels = %v, want %v", got, all)
+	}
+}
+
+func mustLookup(t *testing.T, country string) *Domain {
+	t.Helper()
+	domain, err := Lookup(country)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return domain
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"github.com/svgreg/wifi-bander/internal/ie"
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+// 802.11 capability information bits
+const (
+	capabilityIBSS    = 0x0002
+	capabilityPrivacy = 0x0010
+)
+
+// applyInformationElements fills SSID, security, PHY mode, channel width and BSS Load
+// from a raw IE blob. Frequency and Channel must already be set; capability is the
+// beacon capability field.
+func applyInformationElements(network *WiFiNetwork, ies []byte, capability uint16) {
+	// A truncated blob still yields every element before the damage
+	elements, _ := ie.Decode(ies)
+	network.IEs = ies
+
+	if elements.SSID != "" {
+		network.SSID = elements.SSID
+	}
+	if network.Channel == 0 {
+		network.Channel = elements.DSChannel
+	}
+	if capability&capabilityIBSS != 0 {
+		network.NetworkType = "Ad-hoc"
+	}
+
+	network.Security = elements.Security(capability&capabilityPrivacy != 0)
+	network.PHYMode = elements.PHYMode(network.Frequency)
+
+	applyOperation(network, elements.Operation(network.Channel))
+
+	if elements.BSSLoad != nil {
+		network.StationCount = elements.BSSLoad.StationCount
+		network.ChannelUtilization = elements.BSSLoad.UtilizationPercent()
+		network.BSSLoad = true
+	}
+}
+
+// applyOperation sets the channel width, block center and HT secondary offset from
+// the operating channel the elements advertise. Frequency must already be set.
+func applyOperation(network *WiFiNetwork, op ie.Operation) {
+	network.SecondaryOffset = op.SecondaryOffset
+	network.ChannelWidth = operationWidthLabel(op)
+	network.CenterFrequency = network.Frequency
+	if op.Width > 20 {
+		network.CenterFrequency = operationCenterFrequency(op, network.Frequency)
+	}
+}
+
+// operationWidthLabel formats an operating width the way the other backends report it
+func operationWidthLabel(op ie.Operation) string {
+	switch {
+	case op.Split:
+		return "80+80MHz"
+	case op.Width == 40:
+		return "40MHz"
+	case op.Width == 80:
+		return "80MHz"
+	case op.Width == 160:
+		return "160MHz"
+	}
+	return "20MHz"
+}
+
+// operationCenterFrequency converts the advertised center channel into MHz
+func operationCenterFrequency(op ie.Operation, primaryFrequency int) int {
+	band := spectrum.Band5GHz
+	switch {
+	case op.SixGHz:
+		band = spectrum.Band6GHz
+	case primaryFrequency < 5000:
+		band = spectrum.Band2GHz
+	}
+	return spectrum.CenterChannelFrequency(band, op.CenterChannel)
+}
@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Sentinel errors returned (wrapped) by scanners so callers can match them with errors.Is
+var (
+	ErrToolMissing      = errors.New("required tool or service is not available")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrNoInterface      = errors.New("no wireless interface found")
+	ErrRadioDisabled    = errors.New("wireless radio is disabled")
+	ErrDeviceBusy       = errors.New("wireless device is busy")
+)
+
+// commandTimeout bounds each external tool invocation; iwlist and iw scans can take several seconds
+const commandTimeout = 30 * time.Second
+
+// commandErrorPatterns map tool stderr fragments to sentinel errors, checked in order.
+// They match the untranslated messages runCommand gets by running tools in the C locale.
+var commandErrorPatterns = []struct {
+	fragment string
+	err      error
+}{
+	{"a password is required", ErrPermissionDenied},
+	{"Operation not permitted", ErrPermissionDenied},
+	{"Permission denied", ErrPermissionDenied},
+	{"Not authorized", ErrPermissionDenied},
+	{"not authorized", ErrPermissionDenied},
+	{"Device or resource busy", ErrDeviceBusy},
+	{"(-16)", ErrDeviceBusy},
+	{"Network is down", ErrRadioDisabled},
+	{"(-100)", ErrRadioDisabled},
+	{"RF-kill", ErrRadioDisabled},
+	{"rfkill", ErrRadioDisabled},
+	{"Wi-Fi is disabled", ErrRadioDisabled},
+	{"No such device", ErrNoInterface},
+	{"(-19)", ErrNoInterface},
+	{"No Wi-Fi device found", ErrNoInterface},
+	{"' not found", ErrNoInterface},
+	{"NetworkManager is not running", ErrToolMissing},
+	{"command not found", ErrToolMissing},
+}
+
+// runCommand runs an external tool with a deadline and classifies its failure
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	// Keep messages and number formats untranslated for commandErrorPatterns and the parsers
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return output, commandError(ctx, name, err, stderr.String())
+	}
+	return output, nil
+}
+
+// commandError wraps a failed command's error with the matching sentinel and its stderr
+func commandError(ctx context.Context, name string, err error, stderr string) error {
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s not found: %w", name, ErrToolMissing)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s interrupted: %w", name, ctxErr)
+	}
+
+	detail := strings.TrimSpace(stderr)
+	if i := strings.IndexByte(detail, '\n'); i >= 0 {
+		detail = detail[:i]
+	}
+	if detail == "" {
+		detail = err.Error()
+	}
+
+	for _, pattern := range commandErrorPatterns {
+		if strings.Contains(stderr, pattern.fragment) {
+			return fmt.Errorf("%s failed: %w: %s", name, pattern.err, detail)
+		}
+	}
+	return fmt.Errorf("%s failed: %s", name, detail)
+}
+
+// errnoError wraps a system call error with the matching sentinel
+func errnoError(op string, err error) error {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch errno {
+	case syscall.EPERM, syscall.EACCES:
+		return fmt.Errorf("%s: %w: %v", op, ErrPermissionDenied, err)
+	case syscall.EBUSY:
+		return fmt.Errorf("%s: %w: %v", op, ErrDeviceBusy, err)
+	case syscall.ENETDOWN, errnoRFKill:
+		return fmt.Errorf("%s: %w: %v", op, ErrRadioDisabled, err)
+	case syscall.ENODEV:
+		return fmt.Errorf("%s: %w: %v", op, ErrNoInterface, err)
+	}
+	return fmt.Errorf("%s: %v", op, err)
+}
+
+// errnoRFKill is Linux's ERFKILL, returned when a scan is attempted on a blocked radio
+const errnoRFKill = syscall.Errno(132)
+
+// sleepContext waits for d or until ctx is done, returning ctx's error in the latter case
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestCommandError(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	tests := []struct {
+		name   string
+		stderr string
+		want   error // nil when no sentinel applies
+	}{
+		{"iw", "command failed: Operation not permitted (-1)\n", ErrPermissionDenied},
+		{"iw", "command failed: Device or resource busy (-16)\n", ErrDeviceBusy},
+		{"iw", "command failed: Network is down (-100)\n", ErrRadioDisabled},
+		{"iw", "command failed: Operation not possible due to RF-kill (-132)\n", ErrRadioDisabled},
+		{"iw", "command failed: No such device (-19)\n", ErrNoInterface},
+		{"iw", "command failed: Invalid argument (-22)\n", nil},
+		{"iwlist", "wlan0     Interface doesn't support scanning : Operation not permitted\n\n", ErrPermissionDenied},
+		{"iwlist", "wlan0     Interface doesn't support scanning : Device or resource busy\n\n", ErrDeviceBusy},
+		{"iwlist", "wlan0     Interface doesn't support scanning : Network is down\n\n", ErrRadioDisabled},
+		{"iwlist", "wlan9     Interface doesn't support scanning : No such device\n\n", ErrNoInterface},
+		{"iwlist", "lo        Interface doesn't support scanning.\n\n", nil},
+		{"sudo", "sudo: a password is required\n", ErrPermissionDenied},
+		{"sudo", "sudo: iwlist: command not found\n", ErrToolMissing},
+		{"nmcli", "Error: NetworkManager is not running.\n", ErrToolMissing},
+		{"nmcli", "Error: Device 'wlan9' not found.\n", ErrNoInterface},
+		{"nmcli", "Error: No Wi-Fi device found.\n", ErrNoInterface},
+		{"nmcli", "Error: Not authorized to control networking.\n", ErrPermissionDenied},
+		{"system_profiler", "", nil},
+	}
+
+	sentinels := []error{ErrPermissionDenied, ErrDeviceBusy, ErrRadioDisabled, ErrNoInterface, ErrToolMissing}
+	for _, test := range tests {
+		err := commandError(context.Background(), test.name, exitErr, test.stderr)
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == test.want) {
+				t.Errorf("%s %q: errors.Is(%v, %v) = %v", test.name, test.stderr, err, sentinel, got)
+			}
+		}
+
+		// The first stderr line, or the exit status without one, explains the failure
+		detail := strings.TrimSpace(strings.SplitN(test.stderr, "\n", 2)[0])
+		if detail == "" {
+			detail = exitErr.Error()
+		}
+		if !strings.HasPrefix(err.Error(), test.name+" failed") || !strings.HasSuffix(err.Error(), detail) {
+			t.Errorf("%s %q: error %q", test.name, test.stderr, err)
+		}
+	}
+}
+
+func TestCommandErrorNotFoundOrInterrupted(t *testing.T) {
+	err := commandError(context.Background(), "iw", &exec.Error{Name: "iw", Err: exec.ErrNotFound}, "")
+	if !errors.Is(err, ErrToolMissing) {
+		t.Errorf("missing tool error = %v, want ErrToolMissing", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = commandError(ctx, "iw", errors.New("signal: killed"), "command failed: Device or resource busy (-16)")
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrDeviceBusy) {
+		t.Errorf("interrupted command error = %v, want context.Canceled", err)
+	}
+}
+
+func TestRunCommandCLocale(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh to inspect the environment with")
+	}
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	t.Setenv("LANG", "de_DE.UTF-8")
+
+	output, err := runCommand(context.Background(), "sh", "-c", `printf %s "$LC_ALL"`)
+	if err != nil {
+		t.Fatalf("runCommand: %v", err)
+	}
+	if string(output) != "C" {
+		t.Errorf("LC_ALL = %q, want C", output)
+	}
+
+	if _, err := runCommand(context.Background(), "sh", "-c", "echo 'command failed: Network is down (-100)' >&2; exit 1"); !errors.Is(err, ErrRadioDisabled) {
+		t.Errorf("runCommand error = %v, want ErrRadioDisabled", err)
+	}
+}
+
+func TestErrnoError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{syscall.EPERM, ErrPermissionDenied},
+		{syscall.EACCES, ErrPermissionDenied},
+		{syscall.EBUSY, ErrDeviceBusy},
+		{syscall.ENETDOWN, ErrRadioDisabled},
+		{errnoRFKill, ErrRadioDisabled},
+		{syscall.ENODEV, ErrNoInterface},
+		{fmt.Errorf("sendto: %w", syscall.EBUSY), ErrDeviceBusy},
+		{syscall.EINVAL, nil},
+	}
+
+	sentinels := []error{ErrPermissionDenied, ErrDeviceBusy, ErrRadioDisabled, ErrNoInterface}
+	for _, test := range tests {
+		err := errnoError("scan", test.err)
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == test.want) {
+				t.Errorf("errors.Is(errnoError(%v), %v) = %v", test.err, sentinel, got)
+			}
+		}
+		if !strings.HasPrefix(err.Error(), "scan: ") || !strings.HasSuffix(err.Error(), test.err.Error()) {
+			t.Errorf("errnoError(%v) = %q", test.err, err)
+		}
+	}
+}
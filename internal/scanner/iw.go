@@ -0,0 +1,383 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/ie"
+)
+
+// IwScanner implements WiFi scanning on Linux by parsing `iw dev <iface> scan dump`
+type IwScanner struct {
+	// Interface is the wireless interface to scan; the first one reported by `iw dev` is used and stored here when empty
+	Interface string
+}
+
+// Scan reads the kernel's cached BSS table through iw, triggering a fresh scan if the cache is empty
+func (s *IwScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	iface := s.Interface
+	if iface == "" {
+		var err error
+		iface, err = findWiFiInterface(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.Interface = iface
+	}
+
+	output, err := runCommand(ctx, "iw", "dev", iface, "scan", "dump")
+	if err != nil {
+		return nil, err
+	}
+
+	networks, err := s.parseIwScanOutput(string(output), time.Now())
+	if err != nil || len(networks) > 0 {
+		return networks, err
+	}
+
+	// Nothing cached yet; a fresh scan requires CAP_NET_ADMIN
+	output, err = runCommand(ctx, "iw", "dev", iface, "scan")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.parseIwScanOutput(string(output), time.Now())
+}
+
+// Source reports the backend and the interface used by the last scan
+func (s *IwScanner) Source() (string, string) {
+	return "iw", s.Interface
+}
+
+// parseIwScanOutput parses the output of `iw dev <iface> scan [dump]`
+func (s *IwScanner) parseIwScanOutput(output string, now time.Time) ([]WiFiNetwork, error) {
+	var networks []WiFiNetwork
+
+	var block []string
+	flush := func() {
+		if block == nil {
+			return
+		}
+		network, err := s.parseIwBSS(block, now)
+		block = nil
+		if err != nil {
+			return
+		}
+		networks = append(networks, network)
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "BSS ") {
+			flush()
+			block = []string{line}
+		} else if block != nil {
+			block = append(block, line)
+		}
+	}
+	flush()
+
+	// Calculate congestion scores
+	scoreCongestion(networks)
+
+	return networks, nil
+}
+
+// parseIwBSS parses a single "BSS xx:xx:xx:xx:xx:xx(on wlan0)" block
+func (s *IwScanner) parseIwBSS(lines []string, now time.Time) (WiFiNetwork, error) {
+	network := WiFiNetwork{
+		NetworkType: "Infrastructure",
+		LastSeen:    now,
+	}
+
+	// Header: "BSS 00:11:22:33:44:55(on wlan0) -- associated"
+	header := strings.TrimPrefix(lines[0], "BSS ")
+	if idx := strings.IndexAny(header, "( "); idx > 0 {
+		header = header[:idx]
+	}
+	network.BSSID = strings.ToLower(header)
+
+	var section string
+	var privacy, hasRSN, hasWPA, hasHT, hasVHT, hasHE, hasEHT bool
+	var akms []int
+	var elements ie.Elements // Operation elements rebuilt from their rendering
+	var heBlock string       // Optional part of the HE Operation element being read
+
+	for _, raw := range lines[1:] {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		// Elements are indented by one tab and their fields by more, mostly starting
+		// with "*". iw prints the first RSN/WPA field on the element line itself
+		// ("RSN:\t * Version: 1").
+		if !strings.HasPrefix(raw, "\t\t") && !strings.HasPrefix(line, "*") {
+			name, rest, _ := strings.Cut(line, ":")
+			section = strings.TrimSpace(name)
+			rest = strings.TrimSpace(rest)
+
+			switch section {
+			case "freq":
+				if freq, err := strconv.ParseFloat(rest, 64); err == nil {
+					network.Frequency = int(freq)
+				}
+			case "signal":
+				// "-45.00 dBm", or "45/100" from drivers without dBm support
+				if value, ok := strings.CutSuffix(rest, "/100"); ok {
+					if sig, err := strconv.Atoi(value); err == nil {
+						setSignal(&network, sig, SignalPercent)
+					}
+				} else if sig, err := strconv.ParseFloat(strings.TrimSuffix(rest, " dBm"), 64); err == nil {
+					setSignal(&network, int(sig), SignalDBm)
+				}
+			case "last seen":
+				if strings.HasSuffix(rest, "ms ago") {
+					if ms, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(rest, "ms ago"))); err == nil {
+						network.LastSeen = now.Add(-time.Duration(ms) * time.Millisecond)
+					}
+				}
+			case "capability":
+				privacy = strings.Contains(rest, "Privacy")
+				if strings.Contains(rest, "IBSS") {
+					network.NetworkType = "Ad-hoc"
+				}
+			case "SSID":
+				network.SSID = rest
+			case "RSN":
+				hasRSN = true
+			case "WPA":
+				hasWPA = true
+			case "HT capabilities":
+				hasHT = true
+			case "HT operation":
+				hasHT = true
+				elements.HTOperation = &ie.HTOperation{}
+			case "VHT capabilities":
+				hasVHT = true
+			case "VHT operation":
+				hasVHT = true
+				elements.VHTOperation = &ie.VHTOperation{}
+			case "HE capabilities":
+				hasHE = true
+			case "HE Operation":
+				hasHE = true
+				elements.HEOperation = &ie.HEOperation{}
+				heBlock = ""
+			case "EHT capabilities":
+				hasEHT = true
+			}
+
+			if !strings.HasPrefix(rest, "*") {
+				continue
+			}
+			line = rest
+		}
+
+		key, value, hasValue := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, "*")), ":")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch section {
+		case "BSS Load":
+			switch key {
+			case "station count":
+				if n, err := strconv.Atoi(value); err == nil {
+					network.StationCount = n
+					network.BSSLoad = true
+				}
+			case "channel utilisation":
+				// Reported as "28/255"
+				used, _, _ := strings.Cut(value, "/")
+				if n, err := strconv.Atoi(used); err == nil {
+					network.ChannelUtilization = n * 100 / 255
+				}
+			}
+		case "HT operation":
+			ht := elements.HTOperation
+			switch key {
+			case "primary channel":
+				ht.PrimaryChannel = iwNumber(value)
+			case "secondary channel offset":
+				switch value {
+				case "above":
+					ht.SecondaryOffset = 1
+				case "below":
+					ht.SecondaryOffset = -1
+				}
+			case "STA channel width":
+				ht.AnyWidth = value == "any"
+			}
+		case "VHT operation":
+			// The VHT Operation element numbers its center segments from 1
+			vht := elements.VHTOperation
+			switch key {
+			case "channel width":
+				vht.ChannelWidth = iwNumber(value)
+			case "center freq segment 1":
+				vht.CenterSegment0 = iwNumber(value)
+			case "center freq segment 2":
+				vht.CenterSegment1 = iwNumber(value)
+			}
+		case "HE Operation":
+			// Optional parts start with a heading line, e.g. "6 GHz Operation Information",
+			// and number their center segments from 0
+			if !hasValue {
+				heBlock = key
+				break
+			}
+			he := elements.HEOperation
+			switch heBlock {
+			case "VHT Operation Information":
+				if he.VHTOperation == nil {
+					he.VHTOperation = &ie.VHTOperation{}
+				}
+				switch strings.ToLower(key) {
+				case "channel width":
+					he.VHTOperation.ChannelWidth = iwNumber(value)
+				case "center freq segment 0":
+					he.VHTOperation.CenterSegment0 = iwNumber(value)
+				case "center freq segment 1":
+					he.VHTOperation.CenterSegment1 = iwNumber(value)
+				}
+			case "6 GHz Operation Information":
+				if he.SixGHz == nil {
+					he.SixGHz = &ie.SixGHzOperation{}
+				}
+				switch strings.ToLower(key) {
+				case "primary channel":
+					he.SixGHz.PrimaryChannel = iwNumber(value)
+				case "channel width":
+					he.SixGHz.ChannelWidth = iwNumber(value)
+				case "center frequency segment 0":
+					he.SixGHz.CenterSegment0 = iwNumber(value)
+				case "center frequency segment 1":
+					he.SixGHz.CenterSegment1 = iwNumber(value)
+				case "minimum rate":
+					he.SixGHz.MinimumRate = iwNumber(value)
+				}
+			}
+		case "RSN", "WPA":
+			if key == "Authentication suites" {
+				akms = append(akms, iwAKMSuites(value)...)
+			}
+		}
+	}
+
+	if network.Frequency == 0 {
+		return network, fmt.Errorf("incomplete network data")
+	}
+
+	if err := applyFrequency(&network); err != nil {
+		return network, err
+	}
+	network.Vendor = getVendorFromMAC(network.BSSID)
+	applyStationEstimate(&network)
+
+	applyOperation(&network, elements.Operation(network.Channel))
+
+	switch {
+	case hasEHT:
+		network.PHYMode = "802.11be"
+	case hasHE:
+		network.PHYMode = "802.11ax"
+	case hasVHT:
+		network.PHYMode = "802.11ac"
+	case hasHT:
+		network.PHYMode = "802.11n"
+	case network.Band == "6G":
+		network.PHYMode = "802.11ax" // 6GHz operation requires HE
+	case network.Band == "5G":
+		network.PHYMode = "802.11a"
+	default:
+		network.PHYMode = "802.11b/g"
+	}
+
+	network.Security = ie.SecurityLabel(privacy, hasRSN, hasWPA, akms)
+
+	return network, nil
+}
+
+// iwNumber parses the number a field value starts with, as in "1 (80 MHz)"
+func iwNumber(value string) int {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	n, _ := strconv.Atoi(fields[0])
+	return n
+}
+
+// iwAKMNames maps the AKM suite names iw prints to their suite types
+var iwAKMNames = map[string]int{
+	"IEEE 802.1X":             ie.AKM8021X,
+	"PSK":                     ie.AKMPSK,
+	"FT/IEEE 802.1X":          ie.AKMFT8021X,
+	"FT/PSK":                  ie.AKMFTPSK,
+	"IEEE 802.1X/SHA-256":     ie.AKM8021XSHA256,
+	"PSK/SHA-256":             ie.AKMPSKSHA256,
+	"SAE":                     ie.AKMSAE,
+	"FT/SAE":                  ie.AKMFTSAE,
+	"IEEE 802.1X/SUITE-B":     ie.AKMSuiteB,
+	"IEEE 802.1X/SUITE-B-192": ie.AKMSuiteB192,
+	"FT/IEEE 802.1X/SHA-384":  ie.AKMFT8021XSHA384,
+	"OWE":                     ie.AKMOWE,
+	"SAE-EXT-KEY":             ie.AKMSAEExtKey,
+	"FT/SAE-EXT-KEY":          ie.AKMFTSAEExtKey,
+}
+
+// iwAKMSuites converts an "Authentication suites" line such as "PSK IEEE 802.1X"
+// into AKM suite types. Names iw does not know are printed as "00-0f-ac:24".
+func iwAKMSuites(value string) []int {
+	var akms []int
+	fields := strings.Fields(value)
+	for i := 0; i < len(fields); i++ {
+		name := fields[i]
+		// "IEEE 802.1X" and its variants contain a space
+		if strings.HasSuffix(name, "IEEE") && i+1 < len(fields) {
+			i++
+			name += " " + fields[i]
+		}
+
+		if akm, ok := iwAKMNames[name]; ok {
+			akms = append(akms, akm)
+		} else if oui, suite, ok := strings.Cut(name, ":"); ok && (oui == "00-0f-ac" || oui == "00-50-f2") {
+			if akm, err := strconv.Atoi(suite); err == nil {
+				akms = append(akms, akm)
+			}
+		}
+	}
+	return akms
+}
+
+// findWiFiInterface returns the first wireless interface in name order
+func findWiFiInterface(ctx context.Context) (string, error) {
+	ifaces, err := ListWirelessInterfaces(ctx)
+	if err != nil {
+		return "", err
+	}
+	return ifaces[0], nil
+}
+
+// listIwInterfaces returns the interfaces reported by `iw dev`
+func listIwInterfaces(ctx context.Context) ([]string, error) {
+	output, err := runCommand(ctx, "iw", "dev")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find WiFi interface: %w", err)
+	}
+
+	var ifaces []string
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		if strings.Contains(line, "Interface") {
+			parts := strings.Fields(line)
+			if len(parts) >= 2 {
+				ifaces = append(ifaces, parts[1])
+			}
+		}
+	}
+
+	return ifaces, nil
+}
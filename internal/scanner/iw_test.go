@@ -0,0 +1,206 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/ie"
+)
+
+// Recorded `iw dev wlan0 scan dump` output, trimmed to the fields the parser reads
+const iwScanDump = `BSS 00:11:32:aa:bb:cc(on wlan0) -- associated
+	last seen: 120 ms ago
+	freq: 5180.0
+	signal: -52.00 dBm
+	capability: ESS Privacy SpectrumMgmt (0x0111)
+	SSID: Office
+	BSS Load:
+		 * station count: 7
+		 * channel utilisation: 128/255
+	RSN:	 * Version: 1
+		 * Group cipher: CCMP
+		 * Pairwise ciphers: CCMP
+		 * Authentication suites: PSK SAE
+		 * Capabilities: 1-PTKSA-RC 1-GTKSA-RC MFP-capable (0x0080)
+	HT operation:
+		 * primary channel: 36
+		 * secondary channel offset: above
+		 * STA channel width: any
+	VHT operation:
+		 * channel width: 1 (80 MHz)
+		 * center freq segment 1: 42
+		 * center freq segment 2: 0
+BSS 00:11:32:aa:bb:cd(on wlan0)
+	freq: 2437
+	signal: -71.00 dBm
+	capability: ESS Privacy (0x0411)
+	SSID: Corp
+	RSN:	 * Version: 1
+		 * Group cipher: GCMP-256
+		 * Pairwise ciphers: GCMP-256
+		 * Authentication suites: IEEE 802.1X/SUITE-B-192
+BSS 02:00:00:00:00:01(on wlan0)
+	freq: 2412
+	signal: 40/100
+	capability: ESS Privacy (0x0411)
+	SSID: Cafe
+	RSN:	 * Version: 1
+		 * Authentication suites: PSK OWE
+`
+
+func TestParseIwScanOutput(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	networks, err := (&IwScanner{}).parseIwScanOutput(iwScanDump, now)
+	if err != nil {
+		t.Fatalf("parseIwScanOutput: %v", err)
+	}
+	if len(networks) != 3 {
+		t.Fatalf("got %d networks, want 3", len(networks))
+	}
+
+	office := networks[0]
+	if office.SSID != "Office" || office.Channel != 36 || office.Signal != -52 || office.ChannelWidth != "80MHz" ||
+		office.CenterFrequency != 5210 || office.StationCount != 7 || office.ChannelUtilization != 50 || !office.BSSLoad {
+		t.Errorf("Office decoded as %+v", office)
+	}
+	if want := now.Add(-120 * time.Millisecond); !office.LastSeen.Equal(want) {
+		t.Errorf("LastSeen = %v, want %v", office.LastSeen, want)
+	}
+	// Without BSS Load the station count is a flagged estimate
+	if corp := networks[1]; corp.BSSLoad || corp.StationCount == 0 || corp.ChannelUtilization != 0 {
+		t.Errorf("Corp load = %d stations, %d%%, BSS Load %v; want an estimate", corp.StationCount, corp.ChannelUtilization, corp.BSSLoad)
+	}
+	if networks[2].SignalUnit != SignalPercent {
+		t.Errorf("Cafe signal unit = %v, want percent", networks[2].SignalUnit)
+	}
+
+	// Labels must match what the IE decoder reports for the same elements
+	want := []string{"WPA2/WPA3 Personal", "WPA3 Enterprise", "WPA2 Personal"}
+	for i, network := range networks {
+		if network.Security != want[i] {
+			t.Errorf("%s security = %q, want %q", network.SSID, network.Security, want[i])
+		}
+	}
+}
+
+func TestIwAKMSuites(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []int
+	}{
+		{"PSK", []int{ie.AKMPSK}},
+		{"IEEE 802.1X", []int{ie.AKM8021X}},
+		{"PSK FT/PSK PSK/SHA-256", []int{ie.AKMPSK, ie.AKMFTPSK, ie.AKMPSKSHA256}},
+		{"IEEE 802.1X FT/IEEE 802.1X IEEE 802.1X/SHA-256", []int{ie.AKM8021X, ie.AKMFT8021X, ie.AKM8021XSHA256}},
+		{"SAE FT/SAE SAE-EXT-KEY", []int{ie.AKMSAE, ie.AKMFTSAE, ie.AKMSAEExtKey}},
+		{"IEEE 802.1X/SUITE-B-192", []int{ie.AKMSuiteB192}},
+		{"OWE", []int{ie.AKMOWE}},
+		{"00-0f-ac:24 00-0f-ac:25", []int{ie.AKMSAEExtKey, ie.AKMFTSAEExtKey}},
+		{"00-11-22:2 TDLS/TPK", nil},
+		{"", nil},
+	}
+
+	for _, test := range tests {
+		if got := iwAKMSuites(test.value); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("iwAKMSuites(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+// Recorded operation elements of an HT 40 MHz, a VHT 160 MHz, a 5 GHz HE-only and
+// a 6 GHz BSS, trimmed to the fields the parser reads
+const iwOperationDump = `BSS 00:11:32:00:00:01(on wlan0)
+	freq: 2437
+	signal: -60.00 dBm
+	HT operation:
+		 * primary channel: 6
+		 * secondary channel offset: below
+		 * STA channel width: any
+BSS 00:11:32:00:00:02(on wlan0)
+	freq: 5180
+	signal: -60.00 dBm
+	HT operation:
+		 * primary channel: 36
+		 * secondary channel offset: above
+		 * STA channel width: any
+	VHT operation:
+		 * channel width: 1 (80 MHz)
+		 * center freq segment 1: 42
+		 * center freq segment 2: 50
+BSS 00:11:32:00:00:03(on wlan0)
+	freq: 5745
+	signal: -60.00 dBm
+	HE Operation:
+		 * Default PE Duration: 4
+		 * TXOP Duration RTS Threshold: 1023
+		 * VHT Operation Information Present
+		 * BSS Color: 12
+		 * VHT Operation Information
+			 * Channel width: 1
+			 * Center freq segment 0: 155
+			 * Center freq segment 1: 0
+BSS 00:11:32:00:00:04(on wlan0)
+	freq: 6135
+	signal: -60.00 dBm
+	HE capabilities:
+		 * HE MAC Capabilities (0x000801185018):
+	HE Operation:
+		 * Default PE Duration: 4
+		 * 6 GHz Operation Information Present
+		 * BSS Color: 37
+		 * 6 GHz Operation Information
+			 * Primary Channel: 33
+			 * Channel Width: 3
+			 * Duplicate Beacon: 0
+			 * Center Frequency Segment 0: 39
+			 * Center Frequency Segment 1: 47
+			 * Minimum Rate: 6
+BSS 00:11:32:00:00:05(on wlan0)
+	freq: 6015
+	signal: -60.00 dBm
+	HE Operation:
+		 * 6 GHz Operation Information
+			 * Primary Channel: 9
+			 * Channel Width: 0
+			 * Center Frequency Segment 0: 9
+			 * Center Frequency Segment 1: 0
+BSS 00:11:32:00:00:06(on wlan0)
+	freq: 5200
+	signal: -60.00 dBm
+	HT operation:
+		 * primary channel: 40
+		 * secondary channel offset: below
+		 * STA channel width: 20 MHz
+`
+
+func TestParseIwOperation(t *testing.T) {
+	networks, err := (&IwScanner{}).parseIwScanOutput(iwOperationDump, time.Now())
+	if err != nil {
+		t.Fatalf("parseIwScanOutput: %v", err)
+	}
+
+	want := []struct {
+		width     string
+		center    int
+		secondary int
+		phy       string
+	}{
+		{"40MHz", 2427, -1, "802.11n"},
+		{"160MHz", 5250, 1, "802.11ac"},
+		{"80MHz", 5775, 0, "802.11ax"},
+		{"160MHz", 6185, 0, "802.11ax"},
+		{"20MHz", 6015, 0, "802.11ax"},
+		{"20MHz", 5200, 0, "802.11n"}, // 40 MHz operation not allowed
+	}
+	if len(networks) != len(want) {
+		t.Fatalf("got %d networks, want %d", len(networks), len(want))
+	}
+	for i, network := range networks {
+		w := want[i]
+		if network.ChannelWidth != w.width || network.CenterFrequency != w.center || network.SecondaryOffset != w.secondary || network.PHYMode != w.phy {
+			t.Errorf("%s: %s centered at %d MHz, secondary %d, %s; want %s at %d, %d, %s", network.BSSID,
+				network.ChannelWidth, network.CenterFrequency, network.SecondaryOffset, network.PHYMode, w.width, w.center, w.secondary, w.phy)
+		}
+	}
+}
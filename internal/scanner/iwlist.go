@@ -0,0 +1,173 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// IwlistScanner implements WiFi scanning through the legacy wireless-tools iwlist command
+type IwlistScanner struct {
+	// Interface is the wireless interface to scan; the first one reported by `iw dev` is used and stored here when empty
+	Interface string
+}
+
+// Scan runs iwlist, through non-interactive sudo unless already root, as wireless
+// extensions require root to trigger a scan
+func (s *IwlistScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	// Find WiFi interface
+	iface := s.Interface
+	if iface == "" {
+		var err error
+		iface, err = findWiFiInterface(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.Interface = iface
+	}
+
+	var output []byte
+	var err error
+	if os.Geteuid() == 0 {
+		output, err = runCommand(ctx, "iwlist", iface, "scan")
+	} else {
+		// -n fails instead of blocking on a password prompt
+		output, err = runCommand(ctx, "sudo", "-n", "iwlist", iface, "scan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.parseIwlistOutput(string(output))
+}
+
+// Source reports the backend and the interface used by the last scan
+func (s *IwlistScanner) Source() (string, string) {
+	return "iwlist", s.Interface
+}
+
+// parseIwlistOutput parses the output from iwlist command
+func (s *IwlistScanner) parseIwlistOutput(output string) ([]WiFiNetwork, error) {
+	var networks []WiFiNetwork
+
+	// Split by Cell entries
+	cells := strings.Split(output, "Cell ")
+	for _, cell := range cells[1:] { // Skip first empty entry
+		network, err := s.parseIwlistCell(cell)
+		if err != nil {
+			continue
+		}
+
+		networks = append(networks, network)
+	}
+
+	// Calculate congestion scores
+	scoreCongestion(networks)
+
+	return networks, nil
+}
+
+// parseIwlistCell parses a single cell from iwlist output
+func (s *IwlistScanner) parseIwlistCell(cell string) (WiFiNetwork, error) {
+	var network WiFiNetwork
+	lines := strings.Split(cell, "\n")
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if strings.Contains(line, "ESSID:") {
+			ssid := strings.Split(line, "ESSID:")[1]
+			ssid = strings.Trim(ssid, "\"")
+			network.SSID = ssid
+		} else if strings.Contains(line, "Address:") {
+			parts := strings.Fields(line)
+			if len(parts) >= 5 {
+				network.BSSID = parts[4]
+				network.Vendor = getVendorFromMAC(parts[4])
+			}
+		} else if _, value, ok := strings.Cut(line, "Channel:"); ok {
+			// "Channel:36"
+			if ch, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				network.Channel = ch
+			}
+		} else if strings.Contains(line, "Signal level=") {
+			parts := strings.Split(line, "Signal level=")
+			if len(parts) > 1 {
+				signalStr := strings.Fields(parts[1])[0]
+				if level, max, ok := strings.Cut(signalStr, "/"); ok {
+					// Relative level like "60/100" from drivers without dBm support
+					sig, err := strconv.Atoi(level)
+					scale, scaleErr := strconv.Atoi(max)
+					if err == nil && scaleErr == nil && scale > 0 {
+						setSignal(&network, sig*100/scale, SignalPercent)
+					}
+				} else {
+					// Direct dBm value
+					if sig, err := strconv.Atoi(strings.TrimSuffix(signalStr, " dBm")); err == nil {
+						setSignal(&network, sig, SignalDBm)
+					}
+				}
+			}
+		} else if _, value, ok := strings.Cut(line, "Frequency:"); ok {
+			// "Frequency:5.18 GHz (Channel 36)"
+			if fields := strings.Fields(value); len(fields) > 0 {
+				if freq, err := strconv.ParseFloat(fields[0], 64); err == nil {
+					network.Frequency = int(math.Round(freq * 1000)) // Convert to MHz
+				}
+			}
+		} else if strings.Contains(line, "Encryption key:") {
+			if strings.Contains(line, "on") {
+				network.Security = "WPA/WPA2" // Default assumption for encrypted
+			} else {
+				network.Security = "Open"
+			}
+		} else if strings.Contains(line, "IE: IEEE 802.11i/WPA2") {
+			network.Security = "WPA2"
+		} else if strings.Contains(line, "IE: WPA Version 1") {
+			network.Security = "WPA"
+		} else if strings.Contains(line, "Extra:") && strings.Contains(line, "wpa_ie") {
+			network.Security = "WPA"
+		}
+	}
+
+	// Set default frequency if not parsed
+	if network.Frequency == 0 && network.Channel != 0 {
+		network.Frequency, _ = channelOnlyFrequency(network.Channel)
+	}
+
+	// Determine channel and band from the frequency
+	if err := applyFrequency(&network); err != nil {
+		return network, err
+	}
+
+	// Fill in additional properties
+	applyStationEstimate(&network)
+
+	if network.Security == "" {
+		network.Security = "Unknown"
+	}
+	if network.PHYMode == "" {
+		network.PHYMode = "Unknown"
+	}
+	if network.ChannelWidth == "" {
+		network.ChannelWidth = "Unknown"
+	}
+	if network.NetworkType == "" {
+		network.NetworkType = "Infrastructure"
+	}
+	if network.BSSID == "" {
+		network.BSSID = "Unknown"
+	}
+	if network.Vendor == "" {
+		network.Vendor = "Unknown"
+	}
+
+	if network.SSID == "" || network.Channel == 0 {
+		return network, fmt.Errorf("incomplete network data")
+	}
+
+	return network, nil
+}
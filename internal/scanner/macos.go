@@ -1,50 +1,47 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 
-	"github.com/svgreg/wifi-bander/internal/analyzer"
+	"github.com/svgreg/wifi-bander/internal/spectrum"
 )
 
-// MacOSScanner implements WiFi scanning for macOS systems
-type MacOSScanner struct{}
+// AirportScanner implements WiFi scanning with the macOS airport utility
+type AirportScanner struct{}
 
-// Scan performs WiFi network scanning on macOS
-func (m *MacOSScanner) Scan() ([]WiFiNetwork, error) {
-	// Try using the airport command if available
-	cmd := exec.Command("/usr/sbin/airport", "-s")
-	output, err := cmd.Output()
+// Scan lists nearby networks with `airport -s`
+func (a *AirportScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	output, err := runCommand(ctx, "/usr/sbin/airport", "-s")
 	if err != nil {
-		// Fallback to system_profiler
-		return m.scanWithSystemProfiler()
+		return nil, err
 	}
 
-	networks, err := m.parseAirportOutput(string(output))
-	if err != nil {
-		return m.scanWithSystemProfiler()
-	}
-
-	return networks, nil
+	return a.parseAirportOutput(string(output))
 }
 
-// scanWithSystemProfiler uses system_profiler to scan WiFi networks
-func (m *MacOSScanner) scanWithSystemProfiler() ([]WiFiNetwork, error) {
-	cmd := exec.Command("system_profiler", "SPAirPortDataType")
-	output, err := cmd.Output()
+// SystemProfilerScanner implements WiFi scanning with macOS system_profiler
+type SystemProfilerScanner struct{}
+
+// Scan reads the "Other Local Wi-Fi Networks" section of system_profiler
+func (p *SystemProfilerScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	output, err := runCommand(ctx, "system_profiler", "SPAirPortDataType")
 	if err != nil {
-		return nil, fmt.Errorf("system_profiler command failed: %v", err)
+		return nil, err
 	}
 
-	return m.parseSystemProfilerOutput(string(output))
+	networks, err := p.parseSystemProfilerOutput(string(output))
+	if err == nil && len(networks) == 0 && strings.Contains(string(output), "Status: Off") {
+		return nil, fmt.Errorf("system_profiler: %w", ErrRadioDisabled)
+	}
+	return networks, err
 }
 
 // parseAirportOutput parses the output from the airport command
-func (m *MacOSScanner) parseAirportOutput(output string) ([]WiFiNetwork, error) {
+func (a *AirportScanner) parseAirportOutput(output string) ([]WiFiNetwork, error) {
 	var networks []WiFiNetwork
-	channelMap := make(map[int]*ChannelInfo)
 
 	lines := strings.Split(output, "\n")
 
@@ -101,23 +98,22 @@ func (m *MacOSScanner) parseAirportOutput(output string) ([]WiFiNetwork, error)
 			continue
 		}
 
-		network := m.createWiFiNetwork(ssid, channel, signal)
+		network, err := a.createWiFiNetwork(ssid, channel, signal)
+		if err != nil {
+			continue
+		}
 		networks = append(networks, network)
-		updateChannelMap(channelMap, channel, signal)
 	}
 
 	// Calculate congestion scores
-	for i := range networks {
-		networks[i].CongestionScore = analyzer.CalculateCongestionScore(networks[i], channelMap)
-	}
+	scoreCongestion(networks)
 
 	return networks, nil
 }
 
 // parseSystemProfilerOutput parses the output from system_profiler
-func (m *MacOSScanner) parseSystemProfilerOutput(output string) ([]WiFiNetwork, error) {
+func (p *SystemProfilerScanner) parseSystemProfilerOutput(output string) ([]WiFiNetwork, error) {
 	var networks []WiFiNetwork
-	channelMap := make(map[int]*ChannelInfo)
 
 	lines := strings.Split(output, "\n")
 	var currentNetwork *WiFiNetwork
@@ -144,32 +140,28 @@ func (m *MacOSScanner) parseSystemProfilerOutput(output string) ([]WiFiNetwork,
 			// Save previous network
 			if currentNetwork != nil && currentNetwork.SSID != "" && currentNetwork.Channel != 0 {
 				networks = append(networks, *currentNetwork)
-				updateChannelMap(channelMap, currentNetwork.Channel, currentNetwork.Signal)
 			}
 
 			ssid := strings.TrimSuffix(line, ":")
 			currentNetwork = &WiFiNetwork{SSID: ssid}
 		} else if currentNetwork != nil && strings.Contains(line, ":") {
-			m.parseNetworkProperty(currentNetwork, line)
+			p.parseNetworkProperty(currentNetwork, line)
 		}
 	}
 
 	// Add the last network
 	if currentNetwork != nil && currentNetwork.SSID != "" && currentNetwork.Channel != 0 {
 		networks = append(networks, *currentNetwork)
-		updateChannelMap(channelMap, currentNetwork.Channel, currentNetwork.Signal)
 	}
 
 	// Calculate congestion scores
-	for i := range networks {
-		networks[i].CongestionScore = analyzer.CalculateCongestionScore(networks[i], channelMap)
-	}
+	scoreCongestion(networks)
 
 	return networks, nil
 }
 
 // parseNetworkProperty parses a property line for the current network
-func (m *MacOSScanner) parseNetworkProperty(network *WiFiNetwork, line string) {
+func (p *SystemProfilerScanner) parseNetworkProperty(network *WiFiNetwork, line string) {
 	parts := strings.SplitN(line, ":", 2)
 	if len(parts) != 2 {
 		return
@@ -180,16 +172,28 @@ func (m *MacOSScanner) parseNetworkProperty(network *WiFiNetwork, line string) {
 
 	switch key {
 	case "Channel":
-		// Parse channel like "36 (5GHz, 80MHz)" or "6 (2GHz, 20MHz)"
+		// Parse channel like "36 (5GHz, 80MHz)", "6 (2GHz, 20MHz)" or "37 (6GHz, 160MHz)"
 		channelParts := strings.Fields(value)
 		if len(channelParts) > 0 {
 			if ch, err := strconv.Atoi(channelParts[0]); err == nil {
-				network.Channel = ch
-				network.Frequency = channelToFrequency(ch)
-				if network.Frequency > 5000 {
-					network.Band = "5G"
-				} else {
-					network.Band = "2.4G"
+				var channel spectrum.Channel
+				switch {
+				case strings.Contains(value, "6GHz"):
+					channel, err = spectrum.NewChannel(spectrum.Band6GHz, ch)
+				case strings.Contains(value, "5GHz"):
+					channel, err = spectrum.NewChannel(spectrum.Band5GHz, ch)
+				case strings.Contains(value, "2GHz"):
+					channel, err = spectrum.NewChannel(spectrum.Band2GHz, ch)
+				default:
+					var frequency int
+					if frequency, err = channelOnlyFrequency(ch); err == nil {
+						channel, err = spectrum.ChannelFromFrequency(frequency)
+					}
+				}
+				if err == nil {
+					network.Channel = channel.Number
+					network.Band = string(channel.Band)
+					network.Frequency = channel.Frequency()
 				}
 			}
 		}
@@ -212,8 +216,8 @@ func (m *MacOSScanner) parseNetworkProperty(network *WiFiNetwork, line string) {
 			// Signal strength
 			signalStr := strings.TrimSuffix(signalParts[0], " dBm")
 			if sig, err := strconv.Atoi(signalStr); err == nil {
-				network.Signal = sig
-				network.StationCount = estimateStationCount(sig, network.Channel)
+				setSignal(network, sig, SignalDBm)
+				applyStationEstimate(network)
 			}
 
 			// Noise level
@@ -225,8 +229,6 @@ func (m *MacOSScanner) parseNetworkProperty(network *WiFiNetwork, line string) {
 					if network.Signal != 0 && network.Noise != 0 {
 						network.SNR = network.Signal - network.Noise
 					}
-					// Calculate quality percentage (rough approximation)
-					network.Quality = calculateQuality(network.Signal)
 				}
 			}
 		}
@@ -251,21 +253,16 @@ func (m *MacOSScanner) parseNetworkProperty(network *WiFiNetwork, line string) {
 }
 
 // createWiFiNetwork creates a WiFiNetwork struct from basic parameters
-func (m *MacOSScanner) createWiFiNetwork(ssid string, channel, signal int) WiFiNetwork {
-	frequency := channelToFrequency(channel)
-	band := "2.4G"
-	if frequency > 5000 {
-		band = "5G"
+func (a *AirportScanner) createWiFiNetwork(ssid string, channel, signal int) (WiFiNetwork, error) {
+	frequency, err := channelOnlyFrequency(channel)
+	if err != nil {
+		return WiFiNetwork{}, err
 	}
 
-	return WiFiNetwork{
+	network := WiFiNetwork{
 		SSID:         ssid,
-		Channel:      channel,
 		Signal:       signal,
-		Band:         band,
 		Frequency:    frequency,
-		StationCount: estimateStationCount(signal, channel),
-		Quality:      calculateQuality(signal),
 		Security:     "Unknown",
 		PHYMode:      "Unknown",
 		ChannelWidth: "Unknown",
@@ -275,4 +272,7 @@ func (m *MacOSScanner) createWiFiNetwork(ssid string, channel, signal int) WiFiN
 		Noise:        0,
 		SNR:          0,
 	}
+	setSignal(&network, signal, SignalDBm)
+	applyStationEstimate(&network)
+	return network, applyFrequency(&network)
 }
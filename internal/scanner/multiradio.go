@@ -0,0 +1,275 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// sysClassNet is where Linux lists network interfaces; wireless ones link to their phy80211
+var sysClassNet = "/sys/class/net"
+
+// ListWirelessInterfaces returns every wireless interface sorted by name.
+// sysfs is read first so no external tool is needed; `iw dev` is the fallback.
+func ListWirelessInterfaces(ctx context.Context) ([]string, error) {
+	var ifaces []string
+
+	matches, _ := filepath.Glob(filepath.Join(sysClassNet, "*", "phy80211"))
+	for _, match := range matches {
+		ifaces = append(ifaces, filepath.Base(filepath.Dir(match)))
+	}
+
+	if len(ifaces) == 0 {
+		var err error
+		ifaces, err = listIwInterfaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(ifaces) == 0 {
+		return nil, ErrNoInterface
+	}
+
+	sort.Strings(ifaces)
+	return ifaces, nil
+}
+
+// MultiRadioScanner scans every wireless interface concurrently and merges the results by BSSID
+type MultiRadioScanner struct {
+	// Backends are tried in order on each interface; the platform defaults are used when empty
+	Backends []string
+	// Interfaces limits scanning to these interfaces; every wireless interface is used when empty
+	Interfaces []string
+	// OnFailure, when set, is called for each backend or radio that failed while another radio succeeded
+	OnFailure func(err *BackendError)
+	// Calibration offsets each radio's signal before the results are merged
+	Calibration Calibration
+
+	chains map[string]*ChainScanner // Per-interface chains, kept so backend state survives between scans
+	ifaces []string                 // Interfaces that contributed to the last scan
+}
+
+// radioScan is the outcome of scanning one interface
+type radioScan struct {
+	iface    string
+	backend  string
+	networks []WiFiNetwork
+	failures []*BackendError
+	err      error
+}
+
+// Scan runs each interface's backend chain in parallel. When no interface can be
+// discovered the chain runs once without one, letting each backend pick its own.
+func (m *MultiRadioScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	ifaces := m.Interfaces
+	if len(ifaces) == 0 {
+		var err error
+		ifaces, err = ListWirelessInterfaces(ctx)
+		if errors.Is(err, ErrNoInterface) || errors.Is(err, ErrToolMissing) {
+			ifaces = []string{""}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	scans := make([]radioScan, len(ifaces))
+	var wg sync.WaitGroup
+	for i, iface := range ifaces {
+		chain, err := m.chain(iface)
+		if err != nil {
+			return nil, err
+		}
+
+		wg.Add(1)
+		go func(scan *radioScan, iface string, chain *ChainScanner) {
+			defer wg.Done()
+
+			// Collect failures here and report them after the wait, so OnFailure is never called concurrently
+			chain.OnFailure = func(err *BackendError) {
+				scan.failures = append(scan.failures, err)
+			}
+			scan.iface = iface
+			scan.networks, scan.err = chain.Scan(ctx)
+			scan.backend, _ = chain.Source()
+		}(&scans[i], iface, chain)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var succeeded []radioScan
+	var failed []error
+	for _, scan := range scans {
+		if scan.err != nil {
+			if scan.iface != "" {
+				failed = append(failed, &BackendError{Backend: "multi", Arg: scan.iface, Err: scan.err})
+			} else {
+				failed = append(failed, scan.err)
+			}
+			continue
+		}
+		succeeded = append(succeeded, scan)
+	}
+
+	if len(succeeded) == 0 {
+		if len(failed) == 1 {
+			return nil, failed[0]
+		}
+		return nil, fmt.Errorf("every radio failed:\n%w", errors.Join(failed...))
+	}
+
+	if m.OnFailure != nil {
+		for _, scan := range scans {
+			for _, failure := range scan.failures {
+				m.OnFailure(failure)
+			}
+		}
+		for _, err := range failed {
+			var backendErr *BackendError
+			if errors.As(err, &backendErr) {
+				m.OnFailure(backendErr)
+			}
+		}
+	}
+
+	m.ifaces = m.ifaces[:0]
+	for _, scan := range succeeded {
+		m.ifaces = append(m.ifaces, scan.iface)
+	}
+
+	return mergeRadioScans(succeeded), nil
+}
+
+// chain returns the cached backend chain for an interface, creating it on first use
+func (m *MultiRadioScanner) chain(iface string) (*ChainScanner, error) {
+	if chain, ok := m.chains[iface]; ok {
+		return chain, nil
+	}
+
+	backends := m.Backends
+	if len(backends) == 0 {
+		backends = DefaultBackendSpecs()
+	}
+
+	specs := make([]string, len(backends))
+	for i, backend := range backends {
+		specs[i] = backend
+		if iface != "" {
+			specs[i] = backend + ":" + iface
+		}
+	}
+
+	chain, err := NewChainScanner(specs)
+	if err != nil {
+		return nil, err
+	}
+	chain.Calibration = m.Calibration
+
+	if m.chains == nil {
+		m.chains = make(map[string]*ChainScanner)
+	}
+	m.chains[iface] = chain
+	return chain, nil
+}
+
+// Source reports "multi" and the interfaces that contributed to the last scan
+func (m *MultiRadioScanner) Source() (string, string) {
+	return "multi", strings.Join(m.ifaces, ",")
+}
+
+// mergeRadioScans combines per-radio results into one network per BSSID. The strongest
+// observation supplies the network's fields, gaps are filled from the other radios, and
+// every radio's view is kept in Radios. A single radio's results are returned unchanged.
+func mergeRadioScans(scans []radioScan) []WiFiNetwork {
+	if len(scans) == 1 {
+		return scans[0].networks
+	}
+
+	var merged []WiFiNetwork
+	byBSSID := make(map[string]int)
+
+	for _, scan := range scans {
+		for _, network := range scan.networks {
+			observation := RadioObservation{
+				Interface: scan.iface,
+				Backend:   scan.backend,
+				Signal:    network.Signal,
+				Noise:     network.Noise,
+				LastSeen:  network.LastSeen,
+			}
+
+			key := strings.ToLower(network.BSSID)
+			i, seen := byBSSID[key]
+			if !seen || key == "" || key == "unknown" {
+				network.Radios = []RadioObservation{observation}
+				byBSSID[key] = len(merged)
+				merged = append(merged, network)
+				continue
+			}
+
+			existing := merged[i]
+			radios := append(existing.Radios, observation)
+			if network.Signal > existing.Signal {
+				fillMissingFields(&network, existing)
+				merged[i] = network
+			} else {
+				fillMissingFields(&merged[i], network)
+			}
+			merged[i].Radios = radios
+		}
+	}
+
+	for i := range merged {
+		sort.Slice(merged[i].Radios, func(a, b int) bool {
+			return merged[i].Radios[a].Interface < merged[i].Radios[b].Interface
+		})
+	}
+
+	// Calculate congestion scores over the merged view
+	scoreCongestion(merged)
+
+	return merged
+}
+
+// fillMissingFields copies details one radio's backend could not provide from another radio's observation
+func fillMissingFields(dst *WiFiNetwork, src WiFiNetwork) {
+	if dst.SSID == "" {
+		dst.SSID = src.SSID
+	}
+	if dst.Noise == 0 && src.Noise != 0 {
+		dst.Noise = src.Noise
+		dst.SNR = dst.Signal - src.Noise
+	}
+	if dst.Security == "Unknown" || dst.Security == "" {
+		dst.Security = src.Security
+	}
+	if dst.PHYMode == "Unknown" || dst.PHYMode == "" {
+		dst.PHYMode = src.PHYMode
+	}
+	if dst.ChannelWidth == "Unknown" || dst.ChannelWidth == "" {
+		dst.ChannelWidth = src.ChannelWidth
+		dst.CenterFrequency = src.CenterFrequency
+		dst.SecondaryOffset = src.SecondaryOffset
+	}
+	if !dst.BSSLoad && src.BSSLoad {
+		dst.BSSLoad = true
+		dst.StationCount = src.StationCount
+		dst.ChannelUtilization = src.ChannelUtilization
+	}
+	if dst.IEs == nil {
+		dst.IEs = src.IEs
+	}
+	if dst.MaxRate == 0 {
+		dst.MaxRate = src.MaxRate
+	}
+	if src.LastSeen.After(dst.LastSeen) {
+		dst.LastSeen = src.LastSeen
+	}
+	dst.Connected = dst.Connected || src.Connected
+}
@@ -0,0 +1,162 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// radioScanner is a live backend bound to one interface
+type radioScanner struct {
+	iface string
+	scan  func() ([]WiFiNetwork, error)
+}
+
+func (r *radioScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	if r.scan == nil {
+		return nil, ErrNoInterface
+	}
+	return r.scan()
+}
+
+func (r *radioScanner) Source() (string, string) {
+	return "radio", r.iface
+}
+
+// registerRadios registers a "radio" backend whose scanner for each interface
+// returns that interface's scan, until the test ends
+func registerRadios(t *testing.T, radios map[string]func() ([]WiFiNetwork, error)) {
+	t.Helper()
+	registerTestBackend(t, Backend{Name: "radio", New: func(arg string) (Scanner, error) {
+		return &radioScanner{iface: arg, scan: radios[arg]}, nil
+	}})
+}
+
+// registerTestBackend adds a backend to the registry until the test ends
+func registerTestBackend(t *testing.T, backend Backend) {
+	t.Helper()
+	builtin := registry
+	t.Cleanup(func() { registry = builtin })
+	if err := RegisterBackend(backend); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// radioNetworks is what two radios see: both hear Office, each hears one network the other does not
+func radioNetworks() (wlan0, wlan1 []WiFiNetwork) {
+	wlan0 = []WiFiNetwork{
+		{SSID: "Office", BSSID: "00:11:32:AA:BB:CC", Band: "5G", Channel: 36, Frequency: 5180, Signal: -60, Noise: -95,
+			ChannelWidth: "Unknown", Security: "WPA2 Personal", StationCount: 3},
+		{SSID: "Cafe", BSSID: "02:00:00:00:00:01", Band: "2.4G", Channel: 6, Frequency: 2437, Signal: -70, ChannelWidth: "20MHz"},
+	}
+	wlan1 = []WiFiNetwork{
+		{SSID: "Office", BSSID: "00:11:32:aa:bb:cc", Band: "5G", Channel: 36, Frequency: 5180, Signal: -50,
+			ChannelWidth: "80MHz", CenterFrequency: 5210, Security: "Unknown", BSSLoad: true, StationCount: 12, ChannelUtilization: 40},
+		{SSID: "Corp", BSSID: "02:00:00:00:00:02", Band: "5G", Channel: 149, Frequency: 5745, Signal: -80, ChannelWidth: "20MHz"},
+	}
+	return wlan0, wlan1
+}
+
+func TestMultiRadioScannerMergesByBSSID(t *testing.T) {
+	wlan0, wlan1 := radioNetworks()
+	registerRadios(t, map[string]func() ([]WiFiNetwork, error){
+		"wlan0": func() ([]WiFiNetwork, error) { return wlan0, nil },
+		"wlan1": func() ([]WiFiNetwork, error) { return wlan1, nil },
+	})
+
+	multi := &MultiRadioScanner{Backends: []string{"radio"}, Interfaces: []string{"wlan1", "wlan0"}}
+	multi.OnFailure = func(err *BackendError) { t.Errorf("OnFailure(%v)", err) }
+	networks, err := multi.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(networks) != 3 {
+		t.Fatalf("merged %d networks, want Office once plus Cafe and Corp", len(networks))
+	}
+
+	// The stronger radio's view wins and the other fills in what it lacks
+	office := networks[0]
+	if office.Signal != -50 || office.ChannelWidth != "80MHz" || office.Security != "WPA2 Personal" ||
+		office.Noise != -95 || office.SNR != 45 || !office.BSSLoad || office.StationCount != 12 {
+		t.Errorf("Office merged as %+v", office)
+	}
+	radios := make([]string, len(office.Radios))
+	for i, radio := range office.Radios {
+		radios[i] = radio.Interface + "/" + radio.Backend + "=" + strconv.Itoa(radio.Signal)
+	}
+	if got := strings.Join(radios, " "); got != "wlan0/radio=-60 wlan1/radio=-50" {
+		t.Errorf("Office radios %s", got)
+	}
+	for _, network := range networks[1:] {
+		if len(network.Radios) != 1 {
+			t.Errorf("%s heard by %d radios, want 1", network.SSID, len(network.Radios))
+		}
+	}
+	if backend, ifaces := multi.Source(); backend != "multi" || ifaces != "wlan1,wlan0" {
+		t.Errorf("Source() = %s, %s", backend, ifaces)
+	}
+}
+
+func TestMultiRadioScannerCalibratesBeforeMerging(t *testing.T) {
+	wlan0, wlan1 := radioNetworks()
+	registerRadios(t, map[string]func() ([]WiFiNetwork, error){
+		"wlan0": func() ([]WiFiNetwork, error) { return wlan0, nil },
+		"wlan1": func() ([]WiFiNetwork, error) { return wlan1, nil },
+	})
+
+	// wlan0 reads 15 dB low, which makes it the stronger radio for Office
+	multi := &MultiRadioScanner{Backends: []string{"radio"}, Interfaces: []string{"wlan0", "wlan1"}, Calibration: Calibration{"wlan0": 15}}
+	networks, err := multi.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	office := networks[0]
+	if office.Signal != -45 || office.Noise != -80 || office.SignalOffset != 15 || office.ChannelWidth != "80MHz" {
+		t.Errorf("Office merged as %+v, want wlan0's calibrated -45 dBm", office)
+	}
+	if office.Radios[0].Signal != -45 || office.Radios[1].Signal != -50 {
+		t.Errorf("Office radios %+v", office.Radios)
+	}
+}
+
+func TestMultiRadioScannerRadioFails(t *testing.T) {
+	wlan0, _ := radioNetworks()
+	registerRadios(t, map[string]func() ([]WiFiNetwork, error){
+		"wlan0": func() ([]WiFiNetwork, error) { return wlan0, nil },
+		"wlan1": func() ([]WiFiNetwork, error) { return nil, ErrDeviceBusy },
+	})
+	registerTestBackend(t, Backend{Name: "broken", New: func(arg string) (Scanner, error) {
+		return &radioScanner{iface: arg}, nil
+	}})
+
+	var failures []string
+	multi := &MultiRadioScanner{Backends: []string{"broken", "radio"}, Interfaces: []string{"wlan0", "wlan1"}}
+	multi.OnFailure = func(err *BackendError) { failures = append(failures, err.Error()) }
+	networks, err := multi.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan with one radio working: %v", err)
+	}
+
+	// wlan0's results come back unchanged, and every failure is reported
+	if len(networks) != 2 || networks[0].Radios != nil || networks[0].Signal != -60 {
+		t.Errorf("networks %+v, want wlan0's scan", networks)
+	}
+	if _, ifaces := multi.Source(); ifaces != "wlan0" {
+		t.Errorf("Source() interfaces %q, want wlan0", ifaces)
+	}
+	want := []string{
+		"broken (wlan0): " + ErrNoInterface.Error(),
+		"multi (wlan1): all scanner backends failed:\nbroken (wlan1): " + ErrNoInterface.Error() + "\nradio (wlan1): " + ErrDeviceBusy.Error(),
+	}
+	if strings.Join(failures, "|") != strings.Join(want, "|") {
+		t.Errorf("failures %q, want %q", failures, want)
+	}
+
+	// With every radio failing the scan fails, naming each
+	multi.Interfaces = []string{"wlan1", "wlan2"}
+	if _, err := multi.Scan(context.Background()); err == nil || !errors.Is(err, ErrDeviceBusy) || !strings.Contains(err.Error(), "wlan2") {
+		t.Errorf("Scan with every radio failing = %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+//go:build linux
+
+package scanner
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// solNetlink is the SOL_NETLINK socket option level, missing from the syscall package on some architectures
+const solNetlink = 270
+
+// socketNetlinkConn is a netlinkConn backed by a real NETLINK_GENERIC socket
+type socketNetlinkConn struct {
+	fd  int
+	buf []byte
+}
+
+// dialNetlink opens and binds a generic netlink socket
+func dialNetlink() (netlinkConn, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_GENERIC)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("bind", err)
+	}
+
+	return &socketNetlinkConn{fd: fd, buf: make([]byte, os.Getpagesize())}, nil
+}
+
+// Send writes a single netlink message to the kernel
+func (c *socketNetlinkConn) Send(msg []byte) error {
+	return os.NewSyscallError("sendto", syscall.Sendto(c.fd, msg, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}))
+}
+
+// Receive reads one datagram and splits it into netlink messages. The datagram is
+// peeked first so the buffer can grow to fit it; a dump with many IEs per BSS can
+// exceed any fixed size.
+func (c *socketNetlinkConn) Receive() ([]netlinkMessage, error) {
+	// With MSG_TRUNC the full datagram length is returned even when it does not fit
+	n, _, err := syscall.Recvfrom(c.fd, c.buf, syscall.MSG_PEEK|syscall.MSG_TRUNC)
+	if err == nil {
+		if n > len(c.buf) {
+			c.buf = make([]byte, n)
+		}
+		n, _, err = syscall.Recvfrom(c.fd, c.buf, 0)
+	}
+	if err != nil {
+		if err == syscall.EAGAIN {
+			return nil, os.ErrDeadlineExceeded
+		}
+		return nil, os.NewSyscallError("recvfrom", err)
+	}
+
+	// Copy out of the shared buffer so callers can keep the messages
+	datagram := make([]byte, n)
+	copy(datagram, c.buf[:n])
+	return parseNetlinkMessages(datagram)
+}
+
+// JoinGroup subscribes the socket to a multicast group
+func (c *socketNetlinkConn) JoinGroup(group uint32) error {
+	return os.NewSyscallError("setsockopt", syscall.SetsockoptInt(c.fd, solNetlink, syscall.NETLINK_ADD_MEMBERSHIP, int(group)))
+}
+
+// SetReadDeadline bounds how long Receive blocks; a zero time disables the timeout
+func (c *socketNetlinkConn) SetReadDeadline(t time.Time) error {
+	var tv syscall.Timeval
+	if !t.IsZero() {
+		timeout := time.Until(t)
+		if timeout < time.Millisecond {
+			timeout = time.Millisecond
+		}
+		tv = syscall.NsecToTimeval(timeout.Nanoseconds())
+	}
+	return os.NewSyscallError("setsockopt", syscall.SetsockoptTimeval(c.fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv))
+}
+
+// Close releases the socket
+func (c *socketNetlinkConn) Close() error {
+	return syscall.Close(c.fd)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package scanner
+
+import "fmt"
+
+// dialNetlink reports that netlink is only available on Linux
+func dialNetlink() (netlinkConn, error) {
+	return nil, fmt.Errorf("netlink is not supported on this platform")
+}
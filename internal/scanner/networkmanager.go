@@ -0,0 +1,358 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// NetworkManager D-Bus names
+const (
+	nmService         = "org.freedesktop.NetworkManager"
+	nmPath            = "/org/freedesktop/NetworkManager"
+	nmInterface       = "org.freedesktop.NetworkManager"
+	nmDeviceInterface = "org.freedesktop.NetworkManager.Device"
+	nmWirelessIface   = "org.freedesktop.NetworkManager.Device.Wireless"
+	nmAPInterface     = "org.freedesktop.NetworkManager.AccessPoint"
+	dbusPropsIface    = "org.freedesktop.DBus.Properties"
+
+	nmDeviceTypeWiFi = 2
+)
+
+// NM80211ApSecurityFlags (see NetworkManager's nm-dbus-interface.h)
+const (
+	nmAPSecPairWEP40     = 0x1
+	nmAPSecPairWEP104    = 0x2
+	nmAPSecKeyMgmtPSK    = 0x100
+	nmAPSecKeyMgmt8021X  = 0x200
+	nmAPSecKeyMgmtSAE    = 0x400
+	nmAPSecKeyMgmtOWE    = 0x800
+	nmAPSecKeyMgmtOWETM  = 0x1000
+	nmAPSecKeyMgmtEAPB92 = 0x2000
+
+	nmAPFlagsPrivacy = 0x1
+
+	nmWiFiModeAdhoc = 1
+)
+
+// NetworkManager timeouts
+const (
+	nmScanTimeout = 15 * time.Second // Wait for a requested scan to complete
+	nmCallTimeout = 5 * time.Second  // Any single D-Bus call
+)
+
+// NetworkManagerScanner implements WiFi scanning through NetworkManager's D-Bus API
+type NetworkManagerScanner struct {
+	// Interface restricts scanning to a single device; all WiFi devices are used when empty
+	Interface string
+	// Conn is the bus to use; a private system bus connection is opened when nil
+	Conn *dbus.Conn
+}
+
+// Scan requests a scan on every WiFi device, waits for completion and reads the access points
+func (n *NetworkManagerScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	conn := n.Conn
+	if conn == nil {
+		var err error
+		conn, err = dbus.ConnectSystemBus(dbus.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to system bus: %w: %v", ErrToolMissing, err)
+		}
+		defer conn.Close()
+	}
+
+	root := conn.Object(nmService, nmPath)
+	if v, err := nmProperty(ctx, root, nmInterface, "WirelessEnabled"); err != nil {
+		return nil, nmError("NetworkManager", err)
+	} else if enabled, ok := v.Value().(bool); ok && !enabled {
+		return nil, fmt.Errorf("NetworkManager: %w", ErrRadioDisabled)
+	}
+
+	devices, err := n.wifiDevices(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("NetworkManager: %w", ErrNoInterface)
+	}
+
+	n.requestScans(ctx, conn, devices)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	var networks []WiFiNetwork
+	now := time.Now()
+	uptime, uptimeErr := bootTime()
+
+	for _, device := range devices {
+		obj := conn.Object(nmService, device)
+
+		var active dbus.ObjectPath
+		if v, err := nmProperty(ctx, obj, nmWirelessIface, "ActiveAccessPoint"); err == nil {
+			active, _ = v.Value().(dbus.ObjectPath)
+		}
+
+		var aps []dbus.ObjectPath
+		if err := nmCall(ctx, obj, nmWirelessIface+".GetAllAccessPoints").Store(&aps); err != nil {
+			return nil, nmError("NetworkManager GetAllAccessPoints", err)
+		}
+
+		for _, ap := range aps {
+			var props map[string]dbus.Variant
+			if err := nmCall(ctx, conn.Object(nmService, ap), dbusPropsIface+".GetAll", nmAPInterface).Store(&props); err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				continue
+			}
+
+			network, err := n.accessPointToNetwork(props)
+			if err != nil {
+				continue
+			}
+			network.Connected = ap == active
+
+			// LastSeen is in CLOCK_BOOTTIME seconds
+			if seen, ok := props["LastSeen"].Value().(int32); ok && seen >= 0 && uptimeErr == nil {
+				network.LastSeen = now.Add(-(uptime - time.Duration(seen)*time.Second))
+			}
+
+			networks = append(networks, network)
+		}
+	}
+
+	// Calculate congestion scores
+	scoreCongestion(networks)
+
+	return networks, nil
+}
+
+// Source reports the backend and the interface used by the last scan
+func (n *NetworkManagerScanner) Source() (string, string) {
+	return "networkmanager", n.Interface
+}
+
+// wifiDevices returns the object paths of NetworkManager's WiFi devices
+func (n *NetworkManagerScanner) wifiDevices(ctx context.Context, conn *dbus.Conn) ([]dbus.ObjectPath, error) {
+	var all []dbus.ObjectPath
+	if err := nmCall(ctx, conn.Object(nmService, nmPath), nmInterface+".GetDevices").Store(&all); err != nil {
+		return nil, nmError("NetworkManager GetDevices", err)
+	}
+
+	var devices []dbus.ObjectPath
+	for _, path := range all {
+		obj := conn.Object(nmService, path)
+
+		v, err := nmProperty(ctx, obj, nmDeviceInterface, "DeviceType")
+		if err != nil {
+			continue
+		}
+		if deviceType, _ := v.Value().(uint32); deviceType != nmDeviceTypeWiFi {
+			continue
+		}
+
+		if n.Interface != "" {
+			v, err := nmProperty(ctx, obj, nmDeviceInterface, "Interface")
+			if err != nil {
+				continue
+			}
+			if iface, _ := v.Value().(string); iface != n.Interface {
+				continue
+			}
+		}
+
+		devices = append(devices, path)
+	}
+
+	return devices, nil
+}
+
+// requestScans asks each device to scan and waits until every device's LastScan property changes.
+// Rate-limited or unauthorized scan requests are not fatal: the cached access point list is used instead.
+func (n *NetworkManagerScanner) requestScans(ctx context.Context, conn *dbus.Conn, devices []dbus.ObjectPath) {
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	pending := make(map[dbus.ObjectPath]bool)
+	for _, device := range devices {
+		match := []dbus.MatchOption{
+			dbus.WithMatchObjectPath(device),
+			dbus.WithMatchInterface(dbusPropsIface),
+			dbus.WithMatchMember("PropertiesChanged"),
+		}
+		if err := conn.AddMatchSignal(match...); err != nil {
+			continue
+		}
+		defer conn.RemoveMatchSignal(match...)
+
+		call := nmCall(ctx, conn.Object(nmService, device), nmWirelessIface+".RequestScan", map[string]dbus.Variant{})
+		if call.Err == nil {
+			pending[device] = true
+		}
+	}
+
+	timeout := time.NewTimer(nmScanTimeout)
+	defer timeout.Stop()
+
+	for len(pending) > 0 {
+		select {
+		case sig := <-signals:
+			if !pending[sig.Path] || sig.Name != dbusPropsIface+".PropertiesChanged" || len(sig.Body) < 2 {
+				continue
+			}
+			if iface, _ := sig.Body[0].(string); iface != nmWirelessIface {
+				continue
+			}
+			if changed, ok := sig.Body[1].(map[string]dbus.Variant); ok {
+				if _, ok := changed["LastScan"]; ok {
+					delete(pending, sig.Path)
+				}
+			}
+		case <-timeout.C:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// nmCall invokes a D-Bus method, bounded by nmCallTimeout as well as ctx
+func nmCall(ctx context.Context, obj dbus.BusObject, method string, args ...interface{}) *dbus.Call {
+	ctx, cancel := context.WithTimeout(ctx, nmCallTimeout)
+	defer cancel()
+	return obj.CallWithContext(ctx, method, 0, args...)
+}
+
+// nmProperty reads a single D-Bus property through org.freedesktop.DBus.Properties.Get
+func nmProperty(ctx context.Context, obj dbus.BusObject, iface, name string) (dbus.Variant, error) {
+	var v dbus.Variant
+	err := nmCall(ctx, obj, dbusPropsIface+".Get", iface, name).Store(&v)
+	return v, err
+}
+
+// nmError wraps a D-Bus failure with the matching sentinel error
+func nmError(op string, err error) error {
+	var dbusErr dbus.Error
+	if errors.As(err, &dbusErr) {
+		switch dbusErr.Name {
+		case "org.freedesktop.DBus.Error.ServiceUnknown", "org.freedesktop.DBus.Error.NameHasNoOwner":
+			return fmt.Errorf("%s: %w: NetworkManager is not running", op, ErrToolMissing)
+		case "org.freedesktop.DBus.Error.AccessDenied", "org.freedesktop.NetworkManager.PermissionDenied":
+			return fmt.Errorf("%s: %w: %v", op, ErrPermissionDenied, err)
+		}
+	}
+	return fmt.Errorf("%s failed: %w", op, err)
+}
+
+// accessPointToNetwork converts AccessPoint properties into a WiFiNetwork
+func (n *NetworkManagerScanner) accessPointToNetwork(props map[string]dbus.Variant) (WiFiNetwork, error) {
+	ssidBytes, _ := props["Ssid"].Value().([]byte)
+	bssid, _ := props["HwAddress"].Value().(string)
+	frequency, _ := props["Frequency"].Value().(uint32)
+	strength, _ := props["Strength"].Value().(byte)
+	maxBitrate, _ := props["MaxBitrate"].Value().(uint32)
+	flags, _ := props["Flags"].Value().(uint32)
+	wpaFlags, _ := props["WpaFlags"].Value().(uint32)
+	rsnFlags, _ := props["RsnFlags"].Value().(uint32)
+	mode, _ := props["Mode"].Value().(uint32)
+
+	if bssid == "" || frequency == 0 {
+		return WiFiNetwork{}, fmt.Errorf("incomplete access point data")
+	}
+
+	network := WiFiNetwork{
+		SSID:            string(ssidBytes),
+		Frequency:       int(frequency),
+		CenterFrequency: int(frequency),
+		Security:        nmSecurity(flags, wpaFlags, rsnFlags),
+		PHYMode:         "Unknown",
+		ChannelWidth:    "Unknown",
+		NetworkType:     "Infrastructure",
+		BSSID:           strings.ToLower(bssid),
+		Vendor:          getVendorFromMAC(bssid),
+		MaxRate:         int(maxBitrate / 1000),
+	}
+
+	if err := applyFrequency(&network); err != nil {
+		return network, err
+	}
+	setSignal(&network, int(strength), SignalPercent)
+	applyStationEstimate(&network)
+	if mode == nmWiFiModeAdhoc {
+		network.NetworkType = "Ad-hoc"
+	}
+
+	// Bandwidth is only exported by NetworkManager 1.46 and later
+	if bandwidth, ok := props["Bandwidth"].Value().(uint32); ok && bandwidth > 0 {
+		network.ChannelWidth = fmt.Sprintf("%dMHz", bandwidth)
+	}
+
+	return network, nil
+}
+
+// nmSecurity maps NetworkManager's AP flags to a security label
+func nmSecurity(flags, wpaFlags, rsnFlags uint32) string {
+	if wpaFlags == 0 && rsnFlags == 0 {
+		if flags&nmAPFlagsPrivacy != 0 {
+			return "WEP"
+		}
+		return "Open"
+	}
+
+	all := wpaFlags | rsnFlags
+	psk := all&nmAPSecKeyMgmtPSK != 0
+	sae := all&nmAPSecKeyMgmtSAE != 0
+	enterprise := all&(nmAPSecKeyMgmt8021X|nmAPSecKeyMgmtEAPB92) != 0
+	owe := all&(nmAPSecKeyMgmtOWE|nmAPSecKeyMgmtOWETM) != 0
+
+	generation := "WPA2"
+	switch {
+	case rsnFlags == 0:
+		generation = "WPA"
+	case wpaFlags != 0:
+		generation = "WPA/WPA2"
+	case sae && psk:
+		generation = "WPA2/WPA3"
+	case sae, all&nmAPSecKeyMgmtEAPB92 != 0:
+		generation = "WPA3"
+	}
+
+	switch {
+	case enterprise:
+		return generation + " Enterprise"
+	case psk || sae:
+		return generation + " Personal"
+	case owe:
+		return "OWE"
+	case all&(nmAPSecPairWEP40|nmAPSecPairWEP104) != 0:
+		return "WEP"
+	}
+	return generation
+}
+
+// bootTime returns the time since boot including suspend, matching NetworkManager's CLOCK_BOOTTIME timestamps
+var bootTime = func() (time.Duration, error) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected /proc/uptime format")
+	}
+
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
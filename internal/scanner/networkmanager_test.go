@@ -0,0 +1,336 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// busConfig is a minimal session bus configuration for a private dbus-daemon
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:dir=%DIR%</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// startPrivateBus runs a dbus-daemon for the test and returns its address
+func startPrivateBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(config, []byte(strings.ReplaceAll(busConfig, "%DIR%", dir)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("dbus-daemon failed to start: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+// connectBus opens an authenticated connection to the private bus
+func connectBus(t *testing.T, address string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("connecting to the private bus: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// stubProperties serves org.freedesktop.DBus.Properties for one object
+type stubProperties struct {
+	mu    sync.Mutex
+	props map[string]map[string]dbus.Variant // Interface to property to value
+}
+
+func (p *stubProperties) Get(iface, name string) (dbus.Variant, *dbus.Error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if v, ok := p.props[iface][name]; ok {
+		return v, nil
+	}
+	return dbus.Variant{}, dbus.NewError("org.freedesktop.DBus.Error.UnknownProperty", []interface{}{name})
+}
+
+func (p *stubProperties) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.props[iface], nil
+}
+
+// stubNetworkManager is the root NetworkManager object
+type stubNetworkManager struct {
+	devices []dbus.ObjectPath
+}
+
+func (s *stubNetworkManager) GetDevices() ([]dbus.ObjectPath, *dbus.Error) {
+	return s.devices, nil
+}
+
+// stubWirelessDevice is a Device.Wireless object that finishes a requested scan after scanDelay
+type stubWirelessDevice struct {
+	conn      *dbus.Conn
+	path      dbus.ObjectPath
+	aps       []dbus.ObjectPath
+	scanDelay time.Duration
+	scanError *dbus.Error // Returned by RequestScan instead of scanning
+
+	mu       sync.Mutex
+	requests int
+	scanned  time.Time // When LastScan changed
+}
+
+func (d *stubWirelessDevice) GetAllAccessPoints() ([]dbus.ObjectPath, *dbus.Error) {
+	return d.aps, nil
+}
+
+func (d *stubWirelessDevice) RequestScan(options map[string]dbus.Variant) *dbus.Error {
+	d.mu.Lock()
+	d.requests++
+	d.mu.Unlock()
+	if d.scanError != nil {
+		return d.scanError
+	}
+
+	go func() {
+		time.Sleep(d.scanDelay)
+		d.mu.Lock()
+		d.scanned = time.Now()
+		d.mu.Unlock()
+
+		// A change of another interface's properties must not end the wait
+		d.conn.Emit(d.path, dbusPropsIface+".PropertiesChanged", nmDeviceInterface,
+			map[string]dbus.Variant{"State": dbus.MakeVariant(uint32(100))}, []string{})
+		d.conn.Emit(d.path, dbusPropsIface+".PropertiesChanged", nmWirelessIface,
+			map[string]dbus.Variant{"LastScan": dbus.MakeVariant(int64(12345))}, []string{})
+	}()
+	return nil
+}
+
+// stubNM is a NetworkManager exporting one WiFi device with two access points and an Ethernet device
+type stubNM struct {
+	root     *stubProperties
+	wireless *stubWirelessDevice
+}
+
+// exportStubNM claims NetworkManager's name on the bus and exports its objects,
+// letting configure adjust them before any call can reach them
+func exportStubNM(t *testing.T, conn *dbus.Conn, configure func(*stubNM)) *stubNM {
+	t.Helper()
+	wifiPath := dbus.ObjectPath(nmPath + "/Devices/3")
+	ethernetPath := dbus.ObjectPath(nmPath + "/Devices/1")
+	officePath := dbus.ObjectPath(nmPath + "/AccessPoint/10")
+	cafePath := dbus.ObjectPath(nmPath + "/AccessPoint/11")
+
+	export := func(v interface{}, path dbus.ObjectPath, iface string) {
+		if err := conn.Export(v, path, iface); err != nil {
+			t.Fatalf("exporting %s on %s: %v", iface, path, err)
+		}
+	}
+
+	stub := &stubNM{
+		root: &stubProperties{props: map[string]map[string]dbus.Variant{
+			nmInterface: {"WirelessEnabled": dbus.MakeVariant(true)},
+		}},
+		wireless: &stubWirelessDevice{conn: conn, path: wifiPath, aps: []dbus.ObjectPath{officePath, cafePath}, scanDelay: 200 * time.Millisecond},
+	}
+	if configure != nil {
+		configure(stub)
+	}
+	export(&stubNetworkManager{devices: []dbus.ObjectPath{ethernetPath, wifiPath}}, nmPath, nmInterface)
+	export(stub.root, nmPath, dbusPropsIface)
+
+	export(&stubProperties{props: map[string]map[string]dbus.Variant{
+		nmDeviceInterface: {"DeviceType": dbus.MakeVariant(uint32(1)), "Interface": dbus.MakeVariant("eth0")},
+	}}, ethernetPath, dbusPropsIface)
+
+	export(stub.wireless, wifiPath, nmWirelessIface)
+	export(&stubProperties{props: map[string]map[string]dbus.Variant{
+		nmDeviceInterface: {"DeviceType": dbus.MakeVariant(uint32(nmDeviceTypeWiFi)), "Interface": dbus.MakeVariant("wlan0")},
+		nmWirelessIface:   {"ActiveAccessPoint": dbus.MakeVariant(officePath)},
+	}}, wifiPath, dbusPropsIface)
+
+	export(&stubProperties{props: map[string]map[string]dbus.Variant{nmAPInterface: {
+		"Ssid":       dbus.MakeVariant([]byte("Office")),
+		"HwAddress":  dbus.MakeVariant("00:11:32:AA:BB:CC"),
+		"Frequency":  dbus.MakeVariant(uint32(5180)),
+		"Strength":   dbus.MakeVariant(byte(70)),
+		"MaxBitrate": dbus.MakeVariant(uint32(866700)),
+		"Flags":      dbus.MakeVariant(uint32(nmAPFlagsPrivacy)),
+		"RsnFlags":   dbus.MakeVariant(uint32(nmAPSecKeyMgmtPSK | nmAPSecKeyMgmtSAE)),
+		"Bandwidth":  dbus.MakeVariant(uint32(80)),
+		"LastSeen":   dbus.MakeVariant(int32(990)),
+	}}}, officePath, dbusPropsIface)
+
+	export(&stubProperties{props: map[string]map[string]dbus.Variant{nmAPInterface: {
+		"Ssid":      dbus.MakeVariant([]byte("Cafe")),
+		"HwAddress": dbus.MakeVariant("02:00:00:00:00:01"),
+		"Frequency": dbus.MakeVariant(uint32(2437)),
+		"Strength":  dbus.MakeVariant(byte(30)),
+		"LastSeen":  dbus.MakeVariant(int32(-1)), // Never seen by a scan
+	}}}, cafePath, dbusPropsIface)
+
+	reply, err := conn.RequestName(nmService, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("claiming %s: reply %v, error %v", nmService, reply, err)
+	}
+	return stub
+}
+
+// stubBootTime reports a fixed time since boot for the test
+func stubBootTime(t *testing.T, uptime time.Duration) {
+	original := bootTime
+	bootTime = func() (time.Duration, error) { return uptime, nil }
+	t.Cleanup(func() { bootTime = original })
+}
+
+func TestNetworkManagerScan(t *testing.T) {
+	address := startPrivateBus(t)
+	stub := exportStubNM(t, connectBus(t, address), nil)
+	stubBootTime(t, 1000*time.Second)
+
+	scanner := &NetworkManagerScanner{Conn: connectBus(t, address)}
+	networks, err := scanner.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	returned := time.Now()
+
+	stub.wireless.mu.Lock()
+	requests, scanned := stub.wireless.requests, stub.wireless.scanned
+	stub.wireless.mu.Unlock()
+	if requests != 1 {
+		t.Errorf("RequestScan called %d times, want 1", requests)
+	}
+	if scanned.IsZero() || returned.Before(scanned) {
+		t.Error("Scan returned before LastScan changed")
+	}
+
+	if len(networks) != 2 {
+		t.Fatalf("got %d networks, want 2 (Ethernet devices are skipped)", len(networks))
+	}
+	office, cafe := networks[0], networks[1]
+	if office.SSID != "Office" || office.BSSID != "00:11:32:aa:bb:cc" || office.Channel != 36 || office.ChannelWidth != "80MHz" ||
+		office.MaxRate != 866 || office.Security != "WPA2/WPA3 Personal" || !office.Connected || office.SignalUnit != SignalPercent {
+		t.Errorf("Office decoded as %+v", office)
+	}
+	if cafe.Connected || cafe.Security != "Open" || cafe.ChannelWidth != "Unknown" {
+		t.Errorf("Cafe decoded as %+v", cafe)
+	}
+
+	// LastSeen is CLOCK_BOOTTIME seconds: 990 s after boot is 10 s before the stubbed uptime of 1000 s
+	if age := returned.Sub(office.LastSeen); age < 10*time.Second || age > 11*time.Second {
+		t.Errorf("Office last seen %v before the scan returned, want 10s", age)
+	}
+	if !cafe.LastSeen.IsZero() {
+		t.Errorf("Cafe was never seen but LastSeen = %v", cafe.LastSeen)
+	}
+}
+
+func TestNetworkManagerScanDeniedUsesCachedResults(t *testing.T) {
+	address := startPrivateBus(t)
+	exportStubNM(t, connectBus(t, address), func(stub *stubNM) {
+		stub.wireless.scanError = dbus.NewError("org.freedesktop.NetworkManager.Device.NotAllowed", []interface{}{"Scanning not allowed"})
+		stub.wireless.scanDelay = time.Hour
+	})
+
+	start := time.Now()
+	networks, err := (&NetworkManagerScanner{Conn: connectBus(t, address), Interface: "wlan0"}).Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(networks) != 2 {
+		t.Errorf("got %d cached networks, want 2", len(networks))
+	}
+	if elapsed := time.Since(start); elapsed > nmScanTimeout/2 {
+		t.Errorf("waited %v for a scan that was refused", elapsed)
+	}
+}
+
+func TestNetworkManagerScanErrors(t *testing.T) {
+	address := startPrivateBus(t)
+	stub := exportStubNM(t, connectBus(t, address), nil)
+	client := connectBus(t, address)
+
+	if _, err := (&NetworkManagerScanner{Conn: client, Interface: "wlan9"}).Scan(context.Background()); !errors.Is(err, ErrNoInterface) {
+		t.Errorf("unknown interface: error = %v, want ErrNoInterface", err)
+	}
+
+	stub.root.mu.Lock()
+	stub.root.props[nmInterface]["WirelessEnabled"] = dbus.MakeVariant(false)
+	stub.root.mu.Unlock()
+	if _, err := (&NetworkManagerScanner{Conn: client}).Scan(context.Background()); !errors.Is(err, ErrRadioDisabled) {
+		t.Errorf("wireless disabled: error = %v, want ErrRadioDisabled", err)
+	}
+}
+
+func TestNetworkManagerNotRunning(t *testing.T) {
+	address := startPrivateBus(t)
+
+	_, err := (&NetworkManagerScanner{Conn: connectBus(t, address)}).Scan(context.Background())
+	if !errors.Is(err, ErrToolMissing) {
+		t.Errorf("error = %v, want ErrToolMissing", err)
+	}
+}
+
+func TestNMSecurity(t *testing.T) {
+	tests := []struct {
+		flags, wpa, rsn uint32
+		want            string
+	}{
+		{0, 0, 0, "Open"},
+		{nmAPFlagsPrivacy, 0, 0, "WEP"},
+		{nmAPFlagsPrivacy, nmAPSecKeyMgmtPSK, 0, "WPA Personal"},
+		{nmAPFlagsPrivacy, 0, nmAPSecKeyMgmtPSK, "WPA2 Personal"},
+		{nmAPFlagsPrivacy, nmAPSecKeyMgmtPSK, nmAPSecKeyMgmtPSK, "WPA/WPA2 Personal"},
+		{nmAPFlagsPrivacy, 0, nmAPSecKeyMgmtPSK | nmAPSecKeyMgmtSAE, "WPA2/WPA3 Personal"},
+		{nmAPFlagsPrivacy, 0, nmAPSecKeyMgmtSAE, "WPA3 Personal"},
+		{nmAPFlagsPrivacy, 0, nmAPSecKeyMgmt8021X, "WPA2 Enterprise"},
+		{nmAPFlagsPrivacy, 0, nmAPSecKeyMgmtEAPB92, "WPA3 Enterprise"},
+		{0, 0, nmAPSecKeyMgmtOWE, "OWE"},
+	}
+
+	for _, test := range tests {
+		if got := nmSecurity(test.flags, test.wpa, test.rsn); got != test.want {
+			t.Errorf("nmSecurity(%#x, %#x, %#x) = %q, want %q", test.flags, test.wpa, test.rsn, got, test.want)
+		}
+	}
+}
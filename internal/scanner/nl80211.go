@@ -0,0 +1,663 @@
+package scanner
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Generic netlink and nl80211 protocol constants (see linux/netlink.h, linux/genetlink.h and linux/nl80211.h)
+const (
+	nlmsgHeaderLen  = 16
+	genlHeaderLen   = 4
+	nlattrHeaderLen = 4
+
+	nlmsgError = 0x2
+	nlmsgDone  = 0x3
+
+	nlmFRequest = 0x1
+	nlmFMulti   = 0x2
+	nlmFAck     = 0x4
+	nlmFDump    = 0x300
+
+	nlaFNested    = 0x8000
+	nlaFByteOrder = 0x4000
+
+	genlIDCtrl          = 0x10
+	ctrlCmdGetFamily    = 3
+	ctrlAttrFamilyID    = 1
+	ctrlAttrFamilyName  = 2
+	ctrlAttrMcastGroups = 7
+	ctrlAttrMcastGrpNm  = 1
+	ctrlAttrMcastGrpID  = 2
+
+	nl80211CmdGetInterface   = 5
+	nl80211CmdGetScan        = 32
+	nl80211CmdTriggerScan    = 33
+	nl80211CmdNewScanResults = 34
+	nl80211CmdScanAborted    = 35
+	nl80211CmdGetSurvey      = 50
+
+	nl80211AttrIfindex    = 3
+	nl80211AttrIfname     = 4
+	nl80211AttrIftype     = 5
+	nl80211AttrBSS        = 47
+	nl80211AttrSurveyInfo = 84
+
+	nl80211BSSBSSID            = 1
+	nl80211BSSFrequency        = 2
+	nl80211BSSCapability       = 5
+	nl80211BSSInformationElems = 6
+	nl80211BSSSignalMBM        = 7
+	nl80211BSSSignalUnspec     = 8
+	nl80211BSSStatus           = 9
+	nl80211BSSSeenMsAgo        = 10
+	nl80211BSSBeaconIEs        = 11
+
+	// Values of nl80211BSSStatus; an authenticated-only BSS is not connected
+	nl80211BSSStatusAssociated = 1
+	nl80211BSSStatusIBSSJoined = 2
+
+	nl80211SurveyInfoFrequency = 1
+	nl80211SurveyInfoNoise     = 2
+
+	nl80211IftypeStation = 2
+
+	nl80211FamilyName = "nl80211"
+	nl80211ScanGroup  = "scan"
+)
+
+// Timeouts for kernel replies. Receives are split into short polls so cancellation is noticed promptly.
+const (
+	nl80211ScanTimeout  = 15 * time.Second // Trigger to scan completion
+	nl80211ReplyTimeout = 5 * time.Second  // Any single request
+	nl80211PollInterval = 250 * time.Millisecond
+)
+
+// netlinkMessage is a single decoded netlink message
+type netlinkMessage struct {
+	Type    uint16
+	Flags   uint16
+	Seq     uint32
+	Payload []byte
+}
+
+// netlinkConn is the minimal generic netlink socket used by the nl80211 scanner.
+// It is an interface so the scanner can be exercised against recorded message dumps.
+type netlinkConn interface {
+	Send(msg []byte) error
+	Receive() ([]netlinkMessage, error)
+	JoinGroup(group uint32) error
+	SetReadDeadline(t time.Time) error
+	Close() error
+}
+
+// netlinkAttr is a single netlink attribute
+type netlinkAttr struct {
+	Type uint16
+	Data []byte
+}
+
+// NL80211Scanner implements WiFi scanning on Linux by talking nl80211 over generic netlink
+type NL80211Scanner struct {
+	// Interface is the wireless interface to scan; the first station interface is used and stored here when empty
+	Interface string
+
+	// dial opens the netlink socket; it defaults to a real NETLINK_GENERIC socket
+	dial func() (netlinkConn, error)
+	seq  uint32
+}
+
+// Scan triggers an nl80211 scan and returns the resulting BSS list
+func (n *NL80211Scanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	dial := n.dial
+	if dial == nil {
+		dial = dialNetlink
+	}
+
+	conn, err := dial()
+	if err != nil {
+		return nil, fmt.Errorf("nl80211 socket failed: %v", err)
+	}
+	defer conn.Close()
+	n.seq = 0
+
+	familyID, groups, err := n.resolveFamily(ctx, conn, nl80211FamilyName)
+	if errors.Is(err, syscall.ENOENT) {
+		// cfg80211 registers the family as soon as any wireless driver loads
+		return nil, fmt.Errorf("nl80211 family not registered: %w", ErrNoInterface)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("nl80211 family lookup failed: %w", err)
+	}
+
+	ifindex, err := n.resolveInterface(ctx, conn, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := n.triggerScan(ctx, conn, familyID, groups[nl80211ScanGroup], ifindex); err != nil {
+		return nil, err
+	}
+
+	noise, err := n.dumpSurvey(ctx, conn, familyID, ifindex)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		// Survey data is optional; many drivers do not implement it
+		noise = nil
+	}
+
+	return n.dumpScan(ctx, conn, familyID, ifindex, noise)
+}
+
+// Source reports the backend and the interface used by the last scan
+func (n *NL80211Scanner) Source() (string, string) {
+	return "nl80211", n.Interface
+}
+
+// resolveFamily looks up the numeric family ID and multicast groups of a generic netlink family
+func (n *NL80211Scanner) resolveFamily(ctx context.Context, conn netlinkConn, name string) (uint16, map[string]uint32, error) {
+	attrs := encodeAttr(ctrlAttrFamilyName, append([]byte(name), 0))
+	msgs, err := n.request(ctx, conn, genlIDCtrl, nlmFRequest, ctrlCmdGetFamily, attrs)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for _, msg := range msgs {
+		attrs, err := parseAttrs(msg.Payload[genlHeaderLen:])
+		if err != nil {
+			return 0, nil, err
+		}
+
+		var familyID uint16
+		groups := make(map[string]uint32)
+		for _, attr := range attrs {
+			switch attr.Type {
+			case ctrlAttrFamilyID:
+				if len(attr.Data) >= 2 {
+					familyID = binary.NativeEndian.Uint16(attr.Data)
+				}
+			case ctrlAttrMcastGroups:
+				parseMcastGroups(attr.Data, groups)
+			}
+		}
+
+		if familyID != 0 {
+			return familyID, groups, nil
+		}
+	}
+
+	return 0, nil, fmt.Errorf("family %q not found", name)
+}
+
+// parseMcastGroups decodes the nested CTRL_ATTR_MCAST_GROUPS array
+func parseMcastGroups(data []byte, groups map[string]uint32) {
+	entries, err := parseAttrs(data)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		fields, err := parseAttrs(entry.Data)
+		if err != nil {
+			continue
+		}
+
+		var name string
+		var id uint32
+		for _, field := range fields {
+			switch field.Type {
+			case ctrlAttrMcastGrpNm:
+				name = nullTerminated(field.Data)
+			case ctrlAttrMcastGrpID:
+				if len(field.Data) >= 4 {
+					id = binary.NativeEndian.Uint32(field.Data)
+				}
+			}
+		}
+
+		if name != "" {
+			groups[name] = id
+		}
+	}
+}
+
+// resolveInterface returns the interface index to scan, discovering a station interface when none is configured
+func (n *NL80211Scanner) resolveInterface(ctx context.Context, conn netlinkConn, familyID uint16) (uint32, error) {
+	if n.Interface != "" {
+		iface, err := net.InterfaceByName(n.Interface)
+		if err != nil {
+			return 0, fmt.Errorf("failed to find WiFi interface %s: %w: %v", n.Interface, ErrNoInterface, err)
+		}
+		return uint32(iface.Index), nil
+	}
+
+	msgs, err := n.request(ctx, conn, familyID, nlmFRequest|nlmFDump, nl80211CmdGetInterface, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list WiFi interfaces: %w", err)
+	}
+
+	for _, msg := range msgs {
+		attrs, err := parseAttrs(msg.Payload[genlHeaderLen:])
+		if err != nil {
+			continue
+		}
+
+		var ifindex, iftype uint32
+		var ifname string
+		for _, attr := range attrs {
+			switch attr.Type {
+			case nl80211AttrIfindex:
+				ifindex = attrUint32(attr.Data)
+			case nl80211AttrIftype:
+				iftype = attrUint32(attr.Data)
+			case nl80211AttrIfname:
+				ifname = nullTerminated(attr.Data)
+			}
+		}
+
+		if ifindex != 0 && iftype == nl80211IftypeStation {
+			n.Interface = ifname
+			return ifindex, nil
+		}
+	}
+
+	return 0, ErrNoInterface
+}
+
+// triggerScan requests a fresh scan and waits for the kernel to announce the results.
+// Without CAP_NET_ADMIN the trigger is refused, in which case the cached results are used.
+func (n *NL80211Scanner) triggerScan(ctx context.Context, conn netlinkConn, familyID uint16, group, ifindex uint32) error {
+	if group == 0 {
+		return nil
+	}
+	if err := conn.JoinGroup(group); err != nil {
+		return fmt.Errorf("failed to join nl80211 scan group: %v", err)
+	}
+
+	attrs := encodeAttr(nl80211AttrIfindex, uint32Bytes(ifindex))
+	_, err := n.request(ctx, conn, familyID, nlmFRequest|nlmFAck, nl80211CmdTriggerScan, attrs)
+	switch {
+	case errors.Is(err, syscall.EPERM), errors.Is(err, syscall.EACCES):
+		return nil
+	case errors.Is(err, syscall.EBUSY):
+		// A scan is already running; wait for it instead
+	case err != nil:
+		return errnoError("nl80211 scan trigger failed", err)
+	}
+
+	deadline := time.Now().Add(nl80211ScanTimeout)
+	for {
+		msgs, err := n.receive(ctx, conn, deadline)
+		if err != nil {
+			return fmt.Errorf("waiting for nl80211 scan results failed: %w", err)
+		}
+
+		for _, msg := range msgs {
+			if msg.Type != familyID || len(msg.Payload) < genlHeaderLen {
+				continue
+			}
+			cmd := msg.Payload[0]
+			if cmd != nl80211CmdNewScanResults && cmd != nl80211CmdScanAborted {
+				continue
+			}
+			if !messageHasIfindex(msg, ifindex) {
+				continue
+			}
+			if cmd == nl80211CmdScanAborted {
+				return fmt.Errorf("nl80211 scan aborted")
+			}
+			return nil
+		}
+	}
+}
+
+// dumpSurvey returns the noise floor in dBm per frequency, as reported by the driver's channel survey
+func (n *NL80211Scanner) dumpSurvey(ctx context.Context, conn netlinkConn, familyID uint16, ifindex uint32) (map[int]int, error) {
+	attrs := encodeAttr(nl80211AttrIfindex, uint32Bytes(ifindex))
+	msgs, err := n.request(ctx, conn, familyID, nlmFRequest|nlmFDump, nl80211CmdGetSurvey, attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	noise := make(map[int]int)
+	for _, msg := range msgs {
+		attrs, err := parseAttrs(msg.Payload[genlHeaderLen:])
+		if err != nil {
+			continue
+		}
+		for _, attr := range attrs {
+			if attr.Type != nl80211AttrSurveyInfo {
+				continue
+			}
+			info, err := parseAttrs(attr.Data)
+			if err != nil {
+				continue
+			}
+
+			var freq, level int
+			hasNoise := false
+			for _, field := range info {
+				switch field.Type {
+				case nl80211SurveyInfoFrequency:
+					freq = int(attrUint32(field.Data))
+				case nl80211SurveyInfoNoise:
+					if len(field.Data) >= 1 {
+						level = int(int8(field.Data[0]))
+						hasNoise = true
+					}
+				}
+			}
+			if freq != 0 && hasNoise {
+				noise[freq] = level
+			}
+		}
+	}
+
+	return noise, nil
+}
+
+// dumpScan retrieves the kernel's BSS table for the interface
+func (n *NL80211Scanner) dumpScan(ctx context.Context, conn netlinkConn, familyID uint16, ifindex uint32, noise map[int]int) ([]WiFiNetwork, error) {
+	attrs := encodeAttr(nl80211AttrIfindex, uint32Bytes(ifindex))
+	msgs, err := n.request(ctx, conn, familyID, nlmFRequest|nlmFDump, nl80211CmdGetScan, attrs)
+	if err != nil {
+		return nil, errnoError("nl80211 scan dump failed", err)
+	}
+
+	var networks []WiFiNetwork
+	now := time.Now()
+
+	for _, msg := range msgs {
+		attrs, err := parseAttrs(msg.Payload[genlHeaderLen:])
+		if err != nil {
+			continue
+		}
+		for _, attr := range attrs {
+			if attr.Type != nl80211AttrBSS {
+				continue
+			}
+
+			network, err := parseNL80211BSS(attr.Data, now)
+			if err != nil {
+				continue
+			}
+			if level, ok := noise[network.Frequency]; ok {
+				network.Noise = level
+				network.SNR = network.Signal - level
+			}
+
+			networks = append(networks, network)
+		}
+	}
+
+	// Calculate congestion scores
+	scoreCongestion(networks)
+
+	return networks, nil
+}
+
+// parseNL80211BSS converts a nested NL80211_ATTR_BSS attribute into a WiFiNetwork
+func parseNL80211BSS(data []byte, now time.Time) (WiFiNetwork, error) {
+	attrs, err := parseAttrs(data)
+	if err != nil {
+		return WiFiNetwork{}, err
+	}
+
+	network := WiFiNetwork{
+		NetworkType: "Infrastructure",
+		LastSeen:    now,
+	}
+	var capability uint16
+	var ies, beaconIEs []byte
+	hasSignal := false
+
+	for _, attr := range attrs {
+		switch attr.Type {
+		case nl80211BSSBSSID:
+			if len(attr.Data) == 6 {
+				network.BSSID = net.HardwareAddr(attr.Data).String()
+			}
+		case nl80211BSSFrequency:
+			network.Frequency = int(attrUint32(attr.Data))
+		case nl80211BSSCapability:
+			if len(attr.Data) >= 2 {
+				capability = binary.NativeEndian.Uint16(attr.Data)
+			}
+		case nl80211BSSInformationElems:
+			ies = attr.Data
+		case nl80211BSSBeaconIEs:
+			beaconIEs = attr.Data
+		case nl80211BSSSignalMBM:
+			setSignal(&network, int(int32(attrUint32(attr.Data)))/100, SignalDBm)
+			hasSignal = true
+		case nl80211BSSSignalUnspec:
+			if !hasSignal && len(attr.Data) >= 1 {
+				// Unitless 0-100 value reported by drivers without dBm support
+				setSignal(&network, int(attr.Data[0]), SignalPercent)
+			}
+		case nl80211BSSStatus:
+			switch attrUint32(attr.Data) {
+			case nl80211BSSStatusAssociated, nl80211BSSStatusIBSSJoined:
+				network.Connected = true
+			}
+		case nl80211BSSSeenMsAgo:
+			network.LastSeen = now.Add(-time.Duration(attrUint32(attr.Data)) * time.Millisecond)
+		}
+	}
+
+	if network.BSSID == "" || network.Frequency == 0 {
+		return network, fmt.Errorf("incomplete BSS data")
+	}
+	if ies == nil {
+		ies = beaconIEs
+	}
+
+	if err := applyFrequency(&network); err != nil {
+		return network, err
+	}
+	network.Vendor = getVendorFromMAC(network.BSSID)
+
+	applyInformationElements(&network, ies, capability)
+	applyStationEstimate(&network)
+
+	return network, nil
+}
+
+// request sends a generic netlink request and collects the replies up to the final ACK or NLMSG_DONE
+func (n *NL80211Scanner) request(ctx context.Context, conn netlinkConn, family, flags uint16, cmd uint8, attrs []byte) ([]netlinkMessage, error) {
+	n.seq++
+	seq := n.seq
+
+	if err := conn.Send(encodeGenlMessage(family, flags, seq, cmd, attrs)); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(nl80211ReplyTimeout)
+	var replies []netlinkMessage
+	for {
+		msgs, err := n.receive(ctx, conn, deadline)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, msg := range msgs {
+			// Multicast notifications arrive with sequence number 0 and are not ours
+			if msg.Seq != seq {
+				continue
+			}
+
+			switch msg.Type {
+			case nlmsgError:
+				if len(msg.Payload) < 4 {
+					return nil, fmt.Errorf("truncated netlink error")
+				}
+				if errno := -int32(binary.NativeEndian.Uint32(msg.Payload)); errno != 0 {
+					return nil, syscall.Errno(errno)
+				}
+				return replies, nil
+			case nlmsgDone:
+				return replies, nil
+			}
+
+			if len(msg.Payload) < genlHeaderLen {
+				continue
+			}
+			replies = append(replies, msg)
+
+			if msg.Flags&nlmFMulti == 0 && flags&nlmFAck == 0 {
+				return replies, nil
+			}
+		}
+	}
+}
+
+// receive waits for the next datagram until the deadline, polling so that ctx cancellation interrupts the wait
+func (n *NL80211Scanner) receive(ctx context.Context, conn netlinkConn, deadline time.Time) ([]netlinkMessage, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		poll := time.Now().Add(nl80211PollInterval)
+		if poll.After(deadline) {
+			poll = deadline
+		}
+		if err := conn.SetReadDeadline(poll); err != nil {
+			return nil, err
+		}
+
+		msgs, err := conn.Receive()
+		if errors.Is(err, os.ErrDeadlineExceeded) && time.Now().Before(deadline) {
+			continue
+		}
+		return msgs, err
+	}
+}
+
+// encodeGenlMessage builds a complete netlink message with a generic netlink header
+func encodeGenlMessage(family, flags uint16, seq uint32, cmd uint8, attrs []byte) []byte {
+	length := nlmsgHeaderLen + genlHeaderLen + len(attrs)
+	msg := make([]byte, length)
+
+	binary.NativeEndian.PutUint32(msg[0:4], uint32(length))
+	binary.NativeEndian.PutUint16(msg[4:6], family)
+	binary.NativeEndian.PutUint16(msg[6:8], flags)
+	binary.NativeEndian.PutUint32(msg[8:12], seq)
+	msg[16] = cmd
+	msg[17] = 1 // Generic netlink version
+	copy(msg[nlmsgHeaderLen+genlHeaderLen:], attrs)
+
+	return msg
+}
+
+// parseNetlinkMessages splits a datagram into its netlink messages
+func parseNetlinkMessages(buf []byte) ([]netlinkMessage, error) {
+	var msgs []netlinkMessage
+
+	for len(buf) >= nlmsgHeaderLen {
+		length := int(binary.NativeEndian.Uint32(buf[0:4]))
+		if length < nlmsgHeaderLen || length > len(buf) {
+			return nil, fmt.Errorf("malformed netlink message")
+		}
+
+		msgs = append(msgs, netlinkMessage{
+			Type:    binary.NativeEndian.Uint16(buf[4:6]),
+			Flags:   binary.NativeEndian.Uint16(buf[6:8]),
+			Seq:     binary.NativeEndian.Uint32(buf[8:12]),
+			Payload: buf[nlmsgHeaderLen:length],
+		})
+
+		if nlAlign(length) >= len(buf) {
+			break
+		}
+		buf = buf[nlAlign(length):]
+	}
+
+	return msgs, nil
+}
+
+// encodeAttr encodes a single netlink attribute including padding
+func encodeAttr(attrType uint16, data []byte) []byte {
+	length := nlattrHeaderLen + len(data)
+	buf := make([]byte, nlAlign(length))
+	binary.NativeEndian.PutUint16(buf[0:2], uint16(length))
+	binary.NativeEndian.PutUint16(buf[2:4], attrType)
+	copy(buf[nlattrHeaderLen:], data)
+	return buf
+}
+
+// parseAttrs decodes a sequence of netlink attributes
+func parseAttrs(buf []byte) ([]netlinkAttr, error) {
+	var attrs []netlinkAttr
+
+	for len(buf) >= nlattrHeaderLen {
+		length := int(binary.NativeEndian.Uint16(buf[0:2]))
+		if length < nlattrHeaderLen || length > len(buf) {
+			return nil, fmt.Errorf("malformed netlink attribute")
+		}
+
+		attrs = append(attrs, netlinkAttr{
+			Type: binary.NativeEndian.Uint16(buf[2:4]) &^ (nlaFNested | nlaFByteOrder),
+			Data: buf[nlattrHeaderLen:length],
+		})
+
+		if nlAlign(length) >= len(buf) {
+			break
+		}
+		buf = buf[nlAlign(length):]
+	}
+
+	return attrs, nil
+}
+
+// messageHasIfindex reports whether a generic netlink message refers to the given interface
+func messageHasIfindex(msg netlinkMessage, ifindex uint32) bool {
+	attrs, err := parseAttrs(msg.Payload[genlHeaderLen:])
+	if err != nil {
+		return false
+	}
+	for _, attr := range attrs {
+		if attr.Type == nl80211AttrIfindex {
+			return attrUint32(attr.Data) == ifindex
+		}
+	}
+	return false
+}
+
+// nlAlign rounds a length up to the 4-byte netlink alignment
+func nlAlign(length int) int {
+	return (length + 3) &^ 3
+}
+
+// attrUint32 reads a native-endian u32 attribute, returning 0 if it is too short
+func attrUint32(data []byte) uint32 {
+	if len(data) < 4 {
+		return 0
+	}
+	return binary.NativeEndian.Uint32(data)
+}
+
+// uint32Bytes encodes a native-endian u32 attribute value
+func uint32Bytes(v uint32) []byte {
+	buf := make([]byte, 4)
+	binary.NativeEndian.PutUint32(buf, v)
+	return buf
+}
+
+// nullTerminated converts a NUL-terminated attribute string to a Go string
+func nullTerminated(data []byte) string {
+	for i, b := range data {
+		if b == 0 {
+			return string(data[:i])
+		}
+	}
+	return string(data)
+}
@@ -0,0 +1,382 @@
+package scanner
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Family ID, scan group and interface index of the recorded dumps
+const (
+	fakeFamilyID  = 0x1c
+	fakeScanGroup = 5
+	fakeIfindex   = 3
+)
+
+// fakeNetlink replays recorded replies: each request is answered with the
+// datagrams recorded for its command, stamped with the request's sequence number.
+// Recorded messages with sequence number 0 are multicast notifications and keep it.
+type fakeNetlink struct {
+	replies map[uint8][][]netlinkMessage
+	pending [][]netlinkMessage
+	sent    []uint8
+	joined  []uint32
+}
+
+func (f *fakeNetlink) Send(msg []byte) error {
+	msgs, err := parseNetlinkMessages(msg)
+	if err != nil || len(msgs) != 1 || len(msgs[0].Payload) < genlHeaderLen {
+		return fmt.Errorf("malformed request")
+	}
+	cmd := msgs[0].Payload[0]
+	f.sent = append(f.sent, cmd)
+
+	for _, datagram := range f.replies[cmd] {
+		stamped := make([]netlinkMessage, len(datagram))
+		for i, reply := range datagram {
+			if reply.Seq != 0 {
+				reply.Seq = msgs[0].Seq
+			}
+			stamped[i] = reply
+		}
+		f.pending = append(f.pending, stamped)
+	}
+	return nil
+}
+
+func (f *fakeNetlink) Receive() ([]netlinkMessage, error) {
+	if len(f.pending) == 0 {
+		return nil, fmt.Errorf("no recorded reply left")
+	}
+	datagram := f.pending[0]
+	f.pending = f.pending[1:]
+	return datagram, nil
+}
+
+func (f *fakeNetlink) JoinGroup(group uint32) error {
+	f.joined = append(f.joined, group)
+	return nil
+}
+
+func (f *fakeNetlink) SetReadDeadline(t time.Time) error { return nil }
+
+func (f *fakeNetlink) Close() error { return nil }
+
+// genlMessage records a generic netlink reply; seq 0 marks a multicast notification
+func genlMessage(family uint16, flags uint16, seq uint32, cmd uint8, attrs ...[]byte) netlinkMessage {
+	payload := []byte{cmd, 1, 0, 0}
+	for _, attr := range attrs {
+		payload = append(payload, attr...)
+	}
+	return netlinkMessage{Type: family, Flags: flags, Seq: seq, Payload: payload}
+}
+
+// ackMessage records an NLMSG_ERROR reply carrying errno, or an ACK for 0
+func ackMessage(errno syscall.Errno) netlinkMessage {
+	payload := make([]byte, 4+nlmsgHeaderLen)
+	binary.NativeEndian.PutUint32(payload, uint32(-int32(errno)))
+	return netlinkMessage{Type: nlmsgError, Seq: 1, Payload: payload}
+}
+
+// doneMessage records the NLMSG_DONE ending a dump
+func doneMessage() netlinkMessage {
+	return netlinkMessage{Type: nlmsgDone, Flags: nlmFMulti, Seq: 1, Payload: make([]byte, 4)}
+}
+
+func nestAttrs(attrType uint16, attrs ...[]byte) []byte {
+	var data []byte
+	for _, attr := range attrs {
+		data = append(data, attr...)
+	}
+	return encodeAttr(attrType|nlaFNested, data)
+}
+
+func int32Bytes(v int32) []byte {
+	return uint32Bytes(uint32(v))
+}
+
+// recordedFamily is the controller's reply to CTRL_CMD_GETFAMILY for nl80211
+func recordedFamily() [][]netlinkMessage {
+	id := make([]byte, 2)
+	binary.NativeEndian.PutUint16(id, fakeFamilyID)
+	return [][]netlinkMessage{{genlMessage(genlIDCtrl, 0, 1, 1,
+		encodeAttr(ctrlAttrFamilyID, id),
+		encodeAttr(ctrlAttrFamilyName, []byte("nl80211\x00")),
+		nestAttrs(ctrlAttrMcastGroups,
+			nestAttrs(1,
+				encodeAttr(ctrlAttrMcastGrpID, uint32Bytes(4)),
+				encodeAttr(ctrlAttrMcastGrpNm, []byte("config\x00"))),
+			nestAttrs(2,
+				encodeAttr(ctrlAttrMcastGrpID, uint32Bytes(fakeScanGroup)),
+				encodeAttr(ctrlAttrMcastGrpNm, []byte("scan\x00")))),
+	)}}
+}
+
+// recordedInterfaces is a GET_INTERFACE dump with a monitor and a station interface
+func recordedInterfaces() [][]netlinkMessage {
+	return [][]netlinkMessage{{
+		genlMessage(fakeFamilyID, nlmFMulti, 1, 7,
+			encodeAttr(nl80211AttrIfindex, uint32Bytes(4)),
+			encodeAttr(nl80211AttrIfname, []byte("mon0\x00")),
+			encodeAttr(nl80211AttrIftype, uint32Bytes(6))),
+		genlMessage(fakeFamilyID, nlmFMulti, 1, 7,
+			encodeAttr(nl80211AttrIfindex, uint32Bytes(fakeIfindex)),
+			encodeAttr(nl80211AttrIfname, []byte("wlp2s0\x00")),
+			encodeAttr(nl80211AttrIftype, uint32Bytes(nl80211IftypeStation))),
+	}, {doneMessage()}}
+}
+
+// recordedScanNotification is the multicast message announcing a finished or aborted scan
+func recordedScanNotification(cmd uint8, ifindex uint32) netlinkMessage {
+	return genlMessage(fakeFamilyID, 0, 0, cmd, encodeAttr(nl80211AttrIfindex, uint32Bytes(ifindex)))
+}
+
+// recordedSurvey is a GET_SURVEY dump with a noise floor for 5180 MHz only
+func recordedSurvey() [][]netlinkMessage {
+	return [][]netlinkMessage{{
+		genlMessage(fakeFamilyID, nlmFMulti, 1, 51,
+			encodeAttr(nl80211AttrIfindex, uint32Bytes(fakeIfindex)),
+			nestAttrs(nl80211AttrSurveyInfo,
+				encodeAttr(nl80211SurveyInfoFrequency, uint32Bytes(5180)),
+				encodeAttr(nl80211SurveyInfoNoise, []byte{0x9f})), // -97 dBm
+		),
+	}, {doneMessage()}}
+}
+
+// Beacon IEs of a WPA2-Personal 802.11ac access point on channel 36 at 80 MHz
+var recordedBeaconIEs = []byte{
+	0x00, 0x06, 'O', 'f', 'f', 'i', 'c', 'e', // SSID
+	0x01, 0x08, 0x8c, 0x12, 0x98, 0x24, 0xb0, 0x48, 0x60, 0x6c, // Supported rates
+	0x0b, 0x05, 0x07, 0x00, 0x80, 0x00, 0x00, // BSS Load: 7 stations, 50% utilization
+	0x30, 0x14, 0x01, 0x00, 0x00, 0x0f, 0xac, 0x04, 0x01, 0x00, 0x00, 0x0f, 0xac, 0x04,
+	0x01, 0x00, 0x00, 0x0f, 0xac, 0x02, 0x0c, 0x00, // RSN: CCMP, PSK
+	0x3d, 0x16, 0x24, 0x05, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // HT Operation: channel 36, secondary above
+	0xc0, 0x05, 0x01, 0x2a, 0x00, 0x00, 0x00, // VHT Operation: 80 MHz centered on channel 42
+}
+
+// recordedScan is a GET_SCAN dump with one complete BSS, one without a frequency
+// and one 2.4 GHz BSS reporting only an unspecified signal and no IEs
+func recordedScan() [][]netlinkMessage {
+	return [][]netlinkMessage{{
+		genlMessage(fakeFamilyID, nlmFMulti, 1, nl80211CmdNewScanResults,
+			encodeAttr(nl80211AttrIfindex, uint32Bytes(fakeIfindex)),
+			nestAttrs(nl80211AttrBSS,
+				encodeAttr(nl80211BSSBSSID, []byte{0x00, 0x11, 0x32, 0xaa, 0xbb, 0xcc}),
+				encodeAttr(nl80211BSSFrequency, uint32Bytes(5180)),
+				encodeAttr(nl80211BSSCapability, []byte{0x11, 0x04}),
+				encodeAttr(nl80211BSSSignalMBM, int32Bytes(-5500)),
+				encodeAttr(nl80211BSSStatus, uint32Bytes(nl80211BSSStatusAssociated)),
+				encodeAttr(nl80211BSSSeenMsAgo, uint32Bytes(1500)),
+				encodeAttr(nl80211BSSInformationElems, recordedBeaconIEs)),
+		),
+		genlMessage(fakeFamilyID, nlmFMulti, 1, nl80211CmdNewScanResults,
+			encodeAttr(nl80211AttrIfindex, uint32Bytes(fakeIfindex)),
+			nestAttrs(nl80211AttrBSS,
+				encodeAttr(nl80211BSSBSSID, []byte{0x00, 0x11, 0x32, 0xaa, 0xbb, 0xcd}))),
+	}, {
+		genlMessage(fakeFamilyID, nlmFMulti, 1, nl80211CmdNewScanResults,
+			encodeAttr(nl80211AttrIfindex, uint32Bytes(fakeIfindex)),
+			nestAttrs(nl80211AttrBSS,
+				encodeAttr(nl80211BSSBSSID, []byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}),
+				encodeAttr(nl80211BSSFrequency, uint32Bytes(2437)),
+				encodeAttr(nl80211BSSSignalUnspec, []byte{60}))),
+		doneMessage(),
+	}}
+}
+
+// newFakeNetlink replays a successful scan; the trigger is acknowledged and followed
+// by a notification for another interface, then the one for the scanned interface
+func newFakeNetlink() *fakeNetlink {
+	return &fakeNetlink{replies: map[uint8][][]netlinkMessage{
+		ctrlCmdGetFamily:       recordedFamily(),
+		nl80211CmdGetInterface: recordedInterfaces(),
+		nl80211CmdTriggerScan: {
+			{ackMessage(0)},
+			{recordedScanNotification(nl80211CmdNewScanResults, 4)},
+			{recordedScanNotification(nl80211CmdNewScanResults, fakeIfindex)},
+		},
+		nl80211CmdGetSurvey: recordedSurvey(),
+		nl80211CmdGetScan:   recordedScan(),
+	}}
+}
+
+func scanFake(t *testing.T, fake *fakeNetlink) ([]WiFiNetwork, *NL80211Scanner, error) {
+	t.Helper()
+	scanner := &NL80211Scanner{dial: func() (netlinkConn, error) { return fake, nil }}
+	networks, err := scanner.Scan(context.Background())
+	return networks, scanner, err
+}
+
+func TestNL80211ScanDecodesBSS(t *testing.T) {
+	fake := newFakeNetlink()
+	networks, scanner, err := scanFake(t, fake)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	if _, iface := scanner.Source(); iface != "wlp2s0" {
+		t.Errorf("interface = %q, want the station interface wlp2s0", iface)
+	}
+	if len(fake.joined) != 1 || fake.joined[0] != fakeScanGroup {
+		t.Errorf("joined groups %v, want [%d]", fake.joined, fakeScanGroup)
+	}
+	if len(networks) != 2 {
+		t.Fatalf("got %d networks, want 2 (the BSS without a frequency is skipped)", len(networks))
+	}
+
+	office := networks[0]
+	checks := []struct {
+		field     string
+		got, want interface{}
+	}{
+		{"BSSID", office.BSSID, "00:11:32:aa:bb:cc"},
+		{"SSID", office.SSID, "Office"},
+		{"Frequency", office.Frequency, 5180},
+		{"Band", office.Band, "5G"},
+		{"Channel", office.Channel, 36},
+		{"Signal", office.Signal, -55},
+		{"Noise", office.Noise, -97},
+		{"SNR", office.SNR, 42},
+		{"Security", office.Security, "WPA2 Personal"},
+		{"PHYMode", office.PHYMode, "802.11ac"},
+		{"ChannelWidth", office.ChannelWidth, "80MHz"},
+		{"CenterFrequency", office.CenterFrequency, 5210},
+		{"SecondaryOffset", office.SecondaryOffset, 1},
+		{"StationCount", office.StationCount, 7},
+		{"ChannelUtilization", office.ChannelUtilization, 50},
+		{"BSSLoad", office.BSSLoad, true},
+		{"NetworkType", office.NetworkType, "Infrastructure"},
+		{"Connected", office.Connected, true},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %v, want %v", check.field, check.got, check.want)
+		}
+	}
+	if age := time.Since(office.LastSeen); age < 1500*time.Millisecond || age > 5*time.Second {
+		t.Errorf("LastSeen is %v ago, want about 1.5s", age)
+	}
+
+	legacy := networks[1]
+	if legacy.Channel != 6 || legacy.Band != "2.4G" || legacy.SignalUnit != SignalPercent || legacy.Noise != 0 || legacy.Connected {
+		t.Errorf("2.4 GHz BSS decoded as channel %d, band %s, unit %v, noise %d, connected %v",
+			legacy.Channel, legacy.Band, legacy.SignalUnit, legacy.Noise, legacy.Connected)
+	}
+}
+
+func TestNL80211ScanWaitsForBusyScan(t *testing.T) {
+	fake := newFakeNetlink()
+	fake.replies[nl80211CmdTriggerScan] = [][]netlinkMessage{
+		{ackMessage(syscall.EBUSY)},
+		{recordedScanNotification(nl80211CmdNewScanResults, fakeIfindex)},
+	}
+
+	networks, _, err := scanFake(t, fake)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(networks) != 2 {
+		t.Errorf("got %d networks after waiting for the running scan, want 2", len(networks))
+	}
+}
+
+func TestNL80211ScanAborted(t *testing.T) {
+	fake := newFakeNetlink()
+	fake.replies[nl80211CmdTriggerScan] = [][]netlinkMessage{
+		{ackMessage(0)},
+		{recordedScanNotification(nl80211CmdScanAborted, fakeIfindex)},
+	}
+
+	if _, _, err := scanFake(t, fake); err == nil {
+		t.Fatal("Scan succeeded after the scan was aborted")
+	}
+	if last := fake.sent[len(fake.sent)-1]; last != nl80211CmdTriggerScan {
+		t.Errorf("sent command %d after the aborted scan", last)
+	}
+}
+
+func TestNL80211ScanUnprivilegedUsesCachedResults(t *testing.T) {
+	fake := newFakeNetlink()
+	fake.replies[nl80211CmdTriggerScan] = [][]netlinkMessage{{ackMessage(syscall.EPERM)}}
+
+	networks, _, err := scanFake(t, fake)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(networks) != 2 {
+		t.Errorf("got %d cached networks, want 2", len(networks))
+	}
+}
+
+func TestNL80211ScanErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     uint8
+		errno   syscall.Errno
+		want    error
+		wantMsg string
+	}{
+		{"dump not permitted", nl80211CmdGetScan, syscall.EPERM, ErrPermissionDenied, "scan dump"},
+		{"dump busy", nl80211CmdGetScan, syscall.EBUSY, ErrDeviceBusy, "scan dump"},
+		{"trigger on a downed interface", nl80211CmdTriggerScan, syscall.ENETDOWN, ErrRadioDisabled, "scan trigger"},
+		{"trigger on a blocked radio", nl80211CmdTriggerScan, errnoRFKill, ErrRadioDisabled, "scan trigger"},
+		{"interface gone", nl80211CmdTriggerScan, syscall.ENODEV, ErrNoInterface, "scan trigger"},
+		{"family missing", ctrlCmdGetFamily, syscall.ENOENT, ErrNoInterface, "not registered"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := newFakeNetlink()
+			fake.replies[test.cmd] = [][]netlinkMessage{{ackMessage(test.errno)}}
+
+			_, _, err := scanFake(t, fake)
+			if !errors.Is(err, test.want) {
+				t.Fatalf("Scan error = %v, want %v", err, test.want)
+			}
+			if !strings.Contains(err.Error(), test.wantMsg) {
+				t.Errorf("Scan error %q does not mention %q", err, test.wantMsg)
+			}
+		})
+	}
+}
+
+func TestNL80211ScanCancelled(t *testing.T) {
+	fake := newFakeNetlink()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	scanner := &NL80211Scanner{dial: func() (netlinkConn, error) { return fake, nil }}
+	if _, err := scanner.Scan(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Scan error = %v, want context.Canceled", err)
+	}
+}
+
+func TestParseNetlinkMessagesRoundTrip(t *testing.T) {
+	attrs := append(encodeAttr(nl80211AttrIfindex, uint32Bytes(fakeIfindex)), encodeAttr(nl80211AttrIfname, []byte("wlan0\x00"))...)
+	datagram := append(encodeGenlMessage(fakeFamilyID, nlmFMulti, 7, nl80211CmdGetInterface, attrs),
+		encodeGenlMessage(nlmsgDone, nlmFMulti, 7, 0, nil)...)
+
+	msgs, err := parseNetlinkMessages(datagram)
+	if err != nil {
+		t.Fatalf("parseNetlinkMessages: %v", err)
+	}
+	if len(msgs) != 2 || msgs[0].Type != fakeFamilyID || msgs[0].Seq != 7 || msgs[1].Type != nlmsgDone {
+		t.Fatalf("decoded %+v", msgs)
+	}
+	if !messageHasIfindex(msgs[0], fakeIfindex) {
+		t.Error("messageHasIfindex = false for the encoded interface")
+	}
+
+	if _, err := parseNetlinkMessages(datagram[:len(datagram)-30]); err == nil {
+		t.Error("truncated datagram parsed without error")
+	}
+	if _, err := parseAttrs([]byte{0xff, 0x00, 0x01, 0x00}); err == nil {
+		t.Error("attribute longer than its buffer parsed without error")
+	}
+}
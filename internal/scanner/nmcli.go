@@ -0,0 +1,270 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+// nmcliFields are the terse-mode columns requested from nmcli, in output order.
+// BANDWIDTH is only understood by newer nmcli releases and is dropped on older ones.
+var nmcliFields = []string{"IN-USE", "SSID", "BSSID", "MODE", "CHAN", "FREQ", "RATE", "SIGNAL", "BARS", "SECURITY", "WPA-FLAGS", "RSN-FLAGS", "BANDWIDTH"}
+
+// nmcliBarsQuality is a quality percentage within the range each number of BARS
+// stands for; nmcli draws 1 bar above 5%, 2 above 30%, 3 above 55% and 4 above 80%
+var nmcliBarsQuality = []int{0, 20, 45, 70, 90}
+
+// nmcliSecurityFlags maps the WPA-FLAGS/RSN-FLAGS tokens to NetworkManager's AP security flag bits
+var nmcliSecurityFlags = map[string]uint32{
+	"pair_wep40":      0x1,
+	"pair_wep104":     0x2,
+	"pair_tkip":       0x4,
+	"pair_ccmp":       0x8,
+	"group_wep40":     0x10,
+	"group_wep104":    0x20,
+	"group_tkip":      0x40,
+	"group_ccmp":      0x80,
+	"psk":             nmAPSecKeyMgmtPSK,
+	"802.1X":          nmAPSecKeyMgmt8021X,
+	"sae":             nmAPSecKeyMgmtSAE,
+	"owe":             nmAPSecKeyMgmtOWE,
+	"owe_tm":          nmAPSecKeyMgmtOWETM,
+	"eap_suite_b_192": nmAPSecKeyMgmtEAPB92,
+}
+
+// NmcliScanner implements WiFi scanning through NetworkManager's nmcli tool
+type NmcliScanner struct {
+	// Interface restricts the listing to one device; all WiFi devices are listed when empty
+	Interface string
+}
+
+// Scan lists the access points known to NetworkManager
+func (s *NmcliScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	fields := nmcliFields
+	output, err := runCommand(ctx, "nmcli", s.args(fields)...)
+	if err != nil {
+		if errors.Is(err, ErrToolMissing) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		// Older nmcli rejects unknown fields; retry without BANDWIDTH
+		fields = fields[:len(fields)-1]
+		output, err = runCommand(ctx, "nmcli", s.args(fields)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	networks, err := s.parseNmcliOutput(string(output), fields)
+	if err == nil && len(networks) == 0 {
+		// An empty list is also what nmcli prints with the radio switched off
+		if radio, radioErr := runCommand(ctx, "nmcli", "-t", "radio", "wifi"); radioErr == nil && strings.TrimSpace(string(radio)) == "disabled" {
+			return nil, fmt.Errorf("nmcli: %w", ErrRadioDisabled)
+		}
+	}
+	return networks, err
+}
+
+// Source reports the backend and the interface used by the last scan
+func (s *NmcliScanner) Source() (string, string) {
+	return "nmcli", s.Interface
+}
+
+// args builds the nmcli command line for the given fields
+func (s *NmcliScanner) args(fields []string) []string {
+	args := []string{"-t", "-f", strings.Join(fields, ","), "dev", "wifi", "list"}
+	if s.Interface != "" {
+		args = append(args, "ifname", s.Interface)
+	}
+	return args
+}
+
+// parseNmcliOutput parses nmcli terse output whose columns are the given fields
+func (s *NmcliScanner) parseNmcliOutput(output string, fields []string) ([]WiFiNetwork, error) {
+	var networks []WiFiNetwork
+
+	lines := strings.Split(output, "\n")
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		values := splitNmcliTerse(line)
+		if len(values) != len(fields) {
+			continue
+		}
+
+		row := make(map[string]string, len(fields))
+		for i, field := range fields {
+			row[field] = values[i]
+		}
+
+		network, err := s.createNmcliNetwork(row)
+		if err != nil {
+			continue
+		}
+
+		networks = append(networks, network)
+	}
+
+	// Calculate congestion scores
+	scoreCongestion(networks)
+
+	return networks, nil
+}
+
+// splitNmcliTerse splits a terse-mode line on unescaped colons.
+// nmcli escapes ':' as "\:" and '\' as "\\" inside values.
+func splitNmcliTerse(line string) []string {
+	var values []string
+	var current strings.Builder
+
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line):
+			i++
+			current.WriteByte(line[i])
+		case c == ':':
+			values = append(values, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	return append(values, current.String())
+}
+
+// createNmcliNetwork builds a WiFiNetwork from one parsed nmcli row keyed by field name.
+// Hidden networks are kept with an empty SSID, as the other backends report them,
+// since they occupy their channel all the same.
+func (s *NmcliScanner) createNmcliNetwork(row map[string]string) (WiFiNetwork, error) {
+	ssid := row["SSID"]
+	if ssid == "--" {
+		ssid = ""
+	}
+
+	frequency, err := nmcliFrequency(row["FREQ"], row["CHAN"])
+	if err != nil {
+		return WiFiNetwork{}, err
+	}
+
+	// SIGNAL is a 0-100 quality percentage, not dBm; BARS stands in when SIGNAL is missing
+	quality, err := strconv.Atoi(strings.TrimSpace(row["SIGNAL"]))
+	if err != nil {
+		bars, ok := nmcliBars(row["BARS"])
+		if !ok {
+			return WiFiNetwork{}, fmt.Errorf("missing signal")
+		}
+		quality = nmcliBarsQuality[bars]
+	}
+
+	var wpaFlags, rsnFlags uint32
+	for _, token := range strings.Fields(row["WPA-FLAGS"]) {
+		wpaFlags |= nmcliSecurityFlags[token]
+	}
+	for _, token := range strings.Fields(row["RSN-FLAGS"]) {
+		rsnFlags |= nmcliSecurityFlags[token]
+	}
+	var apFlags uint32
+	if security := strings.TrimSpace(row["SECURITY"]); security != "" && security != "--" {
+		apFlags |= nmAPFlagsPrivacy
+	}
+
+	channelWidth := "Unknown"
+	if bandwidth := leadingInt(row["BANDWIDTH"]); bandwidth > 0 {
+		channelWidth = fmt.Sprintf("%dMHz", bandwidth)
+	}
+
+	mode := row["MODE"]
+	switch mode {
+	case "Infra", "":
+		mode = "Infrastructure"
+	case "Ad-Hoc":
+		mode = "Ad-hoc"
+	}
+
+	bssid := strings.ToLower(row["BSSID"])
+	if bssid == "" {
+		bssid = "Unknown"
+	}
+
+	network := WiFiNetwork{
+		SSID:            ssid,
+		Frequency:       frequency,
+		CenterFrequency: frequency,
+		Security:        nmSecurity(apFlags, wpaFlags, rsnFlags),
+		PHYMode:         "Unknown", // nmcli does not expose the PHY generation
+		ChannelWidth:    channelWidth,
+		NetworkType:     mode,
+		BSSID:           bssid,
+		Vendor:          getVendorFromMAC(bssid),
+		MaxRate:         leadingInt(row["RATE"]),
+		Connected:       strings.TrimSpace(row["IN-USE"]) == "*",
+	}
+	if err := applyFrequency(&network); err != nil {
+		return WiFiNetwork{}, err
+	}
+	setSignal(&network, quality, SignalPercent)
+	applyStationEstimate(&network)
+	return network, nil
+}
+
+// nmcliFrequency returns the frequency from FREQ, checked against the channel
+// number in CHAN, or from CHAN alone when FREQ is missing. CHAN carries no band,
+// so on its own it is read as 2.4 or 5GHz.
+func nmcliFrequency(freq, chanText string) (int, error) {
+	frequency := leadingInt(freq)
+	number := leadingInt(chanText)
+	switch {
+	case frequency == 0 && number == 0:
+		return 0, fmt.Errorf("missing frequency")
+	case frequency == 0:
+		return channelOnlyFrequency(number)
+	case number == 0:
+		return frequency, nil
+	}
+
+	channel, err := spectrum.ChannelFromFrequency(frequency)
+	if err != nil {
+		return 0, err
+	}
+	if channel.Number != number {
+		return 0, fmt.Errorf("FREQ %d MHz is channel %d, not CHAN %d", frequency, channel.Number, number)
+	}
+	return frequency, nil
+}
+
+// nmcliBars counts the bars drawn in BARS: "▂▄▆_" in a UTF-8 locale and "*** "
+// otherwise, with "_" or a space for each missing one
+func nmcliBars(bars string) (int, bool) {
+	count, width := 0, 0
+	for _, r := range bars {
+		width++
+		if r != '_' && r != ' ' {
+			count++
+		}
+	}
+	if width != 4 {
+		return 0, false
+	}
+	return count, true
+}
+
+// leadingInt parses the integer prefix of values such as "5180 MHz" or "540 Mbit/s"
+func leadingInt(s string) int {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0
+	}
+	n, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitNmcliTerse(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"a:b:c", []string{"a", "b", "c"}},
+		{"", []string{""}},
+		{"::", []string{"", "", ""}},
+		{`00\:11\:32\:AA\:BB\:CC:Infra`, []string{"00:11:32:AA:BB:CC", "Infra"}},
+		{`Cafe\: Guest:5180 MHz`, []string{"Cafe: Guest", "5180 MHz"}},
+		{`back\\slash:x`, []string{`back\slash`, "x"}},
+		{`ends\\:x`, []string{`ends\`, "x"}},
+		{`\\\::x`, []string{`\:`, "x"}},
+		{`trailing\`, []string{`trailing\`}},
+		{"Café ▂▄▆_:y", []string{"Café ▂▄▆_", "y"}},
+	}
+
+	for _, test := range tests {
+		if got := splitNmcliTerse(test.line); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitNmcliTerse(%q) = %q, want %q", test.line, got, test.want)
+		}
+	}
+}
+
+// Recorded `nmcli -t -f <nmcliFields> dev wifi list` output
+const nmcliOutput = `*:Office:00\:11\:32\:AA\:BB\:CC:Infra:36:5180 MHz:540 Mbit/s:82:▂▄▆█:WPA2 WPA3:(none):pair_ccmp group_ccmp psk sae:80 MHz
+ :Cafe\: Guest:00\:11\:32\:AA\:BB\:CD:Infra:6:2437 MHz:54 Mbit/s:40:▂▄__:--:(none):(none):20 MHz
+ ::02\:00\:00\:00\:00\:01:Infra:1:2412 MHz:130 Mbit/s:30:▂___:WPA2:(none):pair_ccmp group_ccmp psk:20 MHz
+ :--:02\:00\:00\:00\:00\:02:Ad-Hoc:11:2462 MHz:11 Mbit/s:20:▂___:WPA1:pair_tkip group_tkip psk:(none):20 MHz
+ :Broken:02\:00\:00\:00\:00\:03:Infra:::54 Mbit/s:20:▂___:--:(none):(none):20 MHz
+ :Misaligned:02\:00\:00\:00\:00\:04:Infra:40:5180 MHz:54 Mbit/s:20:▂___:--:(none):(none):20 MHz
+ :Short:row
+`
+
+func TestParseNmcliOutput(t *testing.T) {
+	networks, err := (&NmcliScanner{}).parseNmcliOutput(nmcliOutput, nmcliFields)
+	if err != nil {
+		t.Fatalf("parseNmcliOutput: %v", err)
+	}
+	if len(networks) != 4 {
+		t.Fatalf("got %d networks, want 4 (rows without a frequency, with CHAN disagreeing with FREQ or with missing columns are skipped)", len(networks))
+	}
+
+	office := networks[0]
+	if office.SSID != "Office" || office.BSSID != "00:11:32:aa:bb:cc" || office.Channel != 36 || office.ChannelWidth != "80MHz" ||
+		office.MaxRate != 540 || office.Security != "WPA2/WPA3 Personal" || !office.Connected || office.SignalUnit != SignalPercent {
+		t.Errorf("Office parsed as %+v", office)
+	}
+
+	if cafe := networks[1]; cafe.SSID != "Cafe: Guest" || cafe.Security != "Open" || cafe.Connected {
+		t.Errorf("Cafe parsed as %+v", cafe)
+	}
+
+	// Hidden networks are listed with an empty SSID whether nmcli prints "" or "--"
+	for _, hidden := range networks[2:] {
+		if hidden.SSID != "" {
+			t.Errorf("hidden network %s has SSID %q", hidden.BSSID, hidden.SSID)
+		}
+	}
+	if adhoc := networks[3]; adhoc.NetworkType != "Ad-hoc" || adhoc.Security != "WPA Personal" || adhoc.Channel != 11 {
+		t.Errorf("ad-hoc network parsed as %+v", adhoc)
+	}
+}
+
+func TestParseNmcliOutputWithoutBandwidth(t *testing.T) {
+	// Older nmcli releases are asked for every field but BANDWIDTH
+	fields := nmcliFields[:len(nmcliFields)-1]
+	output := ` :Office:00\:11\:32\:AA\:BB\:CC:Infra:36:5180 MHz:540 Mbit/s:82:▂▄▆█:WPA2:(none):pair_ccmp group_ccmp psk` + "\n"
+
+	networks, err := (&NmcliScanner{}).parseNmcliOutput(output, fields)
+	if err != nil {
+		t.Fatalf("parseNmcliOutput: %v", err)
+	}
+	if len(networks) != 1 || networks[0].ChannelWidth != "Unknown" || networks[0].Security != "WPA2 Personal" {
+		t.Errorf("parsed %+v", networks)
+	}
+}
+
+func TestCreateNmcliNetworkChannelAndBars(t *testing.T) {
+	tests := []struct {
+		name         string
+		chanText     string
+		freq         string
+		signal, bars string
+		channel      int
+		frequency    int
+		quality      int // Native SIGNAL percentage, 0 when the row is rejected
+	}{
+		{"FREQ and CHAN agree", "149", "5745 MHz", "67", "▂▄▆_", 149, 5745, 67},
+		{"6GHz channel", "37", "6135 MHz", "67", "▂▄▆_", 37, 6135, 67},
+		{"CHAN without FREQ", "44", "", "67", "▂▄▆_", 44, 5220, 67},
+		{"FREQ without CHAN", "", "2437 MHz", "67", "▂▄▆_", 6, 2437, 67},
+		{"CHAN disagrees", "6", "2412 MHz", "67", "▂▄▆_", 0, 0, 0},
+		{"bars without signal", "36", "5180 MHz", "", "▂▄__", 36, 5180, 45},
+		{"ASCII bars without signal", "36", "5180 MHz", "--", "****", 36, 5180, 90},
+		{"no bars without signal", "36", "5180 MHz", "", "    ", 36, 5180, 0},
+		{"no signal at all", "36", "5180 MHz", "", "", 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		row := map[string]string{"SSID": "Office", "BSSID": "00:11:32:AA:BB:CC", "MODE": "Infra",
+			"CHAN": test.chanText, "FREQ": test.freq, "SIGNAL": test.signal, "BARS": test.bars}
+		network, err := (&NmcliScanner{}).createNmcliNetwork(row)
+		if test.channel == 0 {
+			if err == nil {
+				t.Errorf("%s: parsed %+v, want the row rejected", test.name, network)
+			}
+			continue
+		}
+		if err != nil || network.Channel != test.channel || network.Frequency != test.frequency || network.RawSignal != test.quality {
+			t.Errorf("%s: channel %d at %d MHz with %d%%, %v; want %d at %d MHz with %d%%",
+				test.name, network.Channel, network.Frequency, network.RawSignal, err, test.channel, test.frequency, test.quality)
+		}
+	}
+}
@@ -0,0 +1,522 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// Capture file magic numbers
+const (
+	pcapMagicMicro  = 0xa1b2c3d4
+	pcapMagicNano   = 0xa1b23c4d
+	pcapngBlockSHB  = 0x0a0d0d0a
+	pcapngByteOrder = 0x1a2b3c4d
+
+	pcapngBlockIDB      = 0x00000001
+	pcapngBlockObsolete = 0x00000002
+	pcapngBlockSPB      = 0x00000003
+	pcapngBlockISB      = 0x00000005
+	pcapngBlockEPB      = 0x00000006
+	pcapngOptionTSResol = 9
+)
+
+// Link-layer header types (https://www.tcpdump.org/linktypes.html)
+const (
+	linkTypeIEEE80211         = 105
+	linkTypeIEEE80211Radiotap = 127
+)
+
+// 802.11 management frame subtypes we build networks from
+const (
+	frameSubtypeProbeResponse = 5
+	frameSubtypeBeacon        = 8
+)
+
+// Radiotap flags
+const (
+	radiotapFlagFCS    = 0x10
+	radiotapFlagBadFCS = 0x40
+)
+
+// radiotapFields lists alignment and size of the radiotap fields preceding the noise field, indexed by present bit
+var radiotapFields = [...]struct{ align, size int }{
+	{8, 8}, // TSFT
+	{1, 1}, // Flags
+	{1, 1}, // Rate
+	{2, 4}, // Channel
+	{2, 2}, // FHSS
+	{1, 1}, // dBm antenna signal
+	{1, 1}, // dBm antenna noise
+}
+
+// PcapScanner builds the network list from beacons and probe responses in an
+// offline pcap or pcapng capture taken in monitor mode. The capture is a single
+// scan: Scan returns io.EOF once it has been read.
+type PcapScanner struct {
+	// Path is the capture file to read
+	Path string
+
+	done bool
+}
+
+// capturedFrame is a single 802.11 frame with its capture metadata
+type capturedFrame struct {
+	Timestamp time.Time
+	LinkType  int
+	Data      []byte
+}
+
+// radiotapInfo holds the radiotap fields used to describe a BSS
+type radiotapInfo struct {
+	Frequency int
+	Signal    int
+	Noise     int
+	HasSignal bool
+	HasNoise  bool
+	Flags     byte
+}
+
+// Scan reads the capture and returns one network per BSSID, using each BSS's most recent frame
+func (p *PcapScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	if p.done {
+		return nil, io.EOF
+	}
+
+	file, err := os.Open(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open capture: %v", err)
+	}
+	defer file.Close()
+
+	// Frames read before any timestamp in their section fall back to the file's modification time
+	var modTime time.Time
+	if info, err := file.Stat(); err == nil {
+		modTime = info.ModTime()
+	}
+
+	byBSSID := make(map[string]*WiFiNetwork)
+	var order []string
+
+	err = readCaptureFrames(file, func(frame capturedFrame) {
+		if frame.Timestamp.IsZero() {
+			frame.Timestamp = modTime
+		}
+		network, ok := parseBeaconFrame(frame)
+		if !ok {
+			return
+		}
+
+		existing, seen := byBSSID[network.BSSID]
+		if !seen {
+			order = append(order, network.BSSID)
+		} else if existing.LastSeen.After(network.LastSeen) {
+			return
+		}
+		byBSSID[network.BSSID] = &network
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capture %s: %v", p.Path, err)
+	}
+
+	var networks []WiFiNetwork
+	for _, bssid := range order {
+		network := *byBSSID[bssid]
+		networks = append(networks, network)
+	}
+
+	// Calculate congestion scores
+	scoreCongestion(networks)
+
+	p.done = true
+	return networks, nil
+}
+
+// Source reports the capture file as the scan's origin
+func (p *PcapScanner) Source() (string, string) {
+	return "pcap", ""
+}
+
+// maxCaptureRecord bounds a single record or block, so a corrupt length cannot exhaust memory
+const maxCaptureRecord = 16 << 20
+
+// readCaptureFrames detects the capture format and streams every frame it
+// contains to emit. Each frame's Data is freshly allocated and may be retained.
+// A truncated final record or block ends the capture without an error.
+func readCaptureFrames(r io.Reader, emit func(capturedFrame)) error {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return fmt.Errorf("file too short")
+	}
+
+	if binary.LittleEndian.Uint32(magic) == pcapngBlockSHB {
+		return readPcapng(br, emit)
+	}
+	return readPcap(br, emit)
+}
+
+// readPcap parses the classic libpcap format
+func readPcap(r io.Reader, emit func(capturedFrame)) error {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("truncated pcap header")
+	}
+
+	var order binary.ByteOrder
+	var nano bool
+	switch {
+	case binary.LittleEndian.Uint32(header) == pcapMagicMicro:
+		order = binary.LittleEndian
+	case binary.BigEndian.Uint32(header) == pcapMagicMicro:
+		order = binary.BigEndian
+	case binary.LittleEndian.Uint32(header) == pcapMagicNano:
+		order, nano = binary.LittleEndian, true
+	case binary.BigEndian.Uint32(header) == pcapMagicNano:
+		order, nano = binary.BigEndian, true
+	default:
+		return fmt.Errorf("not a pcap or pcapng file")
+	}
+
+	linkType := int(order.Uint32(header[20:24]) & 0xffff)
+
+	record := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(r, record); err != nil {
+			return readEnd(err)
+		}
+		sec := int64(order.Uint32(record[0:4]))
+		frac := int64(order.Uint32(record[4:8]))
+		capLen := int(order.Uint32(record[8:12]))
+		if capLen > maxCaptureRecord {
+			return nil // Corrupt final record
+		}
+
+		data := make([]byte, capLen)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return readEnd(err) // Truncated final record
+		}
+
+		if !nano {
+			frac *= 1000
+		}
+		emit(capturedFrame{
+			Timestamp: time.Unix(sec, frac),
+			LinkType:  linkType,
+			Data:      data,
+		})
+	}
+}
+
+// readEnd treats running out of input as the end of the capture
+func readEnd(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil
+	}
+	return err
+}
+
+// pcapngInterface holds the per-interface state needed to decode packet blocks
+type pcapngInterface struct {
+	linkType int
+	snapLen  int
+	unitsPer float64 // Timestamp units per second
+}
+
+// timestamp converts a block's split timestamp using the interface's resolution
+func (iface pcapngInterface) timestamp(high, low uint32) time.Time {
+	seconds := float64(uint64(high)<<32|uint64(low)) / iface.unitsPer
+	return time.Unix(0, int64(seconds*1e9))
+}
+
+// readPcapng parses the pcapng format, including multiple sections with differing byte order.
+//
+// Simple Packet Blocks carry no timestamp, so their frames take the section's
+// last known time: that of the latest Enhanced Packet, obsolete Packet or
+// Interface Statistics Block before them. An SPB preceding all of those is
+// emitted with a zero Timestamp.
+func readPcapng(r io.Reader, emit func(capturedFrame)) error {
+	var order binary.ByteOrder = binary.LittleEndian
+	var interfaces []pcapngInterface
+	var lastTime time.Time
+
+	header := make([]byte, 12)
+	for {
+		// The first 12 bytes hold the block type, length and, in a Section
+		// Header Block, the byte-order magic needed to read that length
+		if _, err := io.ReadFull(r, header); err != nil {
+			return readEnd(err)
+		}
+		blockType := binary.LittleEndian.Uint32(header[0:4])
+
+		// A Section Header Block resets byte order, the interface list and the last known time
+		if blockType == pcapngBlockSHB {
+			switch {
+			case binary.LittleEndian.Uint32(header[8:12]) == pcapngByteOrder:
+				order = binary.LittleEndian
+			case binary.BigEndian.Uint32(header[8:12]) == pcapngByteOrder:
+				order = binary.BigEndian
+			default:
+				return fmt.Errorf("invalid pcapng byte-order magic")
+			}
+			interfaces = nil
+			lastTime = time.Time{}
+		} else {
+			blockType = order.Uint32(header[0:4])
+		}
+
+		blockLen := int(order.Uint32(header[4:8]))
+		if blockLen < 12 || blockLen > maxCaptureRecord || blockLen%4 != 0 {
+			return nil // Corrupt final block
+		}
+		block := make([]byte, blockLen)
+		copy(block, header)
+		if _, err := io.ReadFull(r, block[12:]); err != nil {
+			return readEnd(err) // Truncated final block
+		}
+		body := block[8 : blockLen-4]
+
+		switch blockType {
+		case pcapngBlockIDB:
+			if len(body) < 8 {
+				continue
+			}
+			iface := pcapngInterface{
+				linkType: int(order.Uint16(body[0:2])),
+				snapLen:  int(order.Uint32(body[4:8])),
+				unitsPer: 1e6,
+			}
+			if resol, ok := pcapngOption(body[8:], pcapngOptionTSResol, order); ok && len(resol) >= 1 {
+				if resol[0]&0x80 == 0 {
+					iface.unitsPer = pow(10, int(resol[0]))
+				} else {
+					iface.unitsPer = pow(2, int(resol[0]&0x7f))
+				}
+			}
+			interfaces = append(interfaces, iface)
+
+		case pcapngBlockISB:
+			if len(body) < 12 {
+				continue
+			}
+			if ifaceID := int(order.Uint32(body[0:4])); ifaceID < len(interfaces) {
+				lastTime = interfaces[ifaceID].timestamp(order.Uint32(body[4:8]), order.Uint32(body[8:12]))
+			}
+
+		case pcapngBlockEPB, pcapngBlockObsolete:
+			if len(body) < 20 {
+				continue
+			}
+			var ifaceID int
+			if blockType == pcapngBlockEPB {
+				ifaceID = int(order.Uint32(body[0:4]))
+			} else {
+				ifaceID = int(order.Uint16(body[0:2]))
+			}
+			if ifaceID >= len(interfaces) {
+				continue
+			}
+			iface := interfaces[ifaceID]
+
+			lastTime = iface.timestamp(order.Uint32(body[4:8]), order.Uint32(body[8:12]))
+			capLen := int(order.Uint32(body[12:16]))
+			if capLen > len(body)-20 {
+				continue
+			}
+
+			emit(capturedFrame{
+				Timestamp: lastTime,
+				LinkType:  iface.linkType,
+				Data:      body[20 : 20+capLen],
+			})
+
+		case pcapngBlockSPB:
+			// Simple packet blocks always belong to the first interface
+			if len(body) < 4 || len(interfaces) == 0 {
+				continue
+			}
+			packet := body[4:]
+			if origLen := int(order.Uint32(body[0:4])); origLen < len(packet) {
+				packet = packet[:origLen]
+			}
+			if snap := interfaces[0].snapLen; snap > 0 && snap < len(packet) {
+				packet = packet[:snap]
+			}
+			emit(capturedFrame{Timestamp: lastTime, LinkType: interfaces[0].linkType, Data: packet})
+		}
+	}
+}
+
+// pcapngOption returns the value of the first option with the given code
+func pcapngOption(options []byte, code uint16, order binary.ByteOrder) ([]byte, bool) {
+	for len(options) >= 4 {
+		optCode := order.Uint16(options[0:2])
+		optLen := int(order.Uint16(options[2:4]))
+		if optCode == 0 || 4+optLen > len(options) {
+			return nil, false
+		}
+		if optCode == code {
+			return options[4 : 4+optLen], true
+		}
+		padded := 4 + (optLen+3)&^3
+		if padded > len(options) {
+			return nil, false
+		}
+		options = options[padded:]
+	}
+	return nil, false
+}
+
+// parseBeaconFrame turns a captured beacon or probe response into a WiFiNetwork
+func parseBeaconFrame(frame capturedFrame) (WiFiNetwork, bool) {
+	data := frame.Data
+	var rt radiotapInfo
+
+	switch frame.LinkType {
+	case linkTypeIEEE80211Radiotap:
+		var headerLen int
+		var ok bool
+		rt, headerLen, ok = parseRadiotap(data)
+		if !ok || rt.Flags&radiotapFlagBadFCS != 0 {
+			return WiFiNetwork{}, false
+		}
+		data = data[headerLen:]
+		if rt.Flags&radiotapFlagFCS != 0 && len(data) >= 4 {
+			data = data[:len(data)-4]
+		}
+	case linkTypeIEEE80211:
+	default:
+		return WiFiNetwork{}, false
+	}
+
+	// Management frame header is 24 bytes, plus 4 with an HT Control field
+	if len(data) < 24 {
+		return WiFiNetwork{}, false
+	}
+	frameType := (data[0] >> 2) & 0x3
+	subtype := data[0] >> 4
+	if frameType != 0 || (subtype != frameSubtypeBeacon && subtype != frameSubtypeProbeResponse) {
+		return WiFiNetwork{}, false
+	}
+	headerLen := 24
+	if data[1]&0x80 != 0 {
+		headerLen += 4
+	}
+
+	// Fixed fields: timestamp (8), beacon interval (2), capability (2)
+	if len(data) < headerLen+12 {
+		return WiFiNetwork{}, false
+	}
+	bssid := net.HardwareAddr(data[16:22]).String()
+	capability := binary.LittleEndian.Uint16(data[headerLen+10 : headerLen+12])
+	ies := data[headerLen+12:]
+
+	network := WiFiNetwork{
+		BSSID:       bssid,
+		Vendor:      getVendorFromMAC(bssid),
+		NetworkType: "Infrastructure",
+		Frequency:   rt.Frequency,
+		LastSeen:    frame.Timestamp,
+		PHYMode:     "Unknown",
+	}
+	if rt.Frequency != 0 {
+		if err := applyFrequency(&network); err != nil {
+			return WiFiNetwork{}, false
+		}
+	}
+
+	applyInformationElements(&network, ies, capability)
+
+	if network.Frequency == 0 {
+		// Without radiotap the DS Parameter Set channel is all there is
+		frequency, err := channelOnlyFrequency(network.Channel)
+		if err != nil {
+			return WiFiNetwork{}, false
+		}
+		network.Frequency = frequency
+		if err := applyFrequency(&network); err != nil {
+			return WiFiNetwork{}, false
+		}
+		// Recompute the block center now that the primary frequency is known
+		applyInformationElements(&network, ies, capability)
+	}
+
+	if rt.HasSignal {
+		setSignal(&network, rt.Signal, SignalDBm)
+	}
+	if rt.HasNoise {
+		network.Noise = rt.Noise
+		if rt.HasSignal {
+			network.SNR = rt.Signal - rt.Noise
+		}
+	}
+	applyStationEstimate(&network)
+
+	return network, true
+}
+
+// parseRadiotap decodes the radiotap fields up to antenna noise and returns the header length
+func parseRadiotap(data []byte) (radiotapInfo, int, bool) {
+	var info radiotapInfo
+	if len(data) < 8 || data[0] != 0 {
+		return info, 0, false
+	}
+
+	headerLen := int(binary.LittleEndian.Uint16(data[2:4]))
+	if headerLen < 8 || headerLen > len(data) {
+		return info, 0, false
+	}
+
+	present := binary.LittleEndian.Uint32(data[4:8])
+
+	// Skip any extended presence bitmaps; their fields follow the first namespace's
+	offset := 8
+	for word := present; word&(1<<31) != 0; {
+		if offset+4 > headerLen {
+			return info, 0, false
+		}
+		word = binary.LittleEndian.Uint32(data[offset : offset+4])
+		offset += 4
+	}
+
+	for bit, field := range radiotapFields {
+		if present&(1<<uint(bit)) == 0 {
+			continue
+		}
+		offset = (offset + field.align - 1) &^ (field.align - 1)
+		if offset+field.size > headerLen {
+			return info, headerLen, true
+		}
+		value := data[offset : offset+field.size]
+
+		switch bit {
+		case 1:
+			info.Flags = value[0]
+		case 3:
+			info.Frequency = int(binary.LittleEndian.Uint16(value[0:2]))
+		case 5:
+			info.Signal = int(int8(value[0]))
+			info.HasSignal = true
+		case 6:
+			info.Noise = int(int8(value[0]))
+			info.HasNoise = true
+		}
+		offset += field.size
+	}
+
+	return info, headerLen, true
+}
+
+// pow returns base raised to a small non-negative integer exponent
+func pow(base float64, exp int) float64 {
+	result := 1.0
+	for i := 0; i < exp; i++ {
+		result *= base
+	}
+	return result
+}
@@ -0,0 +1,226 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// radiotapBeacon builds a radiotap-framed beacon from 00:11:32:aa:bb:cc carrying recordedBeaconIEs
+func radiotapBeacon(signal int8) []byte {
+	radiotap := []byte{
+		0x00, 0x00, 0x10, 0x00, // Version, pad, length 16
+		0x6a, 0x00, 0x00, 0x00, // Present: flags, channel, antenna signal, antenna noise
+		0x00, 0x00, // Flags, alignment padding
+		0x3c, 0x14, 0x40, 0x01, // Channel: 5180 MHz, OFDM 5 GHz
+		byte(signal), 0xa1, // Signal, noise -95 dBm
+	}
+	header := []byte{
+		0x80, 0x00, 0x00, 0x00, // Beacon, duration
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // Destination
+		0x00, 0x11, 0x32, 0xaa, 0xbb, 0xcc, // Source
+		0x00, 0x11, 0x32, 0xaa, 0xbb, 0xcc, // BSSID
+		0x00, 0x00, // Sequence control
+		0, 0, 0, 0, 0, 0, 0, 0, // TSF timestamp
+		0x64, 0x00, // Beacon interval
+		0x11, 0x04, // Capability: ESS, privacy
+	}
+	frame := append(radiotap, header...)
+	return append(frame, recordedBeaconIEs...)
+}
+
+// pcapFile builds a little-endian microsecond pcap with a radiotap link type
+func pcapFile(records ...[]byte) []byte {
+	file := binary.LittleEndian.AppendUint32(nil, pcapMagicMicro)
+	file = append(file, 0x02, 0x00, 0x04, 0x00) // Version 2.4
+	file = append(file, make([]byte, 8)...)     // Timezone, sigfigs
+	file = binary.LittleEndian.AppendUint32(file, 65535)
+	file = binary.LittleEndian.AppendUint32(file, linkTypeIEEE80211Radiotap)
+	for _, record := range records {
+		file = append(file, record...)
+	}
+	return file
+}
+
+// pcapRecord builds a classic pcap record captured at the given time
+func pcapRecord(at time.Time, frame []byte) []byte {
+	record := binary.LittleEndian.AppendUint32(nil, uint32(at.Unix()))
+	record = binary.LittleEndian.AppendUint32(record, uint32(at.Nanosecond()/1000))
+	record = binary.LittleEndian.AppendUint32(record, uint32(len(frame)))
+	record = binary.LittleEndian.AppendUint32(record, uint32(len(frame)))
+	return append(record, frame...)
+}
+
+// pcapngBlock builds a little-endian pcapng block, padding the body to 32 bits
+func pcapngBlock(blockType uint32, body []byte) []byte {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	length := uint32(12 + len(body))
+	block := binary.LittleEndian.AppendUint32(nil, blockType)
+	block = binary.LittleEndian.AppendUint32(block, length)
+	block = append(block, body...)
+	return binary.LittleEndian.AppendUint32(block, length)
+}
+
+func pcapngSHB() []byte {
+	body := binary.LittleEndian.AppendUint32(nil, pcapngByteOrder)
+	body = append(body, 0x01, 0x00, 0x00, 0x00) // Version 1.0
+	body = append(body, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+	return pcapngBlock(pcapngBlockSHB, body)
+}
+
+// pcapngIDB declares a radiotap interface with nanosecond timestamps
+func pcapngIDB() []byte {
+	body := []byte{linkTypeIEEE80211Radiotap, 0x00, 0x00, 0x00}
+	body = binary.LittleEndian.AppendUint32(body, 0) // No snap length limit
+	body = append(body, pcapngOptionTSResol, 0x00, 0x01, 0x00, 9, 0, 0, 0)
+	body = append(body, 0, 0, 0, 0) // End of options
+	return pcapngBlock(pcapngBlockIDB, body)
+}
+
+func pcapngTimestamp(body []byte, at time.Time) []byte {
+	ns := uint64(at.UnixNano())
+	body = binary.LittleEndian.AppendUint32(body, uint32(ns>>32))
+	return binary.LittleEndian.AppendUint32(body, uint32(ns))
+}
+
+func pcapngEPB(at time.Time, frame []byte) []byte {
+	body := pcapngTimestamp(binary.LittleEndian.AppendUint32(nil, 0), at)
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(frame)))
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(frame)))
+	return pcapngBlock(pcapngBlockEPB, append(body, frame...))
+}
+
+func pcapngISB(at time.Time) []byte {
+	return pcapngBlock(pcapngBlockISB, pcapngTimestamp(binary.LittleEndian.AppendUint32(nil, 0), at))
+}
+
+func pcapngSPB(frame []byte) []byte {
+	body := binary.LittleEndian.AppendUint32(nil, uint32(len(frame)))
+	return pcapngBlock(pcapngBlockSPB, append(body, frame...))
+}
+
+// writeCapture writes data to a capture file in a temporary directory
+func writeCapture(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "capture")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPcapScanner(t *testing.T) {
+	first := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	last := first.Add(2 * time.Second)
+	truncated := pcapRecord(last.Add(time.Second), radiotapBeacon(-40))
+	path := writeCapture(t, pcapFile(
+		pcapRecord(first, radiotapBeacon(-60)),
+		pcapRecord(last, radiotapBeacon(-55)),
+		pcapRecord(first.Add(time.Second), radiotapBeacon(-70)),
+		truncated[:len(truncated)-10],
+	))
+
+	p := &PcapScanner{Path: path}
+	networks, err := p.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(networks) != 1 {
+		t.Fatalf("got %d networks, want 1", len(networks))
+	}
+
+	// The most recent complete frame describes the BSS
+	office := networks[0]
+	if !office.LastSeen.Equal(last) || office.Signal != -55 || office.Noise != -95 || office.SNR != 40 {
+		t.Errorf("Office seen at %v with signal %d, noise %d, SNR %d", office.LastSeen, office.Signal, office.Noise, office.SNR)
+	}
+	if office.SSID != "Office" || office.Channel != 36 || office.ChannelWidth != "80MHz" || office.Security != "WPA2 Personal" {
+		t.Errorf("Office decoded as %+v", office)
+	}
+
+	if _, err := p.Scan(context.Background()); !errors.Is(err, io.EOF) {
+		t.Errorf("second Scan error = %v, want io.EOF", err)
+	}
+}
+
+func TestReadPcapngSimplePacketBlockTimestamps(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	frame := radiotapBeacon(-55)
+	var capture []byte
+	for _, block := range [][]byte{
+		pcapngSHB(), pcapngIDB(),
+		pcapngSPB(frame), // Before any timestamp
+		pcapngEPB(base, frame),
+		pcapngSPB(frame), // Inherits the EPB's time
+		pcapngISB(base.Add(5 * time.Second)),
+		pcapngSPB(frame), // Inherits the ISB's time
+		pcapngSHB(), pcapngIDB(),
+		pcapngSPB(frame), // A new section forgets the previous one's time
+	} {
+		capture = append(capture, block...)
+	}
+
+	var got []time.Time
+	// Reading a byte at a time checks that blocks are streamed rather than sliced from a buffer
+	err := readCaptureFrames(iotest.OneByteReader(bytes.NewReader(capture)), func(frame capturedFrame) {
+		got = append(got, frame.Timestamp)
+	})
+	if err != nil {
+		t.Fatalf("readCaptureFrames: %v", err)
+	}
+
+	want := []time.Time{{}, base, base, base.Add(5 * time.Second), {}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d frames, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("frame %d timestamp = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPcapScannerUntimedFramesUseModTime(t *testing.T) {
+	path := writeCapture(t, append(append(pcapngSHB(), pcapngIDB()...), pcapngSPB(radiotapBeacon(-55))...))
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	networks, err := (&PcapScanner{Path: path}).Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(networks) != 1 || !networks[0].LastSeen.Equal(modTime) {
+		t.Errorf("networks %+v, want one seen at the file's modification time", networks)
+	}
+}
+
+func TestReadCaptureFramesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short", []byte{0xd4, 0xc3}},
+		{"unknown magic", make([]byte, 24)},
+		{"truncated pcap header", pcapFile()[:20]},
+		{"bad byte order", pcapngBlock(pcapngBlockSHB, make([]byte, 16))},
+	}
+
+	for _, test := range tests {
+		err := readCaptureFrames(bytes.NewReader(test.data), func(capturedFrame) {})
+		if err == nil {
+			t.Errorf("%s: readCaptureFrames succeeded", test.name)
+		}
+	}
+}
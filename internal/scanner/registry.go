@@ -0,0 +1,378 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// Capability flags describe which optional WiFiNetwork fields a backend fills from measured data
+type Capability uint
+
+const (
+	CapNoise        Capability = 1 << iota // Noise floor and SNR
+	CapChannelWidth                        // Operating channel width
+	CapSecurity                            // Security type beyond open/encrypted
+	CapStationCount                        // Station count from BSS Load rather than an estimate
+)
+
+// capabilityNames lists the capability flags in display order
+var capabilityNames = []struct {
+	cap  Capability
+	name string
+}{
+	{CapNoise, "noise"},
+	{CapChannelWidth, "width"},
+	{CapSecurity, "security"},
+	{CapStationCount, "stations"},
+}
+
+// String returns the capability names separated by commas, or "-" when none are set
+func (c Capability) String() string {
+	var names []string
+	for _, entry := range capabilityNames {
+		if c&entry.cap != 0 {
+			names = append(names, entry.name)
+		}
+	}
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ",")
+}
+
+// Backend describes a named scanner implementation
+type Backend struct {
+	Name         string
+	Description  string
+	Platforms    []string // GOOS values the backend runs on; empty for all
+	Auto         bool     // Part of the default chain on its platforms
+	Capabilities Capability
+	Signal       SignalUnit // Usual native signal unit; drivers without dBm support report percent
+
+	// New creates the scanner. arg is the text after "name:" in a backend spec,
+	// an interface name for live backends or a file path for offline ones.
+	New func(arg string) (Scanner, error)
+}
+
+// Supported reports whether the backend runs on the current operating system
+func (b Backend) Supported() bool {
+	if len(b.Platforms) == 0 {
+		return true
+	}
+	for _, platform := range b.Platforms {
+		if platform == runtime.GOOS {
+			return true
+		}
+	}
+	return false
+}
+
+// registry holds the known backends in default preference order
+var registry = []Backend{
+	{
+		Name:         "nl80211",
+		Signal:       SignalDBm,
+		Description:  "kernel nl80211 over generic netlink",
+		Platforms:    []string{"linux"},
+		Auto:         true,
+		Capabilities: CapNoise | CapChannelWidth | CapSecurity | CapStationCount,
+		New: func(arg string) (Scanner, error) {
+			return &NL80211Scanner{Interface: arg}, nil
+		},
+	},
+	{
+		Name:         "iw",
+		Signal:       SignalDBm,
+		Description:  "iw scan dump",
+		Platforms:    []string{"linux"},
+		Auto:         true,
+		Capabilities: CapChannelWidth | CapSecurity | CapStationCount,
+		New: func(arg string) (Scanner, error) {
+			return &IwScanner{Interface: arg}, nil
+		},
+	},
+	{
+		Name:         "networkmanager",
+		Signal:       SignalPercent,
+		Description:  "NetworkManager D-Bus API",
+		Platforms:    []string{"linux"},
+		Auto:         true,
+		Capabilities: CapChannelWidth | CapSecurity,
+		New: func(arg string) (Scanner, error) {
+			return &NetworkManagerScanner{Interface: arg}, nil
+		},
+	},
+	{
+		Name:         "nmcli",
+		Signal:       SignalPercent,
+		Description:  "NetworkManager nmcli tool",
+		Platforms:    []string{"linux"},
+		Auto:         true,
+		Capabilities: CapChannelWidth | CapSecurity,
+		New: func(arg string) (Scanner, error) {
+			return &NmcliScanner{Interface: arg}, nil
+		},
+	},
+	{
+		Name:         "wpa_supplicant",
+		Signal:       SignalDBm,
+		Description:  "wpa_supplicant control socket",
+		Platforms:    []string{"linux"},
+		Auto:         true,
+		Capabilities: CapNoise | CapChannelWidth | CapSecurity | CapStationCount,
+		New: func(arg string) (Scanner, error) {
+			return &WPASupplicantScanner{Interface: arg}, nil
+		},
+	},
+	{
+		Name:        "iwlist",
+		Signal:      SignalDBm,
+		Description: "wireless-tools iwlist (requires sudo)",
+		Platforms:   []string{"linux"},
+		Auto:        true,
+		New: func(arg string) (Scanner, error) {
+			return &IwlistScanner{Interface: arg}, nil
+		},
+	},
+	{
+		Name:         "multi",
+		Description:  "every wireless interface concurrently, merged by BSSID (multi:BACKEND+BACKEND)",
+		Platforms:    []string{"linux"},
+		Capabilities: CapNoise | CapChannelWidth | CapSecurity | CapStationCount,
+		New: func(arg string) (Scanner, error) {
+			var backends []string
+			if arg != "" {
+				backends = strings.Split(arg, "+")
+			}
+			return &MultiRadioScanner{Backends: backends}, nil
+		},
+	},
+	{
+		Name:        "airport",
+		Signal:      SignalDBm,
+		Description: "macOS airport utility",
+		Platforms:   []string{"darwin"},
+		Auto:        true,
+		New: func(arg string) (Scanner, error) {
+			return &AirportScanner{}, nil
+		},
+	},
+	{
+		Name:         "system_profiler",
+		Signal:       SignalDBm,
+		Description:  "macOS system_profiler SPAirPortDataType",
+		Platforms:    []string{"darwin"},
+		Auto:         true,
+		Capabilities: CapNoise | CapChannelWidth | CapSecurity,
+		New: func(arg string) (Scanner, error) {
+			return &SystemProfilerScanner{}, nil
+		},
+	},
+	{
+		Name:         "pcap",
+		Signal:       SignalDBm,
+		Description:  "beacons from a pcap/pcapng capture (pcap:FILE)",
+		Capabilities: CapNoise | CapChannelWidth | CapSecurity | CapStationCount,
+		New: func(arg string) (Scanner, error) {
+			if arg == "" {
+				return nil, fmt.Errorf("pcap backend requires a file, e.g. pcap:capture.pcapng")
+			}
+			return &PcapScanner{Path: arg}, nil
+		},
+	},
+	{
+		Name:         "simulate",
+		Signal:       SignalDBm,
+		Description:  "synthetic RF environment from a scenario (simulate:FILE or built-in name)",
+		Capabilities: CapNoise | CapChannelWidth | CapSecurity | CapStationCount,
+		New: func(arg string) (Scanner, error) {
+			if arg == "" {
+				return nil, fmt.Errorf("simulate backend requires a scenario, e.g. simulate:%s", strings.Join(ScenarioNames(), " or simulate:"))
+			}
+			return &SimulatedScanner{Path: arg}, nil
+		},
+	},
+	{
+		Name:        "replay",
+		Description: "recorded session at original speed (replay:FILE)",
+		New: func(arg string) (Scanner, error) {
+			if arg == "" {
+				return nil, fmt.Errorf("replay backend requires a file, e.g. replay:session.jsonl")
+			}
+			return &ReplayScanner{Path: arg, Speed: 1}, nil
+		},
+	},
+}
+
+// RegisterBackend adds a backend to the registry after the built-in ones
+func RegisterBackend(backend Backend) error {
+	if backend.Name == "" || backend.New == nil {
+		return fmt.Errorf("backend needs a name and a constructor")
+	}
+	if _, ok := LookupBackend(backend.Name); ok {
+		return fmt.Errorf("backend %q is already registered", backend.Name)
+	}
+	registry = append(registry, backend)
+	return nil
+}
+
+// Backends returns every registered backend in default preference order
+func Backends() []Backend {
+	backends := make([]Backend, len(registry))
+	copy(backends, registry)
+	return backends
+}
+
+// LookupBackend finds a backend by name
+func LookupBackend(name string) (Backend, bool) {
+	for _, backend := range registry {
+		if backend.Name == name {
+			return backend, true
+		}
+	}
+	return Backend{}, false
+}
+
+// DefaultBackendSpecs returns the automatic backends for the current operating system in preference order
+func DefaultBackendSpecs() []string {
+	var specs []string
+	for _, backend := range registry {
+		if backend.Auto && backend.Supported() {
+			specs = append(specs, backend.Name)
+		}
+	}
+	return specs
+}
+
+// ParseBackendSpecs splits a comma-separated backend list such as "nl80211:wlan0,iw"
+func ParseBackendSpecs(list string) []string {
+	var specs []string
+	for _, spec := range strings.Split(list, ",") {
+		if spec = strings.TrimSpace(spec); spec != "" {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// BackendError reports why a single backend failed
+type BackendError struct {
+	Backend string
+	Arg     string // Interface or file the backend was given, if any
+	Err     error
+}
+
+func (e *BackendError) Error() string {
+	if e.Arg != "" {
+		return e.Backend + " (" + e.Arg + "): " + e.Err.Error()
+	}
+	return e.Backend + ": " + e.Err.Error()
+}
+
+func (e *BackendError) Unwrap() error { return e.Err }
+
+// chainEntry is one configured backend in a ChainScanner
+type chainEntry struct {
+	name    string
+	arg     string
+	scanner Scanner
+}
+
+// ChainScanner tries an ordered list of backends and returns the first successful scan
+type ChainScanner struct {
+	// OnFailure, when set, is called for each backend that failed before a later one succeeded.
+	// When every backend fails the failures are returned together from Scan instead.
+	OnFailure func(err *BackendError)
+	// Calibration offsets the signal of each scan by the interface it was taken on
+	Calibration Calibration
+
+	entries []chainEntry
+	backend string
+	iface   string
+}
+
+// NewChainScanner builds a chain from backend specs of the form "name" or "name:arg"
+func NewChainScanner(specs []string) (*ChainScanner, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no scanner backends configured")
+	}
+
+	chain := &ChainScanner{}
+	for _, spec := range specs {
+		name, arg, _ := strings.Cut(spec, ":")
+
+		backend, ok := LookupBackend(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown scanner backend %q", name)
+		}
+		if !backend.Supported() {
+			return nil, fmt.Errorf("scanner backend %q is not available on %s", name, runtime.GOOS)
+		}
+
+		scanner, err := backend.New(arg)
+		if err != nil {
+			return nil, err
+		}
+		chain.entries = append(chain.entries, chainEntry{name: name, arg: arg, scanner: scanner})
+	}
+
+	return chain, nil
+}
+
+// Scan tries each backend in order. A backend reporting io.EOF ends the chain,
+// since an exhausted offline source has nothing more to fall back to, and so
+// does cancellation of ctx.
+func (c *ChainScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	var failures []error
+
+	for _, entry := range c.entries {
+		// A multi-radio backend calibrates each radio before merging
+		multi, isMulti := entry.scanner.(*MultiRadioScanner)
+		if isMulti {
+			multi.Calibration = c.Calibration
+		}
+
+		networks, err := entry.scanner.Scan(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			failures = append(failures, &BackendError{Backend: entry.name, Arg: entry.arg, Err: err})
+			continue
+		}
+
+		c.backend, c.iface = entry.name, entry.arg
+		if reporter, ok := entry.scanner.(SourceReporter); ok {
+			if backend, iface := reporter.Source(); backend != "" {
+				c.backend, c.iface = backend, iface
+			}
+		}
+		if !isMulti && c.Calibration.apply(networks, c.iface) {
+			scoreCongestion(networks)
+		}
+
+		if c.OnFailure != nil {
+			for _, failure := range failures {
+				c.OnFailure(failure.(*BackendError))
+			}
+		}
+		return networks, nil
+	}
+
+	if len(failures) == 1 {
+		return nil, failures[0]
+	}
+	return nil, fmt.Errorf("all scanner backends failed:\n%w", errors.Join(failures...))
+}
+
+// Source reports the backend and interface behind the last successful scan
+func (c *ChainScanner) Source() (string, string) {
+	return c.backend, c.iface
+}
@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewChainScannerSelectsBackends(t *testing.T) {
+	registerTestBackend(t, Backend{Name: "elsewhere", Platforms: []string{"plan9"}, New: func(arg string) (Scanner, error) {
+		return &radioScanner{iface: arg}, nil
+	}})
+
+	chain, err := NewChainScanner(ParseBackendSpecs(" pcap:capture.pcapng, replay:session.jsonl ,"))
+	if err != nil {
+		t.Fatalf("NewChainScanner: %v", err)
+	}
+	if len(chain.entries) != 2 || chain.entries[0].name != "pcap" || chain.entries[0].arg != "capture.pcapng" ||
+		chain.entries[1].scanner.(*ReplayScanner).Path != "session.jsonl" {
+		t.Errorf("chain entries %+v", chain.entries)
+	}
+
+	for _, specs := range [][]string{nil, {"fancy"}, {"elsewhere"}, {"pcap"}, {"replay:session.jsonl", "simulate"}} {
+		if _, err := NewChainScanner(specs); err == nil {
+			t.Errorf("NewChainScanner(%q) succeeded", specs)
+		}
+	}
+	if _, err := NewChainScanner([]string{"elsewhere"}); err == nil || !strings.Contains(err.Error(), runtime.GOOS) {
+		t.Errorf("unsupported backend error %v, want the operating system named", err)
+	}
+}
+
+func TestDefaultBackendSpecs(t *testing.T) {
+	// Automatic backends for this system, in registry order
+	var want []string
+	for _, backend := range Backends() {
+		if backend.Auto && backend.Supported() {
+			want = append(want, backend.Name)
+		}
+	}
+	if got := DefaultBackendSpecs(); !slices.Equal(got, want) {
+		t.Errorf("DefaultBackendSpecs() = %v, want %v", got, want)
+	}
+
+	if runtime.GOOS == "linux" {
+		want := []string{"nl80211", "iw", "networkmanager", "nmcli", "wpa_supplicant", "iwlist"}
+		if got := DefaultBackendSpecs(); !slices.Equal(got, want) {
+			t.Errorf("Linux default chain %v, want %v", got, want)
+		}
+	}
+	for _, name := range []string{"multi", "pcap", "simulate", "replay"} {
+		if slices.Contains(DefaultBackendSpecs(), name) {
+			t.Errorf("%s is in the default chain", name)
+		}
+	}
+}
+
+func TestRegisterBackend(t *testing.T) {
+	registerTestBackend(t, Backend{Name: "radio", New: func(arg string) (Scanner, error) { return &radioScanner{iface: arg}, nil }})
+	if backends := Backends(); backends[len(backends)-1].Name != "radio" {
+		t.Errorf("registered backend not last: %v", backends[len(backends)-1].Name)
+	}
+	for _, backend := range []Backend{
+		{Name: "radio", New: func(arg string) (Scanner, error) { return nil, nil }},
+		{Name: "iw", New: func(arg string) (Scanner, error) { return nil, nil }},
+		{Name: "unbuilt"},
+	} {
+		if err := RegisterBackend(backend); err == nil {
+			t.Errorf("RegisterBackend(%q) succeeded", backend.Name)
+		}
+	}
+
+	if got := (CapNoise | CapStationCount).String(); got != "noise,stations" {
+		t.Errorf("capabilities %q", got)
+	}
+	if got := Capability(0).String(); got != "-" {
+		t.Errorf("no capabilities %q", got)
+	}
+}
+
+func TestChainScannerReportsFailures(t *testing.T) {
+	office := []WiFiNetwork{{SSID: "Office", BSSID: "00:11:32:aa:bb:cc", Band: "5G", Channel: 36, Frequency: 5180, Signal: -50}}
+	registerRadios(t, map[string]func() ([]WiFiNetwork, error){
+		"wlan0":  func() ([]WiFiNetwork, error) { return office, nil },
+		"busy":   func() ([]WiFiNetwork, error) { return nil, ErrDeviceBusy },
+		"denied": func() ([]WiFiNetwork, error) { return nil, ErrPermissionDenied },
+		"done":   func() ([]WiFiNetwork, error) { return nil, io.EOF },
+	})
+
+	tests := []struct {
+		name     string
+		specs    []string
+		failures []string // Reported through OnFailure
+		err      []error  // Returned from Scan, nil on success
+	}{
+		{"first succeeds", []string{"radio:wlan0", "radio:busy"}, nil, nil},
+		{"fallback", []string{"radio:busy", "radio:denied", "radio:wlan0"},
+			[]string{"radio (busy): " + ErrDeviceBusy.Error(), "radio (denied): " + ErrPermissionDenied.Error()}, nil},
+		{"single failure", []string{"radio:busy"}, nil, []error{ErrDeviceBusy}},
+		{"every backend fails", []string{"radio:busy", "radio:denied"}, nil, []error{ErrDeviceBusy, ErrPermissionDenied}},
+		// An exhausted offline source ends the chain without trying the rest
+		{"end of source", []string{"radio:done", "radio:wlan0"}, nil, []error{io.EOF}},
+	}
+
+	for _, test := range tests {
+		chain, err := NewChainScanner(test.specs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var failures []string
+		chain.OnFailure = func(err *BackendError) { failures = append(failures, err.Error()) }
+
+		networks, err := chain.Scan(context.Background())
+		if !slices.Equal(failures, test.failures) {
+			t.Errorf("%s: OnFailure got %q, want %q", test.name, failures, test.failures)
+		}
+		if test.err == nil {
+			if err != nil || len(networks) != 1 {
+				t.Errorf("%s: Scan = %d networks, %v", test.name, len(networks), err)
+			}
+			if backend, iface := chain.Source(); backend != "radio" || iface != "wlan0" {
+				t.Errorf("%s: Source() = %s, %s", test.name, backend, iface)
+			}
+			continue
+		}
+		for _, want := range test.err {
+			if !errors.Is(err, want) {
+				t.Errorf("%s: Scan error %v, want %v", test.name, err, want)
+			}
+		}
+		var backendErr *BackendError
+		if len(test.err) > 1 && (!strings.HasPrefix(err.Error(), "all scanner backends failed") || !errors.As(err, &backendErr)) {
+			t.Errorf("%s: Scan error %q does not list each backend", test.name, err)
+		}
+	}
+
+	// Cancellation stops the chain too
+	chain, _ := NewChainScanner([]string{"radio:busy", "radio:wlan0"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := chain.Scan(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Scan with a cancelled context = %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// DetectCountry returns the regulatory country the kernel is applying, read from
+// `iw reg get`. "00" means the world domain, i.e. no country has been set. Other
+// operating systems do not expose it; they get an error wrapping errors.ErrUnsupported.
+func DetectCountry(ctx context.Context) (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", fmt.Errorf("%w: regulatory country detection on %s", errors.ErrUnsupported, runtime.GOOS)
+	}
+	output, err := runCommand(ctx, "iw", "reg", "get")
+	if err != nil {
+		return "", err
+	}
+	return parseRegCountry(output)
+}
+
+// parseRegCountry finds the global "country XX: DFS-..." line; self-managed
+// phys follow in their own sections and only count when there is no global one
+func parseRegCountry(output []byte) (string, error) {
+	country := ""
+	section := "global"
+
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "global" || strings.HasPrefix(line, "phy#"):
+			section = line
+		case strings.HasPrefix(line, "country "):
+			code, _, ok := strings.Cut(strings.TrimPrefix(line, "country "), ":")
+			if !ok || len(code) != 2 {
+				continue
+			}
+			if section == "global" {
+				return code, nil
+			}
+			if country == "" {
+				country = code
+			}
+		}
+	}
+
+	if country == "" {
+		return "", fmt.Errorf("iw reg get reported no country")
+	}
+	return country, nil
+}
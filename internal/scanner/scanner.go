@@ -1,83 +1,88 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"strings"
+
+	"github.com/svgreg/wifi-bander/internal/analyzer"
+	"github.com/svgreg/wifi-bander/internal/spectrum"
 )
 
-// ScanWiFiNetworks detects the operating system and calls the appropriate scanner
-func ScanWiFiNetworks() ([]WiFiNetwork, error) {
-	switch runtime.GOOS {
-	case "linux":
-		scanner := &LinuxScanner{}
-		return scanner.Scan()
-	case "darwin":
-		scanner := &MacOSScanner{}
-		return scanner.Scan()
-	default:
-		return nil, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+// ScanWiFiNetworks scans with the default backend chain for the current operating system
+func ScanWiFiNetworks(ctx context.Context) ([]WiFiNetwork, error) {
+	scanner, err := NewScanner()
+	if err != nil {
+		return nil, err
 	}
+	return scanner.Scan(ctx)
 }
 
-// channelToFrequency converts a WiFi channel number to frequency in MHz
-func channelToFrequency(channel int) int {
-	// 2.4GHz band (channels 1-14)
-	if channel >= 1 && channel <= 13 {
-		return 2412 + (channel-1)*5
+// NewScanner returns the default scanner for the current operating system: every
+// wireless interface scanned concurrently where supported, otherwise a single backend chain
+func NewScanner() (Scanner, error) {
+	specs := DefaultBackendSpecs()
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
-	if channel == 14 {
-		return 2484 // Special case for Japan
+	if multi, ok := LookupBackend("multi"); ok && multi.Supported() {
+		return &MultiRadioScanner{Backends: specs}, nil
 	}
+	return NewChainScanner(specs)
+}
 
-	// 5GHz band - comprehensive mapping
-	// UNII-1 Band (5.15-5.25 GHz) - channels 36, 40, 44, 48
-	if channel >= 36 && channel <= 48 {
-		return 5180 + (channel-36)*5
+// applyFrequency sets Channel and Band from the primary channel Frequency
+func applyFrequency(network *WiFiNetwork) error {
+	channel, err := spectrum.ChannelFromFrequency(network.Frequency)
+	if err != nil {
+		return err
 	}
+	network.Channel = channel.Number
+	network.Band = string(channel.Band)
+	return nil
+}
 
-	// UNII-2A Band (5.25-5.35 GHz) - channels 52, 56, 60, 64 (DFS required)
-	if channel >= 52 && channel <= 64 {
-		return 5260 + (channel-52)*5
+// channelOnlyFrequency converts a channel number from a source that reports no band.
+// Such sources predate 6GHz, so numbers up to 14 are 2.4GHz and the rest 5GHz.
+func channelOnlyFrequency(number int) (int, error) {
+	band := spectrum.Band5GHz
+	if number <= 14 {
+		band = spectrum.Band2GHz
 	}
+	return spectrum.Frequency(band, number)
+}
 
-	// UNII-2C Band (5.47-5.725 GHz) - channels 100-144 (DFS required)
-	if channel >= 100 && channel <= 144 {
-		return 5500 + (channel-100)*5
-	}
+// scoreCongestion sets each network's congestion score with the default scoring model
+func scoreCongestion(networks []WiFiNetwork) {
+	ScoreCongestion(networks, nil)
+}
 
-	// UNII-3 Band (5.725-5.875 GHz) - channels 149, 153, 157, 161, 165
-	if channel >= 149 && channel <= 165 {
-		return 5745 + (channel-149)*5
+// ScoreCongestion sets each network's congestion score from the spectrum all of them
+// occupy under a scoring model; a nil scorer means the default model
+func ScoreCongestion(networks []WiFiNetwork, scorer analyzer.Scorer) {
+	analyzerNetworks := make([]analyzer.WiFiNetwork, len(networks))
+	for i, network := range networks {
+		analyzerNetworks[i] = network
 	}
 
-	// UNII-4 Band (5.85-5.925 GHz) - channels 169, 173, 177 (newer allocation)
-	if channel >= 169 && channel <= 177 {
-		return 5845 + (channel-169)*5
+	occupancy := analyzer.NewOccupancy(analyzerNetworks)
+	for i := range networks {
+		networks[i].CongestionScore = analyzer.CalculateCongestionScore(networks[i], occupancy, scorer)
 	}
-
-	// Default fallback for unknown channels
-	return 2412
 }
 
-// updateChannelMap updates the channel tracking map with network information
-func updateChannelMap(channelMap map[int]*ChannelInfo, channel, signal int) {
-	if channelInfo, exists := channelMap[channel]; exists {
-		channelInfo.NetworkCount++
-		if signal > channelInfo.StrongestRSSI {
-			channelInfo.StrongestRSSI = signal
-		}
-	} else {
-		channelMap[channel] = &ChannelInfo{
-			Channel:       channel,
-			NetworkCount:  1,
-			StrongestRSSI: signal,
-		}
+// applyStationEstimate fills StationCount from the signal heuristic when the BSS did
+// not advertise a BSS Load element; BSSLoad stays false, flagging the count as a guess
+func applyStationEstimate(network *WiFiNetwork) {
+	if !network.BSSLoad {
+		network.StationCount = estimateStationCount(network.Signal, network.Frequency)
 	}
 }
 
-// estimateStationCount estimates the number of stations based on signal patterns
-func estimateStationCount(signal, channel int) int {
+// estimateStationCount guesses the number of stations from signal patterns. It is
+// only a fallback for BSSes without BSS Load and carries no real information.
+func estimateStationCount(signal, frequency int) int {
 	baseCount := 1
 
 	// Stronger signals might indicate more active networks
@@ -90,7 +95,7 @@ func estimateStationCount(signal, channel int) int {
 	}
 
 	// 2.4GHz tends to be more congested
-	if channel <= 14 {
+	if frequency < 5000 {
 		baseCount += 1
 	}
 
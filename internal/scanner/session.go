@@ -0,0 +1,235 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Session file identification. The first line of every session is a header;
+// each following line is one SessionRecord.
+const (
+	SessionFormat  = "wifi-bander-session"
+	SessionVersion = 1
+)
+
+// SessionHeader is the first line of a session file
+type SessionHeader struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+}
+
+// SessionRecord is a single recorded scan
+type SessionRecord struct {
+	Time      time.Time     `json:"time"`                // When the scan completed
+	Backend   string        `json:"backend"`             // Backend that produced the scan
+	Interface string        `json:"interface,omitempty"` // Interface scanned, when known
+	Networks  []WiFiNetwork `json:"networks"`
+}
+
+// Recorder appends scan results to a JSONL session file
+type Recorder struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewRecorder opens a session file for appending, writing the header when the file is new.
+// Appending to an existing session requires a matching format and version.
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session file: %v", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat session file: %v", err)
+	}
+
+	r := &Recorder{file: file, enc: json.NewEncoder(file)}
+
+	if info.Size() == 0 {
+		header := SessionHeader{Format: SessionFormat, Version: SessionVersion, Created: time.Now()}
+		if err := r.enc.Encode(header); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write session header: %v", err)
+		}
+		return r, nil
+	}
+
+	if _, err := readSessionHeader(json.NewDecoder(bufio.NewReader(file))); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot append to %s: %v", path, err)
+	}
+	return r, nil
+}
+
+// Record appends one scan to the session
+func (r *Recorder) Record(at time.Time, backend, iface string, networks []WiFiNetwork) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record := SessionRecord{Time: at, Backend: backend, Interface: iface, Networks: networks}
+	if record.Networks == nil {
+		record.Networks = []WiFiNetwork{}
+	}
+	if err := r.enc.Encode(record); err != nil {
+		return fmt.Errorf("failed to write session record: %v", err)
+	}
+	return nil
+}
+
+// Close closes the session file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// RecordingScanner wraps a Scanner and records every successful scan
+type RecordingScanner struct {
+	Scanner  Scanner
+	Recorder *Recorder
+	// OnError, when set, is called for each scan that could not be recorded. The scan
+	// is returned from Scan regardless, so a failing session file does not stop scanning.
+	OnError func(err error)
+}
+
+// Scan runs the wrapped scanner and appends its result to the session
+func (r *RecordingScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	networks, err := r.Scanner.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, iface := r.Source()
+	if err := r.Recorder.Record(time.Now(), backend, iface, networks); err != nil && r.OnError != nil {
+		r.OnError(err)
+	}
+	return networks, nil
+}
+
+// Source reports the wrapped scanner's source, or its type name when it cannot name one
+func (r *RecordingScanner) Source() (string, string) {
+	if reporter, ok := r.Scanner.(SourceReporter); ok {
+		return reporter.Source()
+	}
+	return fmt.Sprintf("%T", r.Scanner), ""
+}
+
+// ReadSession reads every record from a session. A truncated final record,
+// as left behind by an interrupted recording, is ignored.
+func ReadSession(r io.Reader) ([]SessionRecord, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	if _, err := readSessionHeader(dec); err != nil {
+		return nil, err
+	}
+
+	var records []SessionRecord
+	for {
+		var record SessionRecord
+		err := dec.Decode(&record)
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return records, nil
+		}
+		if err != nil {
+			return records, fmt.Errorf("record %d: %v", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+}
+
+// readSessionHeader decodes and validates the session header
+func readSessionHeader(dec *json.Decoder) (SessionHeader, error) {
+	var header SessionHeader
+	if err := dec.Decode(&header); err != nil {
+		return header, fmt.Errorf("invalid session header: %v", err)
+	}
+	if header.Format != SessionFormat {
+		return header, fmt.Errorf("not a session file (format %q)", header.Format)
+	}
+	if header.Version < 1 || header.Version > SessionVersion {
+		return header, fmt.Errorf("unsupported session version %d", header.Version)
+	}
+	return header, nil
+}
+
+// ReplayScanner plays back a recorded session, returning one record per Scan call.
+// Scan returns io.EOF once the session is exhausted.
+type ReplayScanner struct {
+	// Path is the session file to replay
+	Path string
+	// Speed scales the recorded gaps between scans: 1 is real time, 2 twice as fast; 0 replays without delay
+	Speed float64
+	// Loop restarts from the first record instead of returning io.EOF
+	Loop bool
+	// OnLoop, when set, is called each time Loop restarts the session, before its
+	// first record is returned again
+	OnLoop func()
+
+	records []SessionRecord
+	loaded  bool
+	next    int
+	current SessionRecord
+}
+
+// Scan waits for the recorded gap since the previous scan, scaled by Speed, and returns the next record's networks
+func (r *ReplayScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	if !r.loaded {
+		file, err := os.Open(r.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open session: %v", err)
+		}
+		records, err := ReadSession(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session %s: %v", r.Path, err)
+		}
+		r.records, r.loaded = records, true
+	}
+
+	if r.next >= len(r.records) {
+		if !r.Loop || len(r.records) == 0 {
+			return nil, io.EOF
+		}
+		r.next = 0
+		if r.OnLoop != nil {
+			r.OnLoop()
+		}
+	}
+
+	record := r.records[r.next]
+	if r.next > 0 && r.Speed > 0 {
+		if gap := record.Time.Sub(r.records[r.next-1].Time); gap > 0 {
+			if err := sleepContext(ctx, time.Duration(float64(gap)/r.Speed)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	r.next++
+	r.current = record
+
+	// Hand out a copy so callers may sort or modify the result
+	networks := make([]WiFiNetwork, len(record.Networks))
+	copy(networks, record.Networks)
+	for i := range networks {
+		if networks[i].LastSeen.IsZero() {
+			networks[i].LastSeen = record.Time
+		}
+	}
+	return networks, nil
+}
+
+// Source reports the backend and interface of the most recently replayed record
+func (r *ReplayScanner) Source() (string, string) {
+	return r.current.Backend, r.current.Interface
+}
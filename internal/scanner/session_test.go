@@ -0,0 +1,233 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeScanner returns its scans in order, then err
+type fakeScanner struct {
+	scans [][]WiFiNetwork
+	err   error
+}
+
+func (f *fakeScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	if len(f.scans) == 0 {
+		return nil, f.err
+	}
+	networks := f.scans[0]
+	f.scans = f.scans[1:]
+	return networks, nil
+}
+
+func (f *fakeScanner) Source() (string, string) {
+	return "fake", "wlan0"
+}
+
+func sessionScans() [][]WiFiNetwork {
+	seen := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return [][]WiFiNetwork{
+		{
+			{SSID: "Office", BSSID: "00:11:32:aa:bb:cc", Channel: 36, Frequency: 5180, Band: "5G", Signal: -55,
+				Security: "WPA2 Personal", LastSeen: seen, IEs: recordedBeaconIEs, SignalUnit: SignalDBm},
+			{SSID: "Cafe", BSSID: "00:11:32:aa:bb:cd", Channel: 6, Frequency: 2437, Band: "2.4G", Signal: -70,
+				Security: "Open", LastSeen: seen.Add(-time.Second), Radios: []RadioObservation{{Interface: "wlan1", Signal: -72}}},
+		},
+		{}, // A scan that found nothing
+		{
+			{SSID: "Office", BSSID: "00:11:32:aa:bb:cc", Channel: 36, Frequency: 5180, Band: "5G", Signal: -58,
+				Security: "WPA2 Personal", LastSeen: seen.Add(10 * time.Second)},
+		},
+	}
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	scans := sessionScans()
+
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	rec := &RecordingScanner{Scanner: &fakeScanner{scans: sessionScans(), err: ErrDeviceBusy}, Recorder: recorder}
+	for i := range scans {
+		if _, err := rec.Scan(context.Background()); err != nil {
+			t.Fatalf("recording scan %d: %v", i, err)
+		}
+	}
+	// Failed scans are not recorded
+	if _, err := rec.Scan(context.Background()); !errors.Is(err, ErrDeviceBusy) {
+		t.Fatalf("recording scan error = %v, want ErrDeviceBusy", err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadSession(file)
+	file.Close()
+	if err != nil {
+		t.Fatalf("ReadSession: %v", err)
+	}
+	if len(records) != len(scans) {
+		t.Fatalf("read %d records, want %d", len(records), len(scans))
+	}
+
+	replay := &ReplayScanner{Path: path, Speed: 1000}
+	for i, want := range scans {
+		got, err := replay.Scan(context.Background())
+		if err != nil {
+			t.Fatalf("replaying scan %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("scan %d replayed as %+v, want %+v", i, got, want)
+		}
+		if backend, iface := replay.Source(); backend != "fake" || iface != "wlan0" {
+			t.Errorf("scan %d source = %s/%s, want fake/wlan0", i, backend, iface)
+		}
+		if !replay.current.Time.Equal(records[i].Time) || records[i].Time.IsZero() {
+			t.Errorf("scan %d replayed at %v, recorded at %v", i, replay.current.Time, records[i].Time)
+		}
+	}
+	if _, err := replay.Scan(context.Background()); !errors.Is(err, io.EOF) {
+		t.Errorf("Scan after the last record error = %v, want io.EOF", err)
+	}
+}
+
+func TestReplayScannerTiming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	offsets := []time.Duration{0, 20 * time.Second, 60 * time.Second}
+	for i, offset := range offsets {
+		networks := []WiFiNetwork{{SSID: "Office", BSSID: "00:11:32:aa:bb:cc", Signal: -50 - i}}
+		if err := recorder.Record(start.Add(offset), "fake", "wlan0", networks); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recorder.Close()
+
+	// At 1000x the 60 s session takes 60 ms
+	loops := 0
+	replay := &ReplayScanner{Path: path, Speed: 1000, Loop: true, OnLoop: func() { loops++ }}
+	began := time.Now()
+	for i, offset := range append(offsets, offsets...) {
+		networks, err := replay.Scan(context.Background())
+		if err != nil {
+			t.Fatalf("scan %d: %v", i, err)
+		}
+		// Networks recorded without a LastSeen take the record's time
+		if len(networks) != 1 || !networks[0].LastSeen.Equal(start.Add(offset)) {
+			t.Fatalf("scan %d = %+v, want one network seen at +%v", i, networks, offset)
+		}
+	}
+	if loops != 1 {
+		t.Errorf("OnLoop called %d times in two passes, want once", loops)
+	}
+	// Looping restarts without waiting, so two passes take 120 ms
+	if elapsed := time.Since(began); elapsed < 120*time.Millisecond || elapsed > 5*time.Second {
+		t.Errorf("two passes took %v, want about 120ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	replay.Scan(ctx) // Wraps around to the first record
+	if _, err := replay.Scan(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Scan with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestRecorderAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	for i := 0; i < 2; i++ {
+		recorder, err := NewRecorder(path)
+		if err != nil {
+			t.Fatalf("NewRecorder #%d: %v", i+1, err)
+		}
+		if err := recorder.Record(time.Now(), "fake", "", nil); err != nil {
+			t.Fatal(err)
+		}
+		recorder.Close()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadSession(file)
+	file.Close()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("ReadSession = %d records, %v; want 2 records", len(records), err)
+	}
+
+	// An interrupted recording leaves a partial line that replay ignores
+	if err := os.WriteFile(path, append(mustReadFile(t, path), `{"time":"2026-03-01T12:00:00Z","netw`...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	replay := &ReplayScanner{Path: path}
+	for i := 0; i < 2; i++ {
+		if _, err := replay.Scan(context.Background()); err != nil {
+			t.Fatalf("replaying scan %d: %v", i, err)
+		}
+	}
+	if _, err := replay.Scan(context.Background()); !errors.Is(err, io.EOF) {
+		t.Errorf("Scan past the partial record error = %v, want io.EOF", err)
+	}
+
+	other := filepath.Join(t.TempDir(), "other.json")
+	if err := os.WriteFile(other, []byte(`{"format":"something-else","version":1}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRecorder(other); err == nil {
+		t.Error("NewRecorder appended to a file that is not a session")
+	}
+}
+
+func TestRecordingScannerKeepsUnrecordedScans(t *testing.T) {
+	recorder, err := NewRecorder(filepath.Join(t.TempDir(), "session.jsonl"))
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	recorder.Close() // Every Record now fails
+
+	var reported []error
+	rec := &RecordingScanner{
+		Scanner:  &fakeScanner{scans: sessionScans()},
+		Recorder: recorder,
+		OnError:  func(err error) { reported = append(reported, err) },
+	}
+	networks, err := rec.Scan(context.Background())
+	if err != nil || len(networks) != 2 {
+		t.Errorf("Scan = %d networks, %v; want the 2 scanned", len(networks), err)
+	}
+	if len(reported) != 1 {
+		t.Errorf("OnError called %d times, want once", len(reported))
+	}
+
+	// Without OnError the failure is ignored
+	rec.OnError = nil
+	if networks, err := rec.Scan(context.Background()); err != nil || networks == nil {
+		t.Errorf("Scan without OnError = %+v, %v", networks, err)
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
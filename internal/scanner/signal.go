@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SignalUnit is the native unit a backend reports signal strength in
+type SignalUnit string
+
+const (
+	SignalDBm     SignalUnit = "dBm"     // Received power
+	SignalPercent SignalUnit = "percent" // 0-100 strength with no defined power scale
+)
+
+// setSignal records a reading in its native unit and normalizes it: Signal in dBm
+// and Quality as a percentage derived from that dBm, the same way for every backend
+func setSignal(network *WiFiNetwork, value int, unit SignalUnit) {
+	network.RawSignal = value
+	network.SignalUnit = unit
+	network.Signal = signalToDBm(value, unit)
+	network.Quality = calculateQuality(network.Signal)
+}
+
+// signalToDBm converts a native reading to dBm. Percentages follow NetworkManager,
+// which maps -100..-40 dBm linearly onto 0-100%; other percentage sources
+// (nl80211's unspecified unit, wireless-extensions quality) approximate the same range.
+func signalToDBm(value int, unit SignalUnit) int {
+	if unit != SignalPercent {
+		return value
+	}
+	if value < 0 {
+		value = 0
+	}
+	if value > 100 {
+		value = 100
+	}
+	return -100 + value*60/100
+}
+
+// Calibration holds per-adapter offsets in dB that line up adapters whose RSSI
+// reads high or low. The "" entry applies to adapters without their own.
+type Calibration map[string]int
+
+// ParseCalibration parses offsets such as "wlan0=+3,wlan1=-2"; an entry without
+// an interface, such as "-2", applies to every other adapter
+func ParseCalibration(s string) (Calibration, error) {
+	calibration := make(Calibration)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		iface, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			iface, value = "", entry
+		}
+		offset, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "dB"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid calibration %q: offset must be whole dB, e.g. wlan0=+3", entry)
+		}
+		calibration[strings.TrimSpace(iface)] = offset
+	}
+	return calibration, nil
+}
+
+// Offset returns the offset for an interface, falling back to the "" entry
+func (c Calibration) Offset(iface string) int {
+	if offset, ok := c[iface]; ok {
+		return offset
+	}
+	return c[""]
+}
+
+// String formats the offsets as "wlan0=+3, wlan1=-2", or "none"
+func (c Calibration) String() string {
+	var entries []string
+	for iface, offset := range c {
+		if iface == "" {
+			iface = "default"
+		}
+		entries = append(entries, fmt.Sprintf("%s=%+d dB", iface, offset))
+	}
+	if len(entries) == 0 {
+		return "none"
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ", ")
+}
+
+// apply shifts the signal and noise of networks scanned on iface by its offset and
+// reports whether anything changed. An offset applied before, as in a recorded
+// session, is replaced rather than added to; SNR is unaffected since both shift.
+func (c Calibration) apply(networks []WiFiNetwork, iface string) bool {
+	offset := c.Offset(iface)
+	changed := false
+	for i := range networks {
+		network := &networks[i]
+		delta := offset - network.SignalOffset
+		if delta == 0 {
+			continue
+		}
+		network.Signal += delta
+		if network.Noise != 0 {
+			network.Noise += delta
+		}
+		network.SignalOffset = offset
+		network.Quality = calculateQuality(network.Signal)
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,129 @@
+package scanner
+
+import (
+	"testing"
+)
+
+func TestSignalToDBm(t *testing.T) {
+	tests := []struct {
+		value int
+		unit  SignalUnit
+		want  int
+	}{
+		{-52, SignalDBm, -52},
+		{-95, SignalDBm, -95},
+		{0, SignalPercent, -100},
+		{50, SignalPercent, -70},
+		{100, SignalPercent, -40},
+		{-5, SignalPercent, -100}, // Clamped to 0-100
+		{120, SignalPercent, -40},
+	}
+
+	for _, test := range tests {
+		if got := signalToDBm(test.value, test.unit); got != test.want {
+			t.Errorf("signalToDBm(%d, %s) = %d, want %d", test.value, test.unit, got, test.want)
+		}
+	}
+
+	// Quality comes from the dBm whatever the native unit
+	var percent, dBm WiFiNetwork
+	setSignal(&percent, 75, SignalPercent)
+	setSignal(&dBm, -55, SignalDBm)
+	if percent.Signal != -55 || percent.RawSignal != 75 || percent.SignalUnit != SignalPercent || percent.Quality != dBm.Quality {
+		t.Errorf("75%% = %+v, want -55 dBm with the quality of %+v", percent, dBm)
+	}
+}
+
+func TestParseIwlistSignal(t *testing.T) {
+	tests := []struct {
+		line string
+		raw  int
+		unit SignalUnit
+		want int // dBm
+	}{
+		{"Quality=42/70  Signal level=-68 dBm", -68, SignalDBm, -68},
+		{"Quality=60/100  Signal level=60/100", 60, SignalPercent, -64},
+		{"Quality:47/94  Signal level:47/94", 0, "", 0}, // Not the "level=" form
+		{"Quality=47/94  Signal level=47/94", 50, SignalPercent, -70},
+		{"Quality=0/100  Signal level=0/0", 0, "", 0}, // No scale
+	}
+
+	for _, test := range tests {
+		output := "wlan0     Scan completed :\n" +
+			"          Cell 01 - Address: 00:11:32:AA:BB:CC\n" +
+			"                    Channel:36\n" +
+			"                    Frequency:5.18 GHz (Channel 36)\n" +
+			"                    " + test.line + "  \n" +
+			"                    Encryption key:on\n" +
+			"                    ESSID:\"Office\"\n"
+		networks, err := (&IwlistScanner{}).parseIwlistOutput(output)
+		if err != nil || len(networks) != 1 {
+			t.Fatalf("%s: parsed %d networks, %v", test.line, len(networks), err)
+		}
+		network := networks[0]
+		if network.Channel != 36 || network.Frequency != 5180 {
+			t.Errorf("%s: channel %d at %d MHz, want 36 at 5180", test.line, network.Channel, network.Frequency)
+		}
+		if network.RawSignal != test.raw || network.SignalUnit != test.unit || network.Signal != test.want {
+			t.Errorf("%s: signal %d (%d %s), want %d (%d %s)",
+				test.line, network.Signal, network.RawSignal, network.SignalUnit, test.want, test.raw, test.unit)
+		}
+	}
+}
+
+func TestParseCalibration(t *testing.T) {
+	calibration, err := ParseCalibration(" wlan0=+3, wlan1=-2dB ,-1,")
+	if err != nil {
+		t.Fatalf("ParseCalibration: %v", err)
+	}
+	for iface, want := range map[string]int{"wlan0": 3, "wlan1": -2, "wlan2": -1, "": -1} {
+		if got := calibration.Offset(iface); got != want {
+			t.Errorf("Offset(%q) = %d, want %d", iface, got, want)
+		}
+	}
+	if got := calibration.String(); got != "default=-1 dB, wlan0=+3 dB, wlan1=-2 dB" {
+		t.Errorf("String() = %q", got)
+	}
+
+	if calibration, err := ParseCalibration(""); err != nil || len(calibration) != 0 || calibration.String() != "none" {
+		t.Errorf("ParseCalibration(\"\") = %v, %v", calibration, err)
+	}
+	for _, invalid := range []string{"wlan0", "wlan0=+1.5", "wlan0=3,wlan1=x"} {
+		if _, err := ParseCalibration(invalid); err == nil {
+			t.Errorf("ParseCalibration(%q) succeeded", invalid)
+		}
+	}
+}
+
+func TestCalibrationApplyReplacesOffset(t *testing.T) {
+	network := WiFiNetwork{BSSID: "00:11:32:aa:bb:cc", Noise: -95}
+	setSignal(&network, -60, SignalDBm)
+	networks := []WiFiNetwork{network}
+
+	if !(Calibration{"wlan0": 3}).apply(networks, "wlan0") {
+		t.Fatal("apply reported no change")
+	}
+	got := networks[0]
+	if got.Signal != -57 || got.Noise != -92 || got.SignalOffset != 3 || got.RawSignal != -60 || got.Quality != calculateQuality(-57) {
+		t.Errorf("calibrated by +3: %+v", got)
+	}
+
+	// Applying the same offset again leaves the scan alone, and another offset
+	// replaces it rather than adding to it
+	if (Calibration{"wlan0": 3}).apply(networks, "wlan0") {
+		t.Error("reapplying the same offset changed the scan")
+	}
+	if !(Calibration{"": -2}).apply(networks, "wlan0") {
+		t.Fatal("apply of a new offset reported no change")
+	}
+	if got := networks[0]; got.Signal != -62 || got.Noise != -97 || got.SignalOffset != -2 {
+		t.Errorf("recalibrated by -2: %+v, want -62 dBm over -97 dBm", got)
+	}
+
+	// Without a noise reading there is nothing to shift
+	quiet := []WiFiNetwork{{Signal: -60}}
+	Calibration{"": 5}.apply(quiet, "wlan1")
+	if quiet[0].Signal != -55 || quiet[0].Noise != 0 {
+		t.Errorf("calibrated without noise: %+v", quiet[0])
+	}
+}
@@ -0,0 +1,325 @@
+package scanner
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/spectrum"
+)
+
+// builtinScenarios are the example scenarios shipped with the binary, selectable by name
+//
+//go:embed scenarios/*.json
+var builtinScenarios embed.FS
+
+// Defaults applied to scenario fields left at zero
+const (
+	defaultPathLossExponent = 3.0  // Indoor residential/office propagation
+	defaultNoiseFloor       = -95  // dBm
+	defaultNoiseJitter      = 1.0  // dB standard deviation of the noise floor between scans
+	defaultSensitivity      = -92  // dBm, weakest beacon a typical client still reports
+	defaultTxPower          = 20.0 // dBm EIRP
+)
+
+// Scenario describes a synthetic RF environment: access points placed on a
+// floor plan in meters and the observer listening to them
+type Scenario struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	Observer Position `json:"observer"`
+
+	PathLossExponent float64 `json:"path_loss_exponent,omitempty"` // Log-distance exponent n; 2 is free space
+	ReferenceLoss    float64 `json:"reference_loss,omitempty"`     // Loss at 1 m in dB; free-space loss at the AP's frequency when zero
+	NoiseFloor       int     `json:"noise_floor,omitempty"`        // Noise floor in dBm
+	NoiseJitter      float64 `json:"noise_jitter,omitempty"`       // Noise floor standard deviation in dB
+	Jitter           float64 `json:"jitter,omitempty"`             // Per-scan signal standard deviation in dB (shadow fading)
+	Sensitivity      int     `json:"sensitivity,omitempty"`        // APs weaker than this (dBm) are not reported
+	Seed             int64   `json:"seed,omitempty"`               // Random seed for repeatable runs; zero seeds from the clock
+
+	AccessPoints []SimulatedAP `json:"access_points"`
+}
+
+// Position is a point on the scenario floor plan in meters
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z,omitempty"` // Height, e.g. 3 per floor
+}
+
+// SimulatedAP is one access point in a scenario
+type SimulatedAP struct {
+	SSID      string   `json:"ssid"`
+	BSSID     string   `json:"bssid,omitempty"` // Generated when empty
+	Position  Position `json:"position"`
+	TxPower   float64  `json:"tx_power,omitempty"`  // EIRP in dBm
+	WallLoss  float64  `json:"wall_loss,omitempty"` // Extra attenuation in dB between the AP and the observer
+	Band      string   `json:"band,omitempty"`      // "6G" for 6GHz channel numbering; otherwise inferred from the channel
+	Channel   int      `json:"channel"`
+	Width     string   `json:"width,omitempty"`     // "20MHz", "40MHz", "80MHz" or "160MHz"
+	Secondary string   `json:"secondary,omitempty"` // 2.4GHz 40MHz secondary channel: "above" or "below"
+	Security  string   `json:"security,omitempty"`  // Label as a live scan reports it, e.g. "WPA2 Personal" (the default) or "Open"
+	PHYMode   string   `json:"phy_mode,omitempty"`
+	Vendor    string   `json:"vendor,omitempty"` // Looked up from the BSSID when empty
+
+	// Advertised BSS Load; when both are zero the AP sends none and the count is estimated like a live scan
+	Stations    int `json:"stations,omitempty"`
+	Utilization int `json:"utilization,omitempty"` // Channel utilization percentage
+
+	channel spectrum.Channel // Resolved primary channel
+	block   spectrum.Block   // Spectrum occupied at the configured width
+}
+
+// SimulatedScanner produces networks from a scenario using a log-distance path-loss model
+type SimulatedScanner struct {
+	// Path is a scenario file, or the name of a built-in scenario such as "dense-apartment"
+	Path string
+
+	scenario *Scenario
+	rng      *rand.Rand
+}
+
+// Scan computes each access point's received signal at the observer with fresh jitter
+func (s *SimulatedScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if s.scenario == nil {
+		scenario, err := LoadScenario(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		seed := scenario.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		s.scenario, s.rng = scenario, rand.New(rand.NewSource(seed))
+	}
+
+	now := time.Now()
+	var networks []WiFiNetwork
+
+	for _, ap := range s.scenario.AccessPoints {
+		network, ok := s.scenario.observe(ap, s.rng, now)
+		if !ok {
+			continue
+		}
+		networks = append(networks, network)
+	}
+
+	// Calculate congestion scores
+	scoreCongestion(networks)
+
+	return networks, nil
+}
+
+// Source reports the scenario name as the interface
+func (s *SimulatedScanner) Source() (string, string) {
+	if s.scenario != nil && s.scenario.Name != "" {
+		return "simulate", s.scenario.Name
+	}
+	return "simulate", s.Path
+}
+
+// ScenarioNames lists the built-in scenarios
+func ScenarioNames() []string {
+	entries, _ := builtinScenarios.ReadDir("scenarios")
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadScenario reads a scenario from a file, falling back to the built-in scenario of that name
+func LoadScenario(name string) (*Scenario, error) {
+	if name == "" {
+		return nil, fmt.Errorf("no scenario given; built-in scenarios: %s", strings.Join(ScenarioNames(), ", "))
+	}
+
+	data, err := os.ReadFile(name)
+	if os.IsNotExist(err) && !strings.ContainsAny(name, `/\`) {
+		var embedErr error
+		data, embedErr = builtinScenarios.ReadFile(path.Join("scenarios", strings.TrimSuffix(name, ".json")+".json"))
+		if embedErr != nil {
+			return nil, fmt.Errorf("scenario %q is neither a file nor built in (built-in: %s)", name, strings.Join(ScenarioNames(), ", "))
+		}
+		err = nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %v", err)
+	}
+
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %v", name, err)
+	}
+	if err := scenario.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %v", name, err)
+	}
+	return &scenario, nil
+}
+
+// validate checks the access points and fills in defaults
+func (s *Scenario) validate() error {
+	if len(s.AccessPoints) == 0 {
+		return fmt.Errorf("no access points")
+	}
+	if s.PathLossExponent == 0 {
+		s.PathLossExponent = defaultPathLossExponent
+	}
+	if s.NoiseFloor == 0 {
+		s.NoiseFloor = defaultNoiseFloor
+	}
+	if s.NoiseJitter == 0 {
+		s.NoiseJitter = defaultNoiseJitter
+	}
+	if s.Sensitivity == 0 {
+		s.Sensitivity = defaultSensitivity
+	}
+
+	for i := range s.AccessPoints {
+		ap := &s.AccessPoints[i]
+		if ap.Width == "" {
+			ap.Width = "20MHz"
+		}
+		if err := ap.resolveChannel(); err != nil {
+			return fmt.Errorf("access point %d (%s): %v", i+1, ap.SSID, err)
+		}
+		if ap.TxPower == 0 {
+			ap.TxPower = defaultTxPower
+		}
+		if ap.BSSID == "" {
+			// Locally administered addresses cannot collide with a real vendor OUI
+			ap.BSSID = fmt.Sprintf("02:00:00:00:%02x:%02x", (i+1)>>8, (i+1)&0xff)
+		}
+		if ap.Security == "" {
+			ap.Security = "WPA2 Personal"
+		}
+	}
+	return nil
+}
+
+// resolveChannel validates the AP's band, channel and width and works out the occupied block
+func (ap *SimulatedAP) resolveChannel() error {
+	var err error
+	if ap.Band != "" {
+		var band spectrum.Band
+		if band, err = spectrum.ParseBand(ap.Band); err != nil {
+			return err
+		}
+		ap.channel, err = spectrum.NewChannel(band, ap.Channel)
+	} else {
+		var frequency int
+		if frequency, err = channelOnlyFrequency(ap.Channel); err == nil {
+			ap.channel, err = spectrum.ChannelFromFrequency(frequency)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	ap.Band = string(ap.channel.Band)
+
+	width, err := spectrum.ParseWidth(ap.Width)
+	if err != nil {
+		return err
+	}
+
+	secondary := 0
+	switch ap.Secondary {
+	case "above":
+		secondary = 1
+	case "below":
+		secondary = -1
+	case "":
+	default:
+		return fmt.Errorf("secondary must be \"above\" or \"below\", not %q", ap.Secondary)
+	}
+	ap.block, err = spectrum.BondedBlock(ap.channel, width, secondary)
+	return err
+}
+
+// observe returns the network as the observer would see it in one scan,
+// or false when the AP is below the scenario's sensitivity
+func (s *Scenario) observe(ap SimulatedAP, rng *rand.Rand, now time.Time) (WiFiNetwork, bool) {
+	frequency := ap.channel.Frequency()
+
+	signal := ap.TxPower - s.pathLoss(ap.Position, frequency) - ap.WallLoss + rng.NormFloat64()*s.Jitter
+	rssi := int(math.Round(signal))
+	if rssi < s.Sensitivity {
+		return WiFiNetwork{}, false
+	}
+	noise := int(math.Round(float64(s.NoiseFloor) + rng.NormFloat64()*s.NoiseJitter))
+
+	network := WiFiNetwork{
+		SSID:            ap.SSID,
+		Channel:         ap.Channel,
+		Band:            ap.Band,
+		Frequency:       frequency,
+		Security:        ap.Security,
+		PHYMode:         ap.PHYMode,
+		ChannelWidth:    ap.Width,
+		NetworkType:     "Infrastructure",
+		BSSID:           ap.BSSID,
+		Vendor:          ap.Vendor,
+		Noise:           noise,
+		SNR:             rssi - noise,
+		LastSeen:        now,
+		CenterFrequency: ap.block.Center,
+	}
+	setSignal(&network, rssi, SignalDBm)
+	if ap.block.Center > frequency {
+		network.SecondaryOffset = 1
+	} else if ap.block.Center < frequency {
+		network.SecondaryOffset = -1
+	}
+
+	if network.PHYMode == "" {
+		switch network.Band {
+		case "6G":
+			network.PHYMode = "802.11ax"
+		case "5G":
+			network.PHYMode = "802.11ac"
+		default:
+			network.PHYMode = "802.11n"
+		}
+	}
+	if network.Vendor == "" {
+		network.Vendor = getVendorFromMAC(ap.BSSID)
+	}
+
+	if ap.Stations > 0 || ap.Utilization > 0 {
+		network.BSSLoad = true
+		network.StationCount = ap.Stations
+		network.ChannelUtilization = ap.Utilization
+	}
+	applyStationEstimate(&network)
+
+	return network, true
+}
+
+// pathLoss applies the log-distance model PL(d) = PL(1m) + 10·n·log10(d)
+func (s *Scenario) pathLoss(ap Position, frequency int) float64 {
+	distance := math.Sqrt(math.Pow(ap.X-s.Observer.X, 2) + math.Pow(ap.Y-s.Observer.Y, 2) + math.Pow(ap.Z-s.Observer.Z, 2))
+	if distance < 1 {
+		distance = 1
+	}
+
+	reference := s.ReferenceLoss
+	if reference == 0 {
+		// Free-space path loss at 1 m: 20·log10(f MHz) − 27.55
+		reference = 20*math.Log10(float64(frequency)) - 27.55
+	}
+	return reference + 10*s.PathLossExponent*math.Log10(distance)
+}
@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/svgreg/wifi-bander/internal/ie"
+)
+
+// securityLabels returns every label ie.SecurityLabel produces for one or two AKM suites
+func securityLabels() map[string]bool {
+	akms := []int{ie.AKM8021X, ie.AKMPSK, ie.AKMFT8021X, ie.AKMFTPSK, ie.AKM8021XSHA256, ie.AKMPSKSHA256, ie.AKMSAE,
+		ie.AKMFTSAE, ie.AKMSuiteB, ie.AKMSuiteB192, ie.AKMFT8021XSHA384, ie.AKMOWE, ie.AKMSAEExtKey, ie.AKMFTSAEExtKey}
+	labels := make(map[string]bool)
+	for _, privacy := range []bool{false, true} {
+		for _, hasRSN := range []bool{false, true} {
+			for _, hasWPA := range []bool{false, true} {
+				for _, first := range akms {
+					for _, second := range akms {
+						labels[ie.SecurityLabel(privacy, hasRSN, hasWPA, []int{first, second})] = true
+					}
+				}
+			}
+		}
+	}
+	return labels
+}
+
+func TestBuiltinScenariosUseScanSecurityLabels(t *testing.T) {
+	labels := securityLabels()
+	for _, name := range ScenarioNames() {
+		scenario, err := LoadScenario(name)
+		if err != nil {
+			t.Fatalf("LoadScenario(%s): %v", name, err)
+		}
+		for _, ap := range scenario.AccessPoints {
+			if !labels[ap.Security] {
+				t.Errorf("%s: %s has security %q, which no scan reports", name, ap.SSID, ap.Security)
+			}
+		}
+	}
+}
+
+func TestSimulatedScannerDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	scenario := `{"observer": {"x": 0, "y": 0}, "seed": 1, "access_points": [{"ssid": "Near", "position": {"x": 2, "y": 0}, "channel": 36}]}`
+	if err := os.WriteFile(path, []byte(scenario), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	networks, err := (&SimulatedScanner{Path: path}).Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(networks) != 1 {
+		t.Fatalf("got %d networks, want 1", len(networks))
+	}
+	if near := networks[0]; near.Security != "WPA2 Personal" || !securityLabels()[near.Security] ||
+		near.ChannelWidth != "20MHz" || near.BSSID != "02:00:00:00:00:01" {
+		t.Errorf("Near simulated as %+v", near)
+	}
+}
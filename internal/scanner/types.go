@@ -1,32 +1,66 @@
 package scanner
 
+import (
+	"context"
+	"time"
+)
+
 // WiFiNetwork represents a detected WiFi network with all its properties
 type WiFiNetwork struct {
-	SSID            string // Network name
-	Channel         int    // WiFi channel (1-13 for 2.4GHz, 36+ for 5GHz)
-	Signal          int    // Signal strength in dBm
-	Band            string // "2.4G" or "5G"
-	CongestionScore int    // Calculated congestion level
-	Frequency       int    // Frequency in MHz
-	StationCount    int    // Estimated connected devices
+	SSID            string `json:"ssid"`             // Network name
+	Channel         int    `json:"channel"`          // WiFi channel (1-13 for 2.4GHz, 36+ for 5GHz, 1-233 for 6GHz)
+	Signal          int    `json:"signal"`           // Signal strength in dBm
+	Band            string `json:"band"`             // "2.4G", "5G" or "6G"
+	CongestionScore int    `json:"congestion_score"` // Calculated congestion level
+	Frequency       int    `json:"frequency"`        // Frequency in MHz
+	StationCount    int    `json:"station_count"`    // Associated stations; a signal-based guess unless BSSLoad is set
 
 	// Enhanced network information
-	Security     string // Security type (Open, WPA, WPA2, WPA3, etc.)
-	PHYMode      string // PHY mode (802.11n, 802.11ac, 802.11ax, etc.)
-	ChannelWidth string // Channel width (20MHz, 40MHz, 80MHz, 160MHz)
-	NetworkType  string // Network type (Infrastructure, Ad-hoc)
-	BSSID        string // MAC address of access point
-	Vendor       string // Vendor name (from MAC OUI lookup)
-	Quality      int    // Signal quality percentage (0-100)
-	Noise        int    // Noise level in dBm
-	SNR          int    // Signal-to-Noise Ratio
+	Security     string `json:"security"`      // Security type (Open, WPA, WPA2, WPA3, etc.)
+	PHYMode      string `json:"phy_mode"`      // PHY mode (802.11n, 802.11ac, 802.11ax, etc.)
+	ChannelWidth string `json:"channel_width"` // Channel width (20MHz, 40MHz, 80MHz, 160MHz)
+	NetworkType  string `json:"network_type"`  // Network type (Infrastructure, Ad-hoc)
+	BSSID        string `json:"bssid"`         // MAC address of access point
+	Vendor       string `json:"vendor"`        // Vendor name (from MAC OUI lookup)
+	Quality      int    `json:"quality"`       // Signal quality percentage (0-100), derived from Signal
+	Noise        int    `json:"noise"`         // Noise level in dBm
+	SNR          int    `json:"snr"`           // Signal-to-Noise Ratio
+
+	// Signal as the backend reported it, before normalization to dBm and calibration
+	RawSignal    int        `json:"raw_signal,omitempty"`    // Reading in SignalUnit
+	SignalUnit   SignalUnit `json:"signal_unit,omitempty"`   // Native unit of RawSignal
+	SignalOffset int        `json:"signal_offset,omitempty"` // Calibration offset in dB applied to Signal and Noise
+
+	// Backend-specific details, zero when the backend cannot provide them
+	LastSeen           time.Time `json:"last_seen"`                     // When the BSS was last observed
+	IEs                []byte    `json:"ies,omitempty"`                 // Raw 802.11 information elements
+	CenterFrequency    int       `json:"center_frequency,omitempty"`    // Center of the occupied channel block in MHz
+	SecondaryOffset    int       `json:"secondary_offset,omitempty"`    // HT secondary channel offset: 1 above, -1 below, 0 none
+	BSSLoad            bool      `json:"bss_load,omitempty"`            // StationCount and ChannelUtilization come from an advertised BSS Load element
+	ChannelUtilization int       `json:"channel_utilization,omitempty"` // Channel utilization percentage (0-100) from BSS Load
+	MaxRate            int       `json:"max_rate,omitempty"`            // Maximum advertised bit rate in Mbit/s
+	Connected          bool      `json:"connected,omitempty"`           // This host is currently associated with the BSS
+
+	// Per-radio observations, set when more than one interface scanned
+	Radios []RadioObservation `json:"radios,omitempty"`
+}
+
+// RadioObservation is one interface's view of a BSS in a multi-radio scan
+type RadioObservation struct {
+	Interface string    `json:"interface"`       // Interface that saw the BSS
+	Backend   string    `json:"backend"`         // Backend used on that interface
+	Signal    int       `json:"signal"`          // Signal strength in dBm
+	Noise     int       `json:"noise,omitempty"` // Noise level in dBm
+	LastSeen  time.Time `json:"last_seen"`       // When this radio last observed the BSS
 }
 
 // Interface methods for analyzer package compatibility
-func (w WiFiNetwork) GetBand() string      { return w.Band }
-func (w WiFiNetwork) GetChannel() int      { return w.Channel }
-func (w WiFiNetwork) GetSignal() int       { return w.Signal }
-func (w WiFiNetwork) GetStationCount() int { return w.StationCount }
+func (w WiFiNetwork) GetBand() string            { return w.Band }
+func (w WiFiNetwork) GetChannel() int            { return w.Channel }
+func (w WiFiNetwork) GetSignal() int             { return w.Signal }
+func (w WiFiNetwork) GetStationCount() int       { return w.StationCount }
+func (w WiFiNetwork) HasBSSLoad() bool           { return w.BSSLoad }
+func (w WiFiNetwork) GetChannelUtilization() int { return w.ChannelUtilization }
 
 // Interface methods for display package compatibility
 func (w WiFiNetwork) GetSSID() string         { return w.SSID }
@@ -41,15 +75,29 @@ func (w WiFiNetwork) GetVendor() string       { return w.Vendor }
 func (w WiFiNetwork) GetQuality() int         { return w.Quality }
 func (w WiFiNetwork) GetNoise() int           { return w.Noise }
 func (w WiFiNetwork) GetSNR() int             { return w.SNR }
+func (w WiFiNetwork) GetLastSeen() time.Time  { return w.LastSeen }
+func (w WiFiNetwork) GetCenterFrequency() int { return w.CenterFrequency }
+func (w WiFiNetwork) GetSecondaryOffset() int { return w.SecondaryOffset }
 
-// ChannelInfo holds aggregated information about a specific channel
-type ChannelInfo struct {
-	Channel       int // Channel number
-	NetworkCount  int // Number of networks on this channel
-	StrongestRSSI int // Strongest signal strength seen on this channel
+// GetRadioSignals returns the signal seen by each interface in a multi-radio scan
+func (w WiFiNetwork) GetRadioSignals() map[string]int {
+	if len(w.Radios) == 0 {
+		return nil
+	}
+	signals := make(map[string]int, len(w.Radios))
+	for _, radio := range w.Radios {
+		signals[radio.Interface] = radio.Signal
+	}
+	return signals
 }
 
-// Scanner defines the interface for WiFi network scanning
+// Scanner defines the interface for WiFi network scanning. Scan must return
+// promptly once ctx is done; failures wrap one of the Err* sentinels when the cause is known.
 type Scanner interface {
-	Scan() ([]WiFiNetwork, error)
+	Scan(ctx context.Context) ([]WiFiNetwork, error)
+}
+
+// SourceReporter is implemented by scanners that can name the backend and interface behind their last scan
+type SourceReporter interface {
+	Source() (backend, iface string)
 }
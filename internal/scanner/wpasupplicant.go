@@ -0,0 +1,454 @@
+package scanner
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/ie"
+)
+
+// DefaultWPACtrlDir is where wpa_supplicant creates its per-interface control sockets
+const DefaultWPACtrlDir = "/var/run/wpa_supplicant"
+
+// wpa_supplicant control interface timeouts
+const (
+	wpaReplyTimeout = 5 * time.Second
+	wpaScanTimeout  = 15 * time.Second
+)
+
+// wpaSocketCounter keeps local socket names unique within the process
+var wpaSocketCounter uint32
+
+// WPASupplicantScanner implements WiFi scanning through wpa_supplicant's control socket
+type WPASupplicantScanner struct {
+	// Interface selects the control socket; the first socket in CtrlDir is used and stored here when empty
+	Interface string
+	// CtrlDir is the control socket directory, DefaultWPACtrlDir when empty
+	CtrlDir string
+}
+
+// wpaConn is a connected control interface session
+type wpaConn struct {
+	ctx       context.Context
+	stop      func() bool // Unregisters the cancellation hook
+	conn      *net.UnixConn
+	localPath string
+	buf       []byte
+}
+
+// Scan requests a scan from wpa_supplicant, waits for completion and reads the BSS table
+func (w *WPASupplicantScanner) Scan(ctx context.Context) ([]WiFiNetwork, error) {
+	ctrlDir := w.CtrlDir
+	if ctrlDir == "" {
+		ctrlDir = DefaultWPACtrlDir
+	}
+
+	iface := w.Interface
+	if iface == "" {
+		var err error
+		iface, err = findWPAInterface(ctrlDir)
+		if err != nil {
+			return nil, err
+		}
+		w.Interface = iface
+	}
+
+	conn, err := dialWPA(ctx, filepath.Join(ctrlDir, iface))
+	if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ECONNREFUSED) {
+		return nil, fmt.Errorf("wpa_supplicant control socket: %w: %v", ErrToolMissing, err)
+	}
+	if err != nil {
+		return nil, errnoError("wpa_supplicant control socket failed", err)
+	}
+	defer conn.Close()
+
+	if err := conn.expectOK("ATTACH"); err != nil {
+		return nil, err
+	}
+	defer conn.request("DETACH")
+
+	if err := conn.scan(); err != nil {
+		return nil, err
+	}
+
+	return w.readResults(conn)
+}
+
+// Source reports the backend and the interface used by the last scan
+func (w *WPASupplicantScanner) Source() (string, string) {
+	return "wpa_supplicant", w.Interface
+}
+
+// findWPAInterface returns the first control socket in the directory
+func findWPAInterface(ctrlDir string) (string, error) {
+	entries, err := os.ReadDir(ctrlDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("wpa_supplicant is not running: %w", ErrToolMissing)
+	}
+	if err != nil {
+		return "", errnoError("failed to read wpa_supplicant control directory", err)
+	}
+
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSocket != 0 && !strings.HasPrefix(entry.Name(), "p2p-dev-") {
+			return entry.Name(), nil
+		}
+	}
+
+	return "", fmt.Errorf("no wpa_supplicant control socket found in %s: %w", ctrlDir, ErrToolMissing)
+}
+
+// dialWPA connects to a control socket from a uniquely named local socket, as wpa_ctrl does.
+// Cancelling ctx interrupts any pending read.
+func dialWPA(ctx context.Context, path string) (*wpaConn, error) {
+	localPath := filepath.Join(os.TempDir(), fmt.Sprintf("wpa_ctrl_%d-%d", os.Getpid(), atomic.AddUint32(&wpaSocketCounter, 1)))
+	os.Remove(localPath)
+
+	conn, err := net.DialUnix("unixgram",
+		&net.UnixAddr{Name: localPath, Net: "unixgram"},
+		&net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		os.Remove(localPath)
+		return nil, err
+	}
+
+	c := &wpaConn{ctx: ctx, conn: conn, localPath: localPath, buf: make([]byte, 64*1024)}
+	c.stop = context.AfterFunc(ctx, func() {
+		conn.SetReadDeadline(time.Now())
+	})
+	return c, nil
+}
+
+// Close closes the session and removes the local socket
+func (c *wpaConn) Close() error {
+	c.stop()
+	err := c.conn.Close()
+	os.Remove(c.localPath)
+	return err
+}
+
+// request sends a command and returns its reply, skipping unsolicited event messages
+func (c *wpaConn) request(cmd string) (string, error) {
+	if _, err := c.conn.Write([]byte(cmd)); err != nil {
+		return "", err
+	}
+
+	deadline := time.Now().Add(wpaReplyTimeout)
+	for {
+		msg, err := c.receive(deadline)
+		if err != nil {
+			return "", fmt.Errorf("wpa_supplicant %s failed: %w", strings.Fields(cmd)[0], err)
+		}
+		if !isWPAEvent(msg) {
+			return msg, nil
+		}
+	}
+}
+
+// expectOK sends a command that must be answered with "OK"
+func (c *wpaConn) expectOK(cmd string) error {
+	reply, err := c.request(cmd)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(reply) != "OK" {
+		return fmt.Errorf("wpa_supplicant %s failed: %s", cmd, strings.TrimSpace(reply))
+	}
+	return nil
+}
+
+// scan triggers a scan and waits for CTRL-EVENT-SCAN-RESULTS
+func (c *wpaConn) scan() error {
+	reply, err := c.request("SCAN")
+	if err != nil {
+		return err
+	}
+
+	// FAIL-BUSY means a scan is already running; its results are just as good
+	switch strings.TrimSpace(reply) {
+	case "OK", "FAIL-BUSY":
+	default:
+		// A disabled interface (rfkill or radio off) refuses to scan
+		if status, err := c.request("STATUS"); err == nil && strings.Contains(status, "wpa_state=INTERFACE_DISABLED") {
+			return fmt.Errorf("wpa_supplicant SCAN failed: %w", ErrRadioDisabled)
+		}
+		return fmt.Errorf("wpa_supplicant SCAN failed: %s", strings.TrimSpace(reply))
+	}
+
+	deadline := time.Now().Add(wpaScanTimeout)
+	for {
+		msg, err := c.receive(deadline)
+		if err != nil {
+			return fmt.Errorf("waiting for wpa_supplicant scan results failed: %w", err)
+		}
+		if !isWPAEvent(msg) {
+			continue
+		}
+
+		event := wpaEventName(msg)
+		switch event {
+		case "CTRL-EVENT-SCAN-RESULTS":
+			return nil
+		case "CTRL-EVENT-SCAN-FAILED":
+			return fmt.Errorf("wpa_supplicant scan failed: %s", strings.TrimSpace(msg))
+		}
+	}
+}
+
+// receive reads a single datagram before the deadline or until the session's context is done
+func (c *wpaConn) receive(deadline time.Time) (string, error) {
+	if err := c.ctx.Err(); err != nil {
+		return "", err
+	}
+	ctxDeadline, ok := c.ctx.Deadline()
+	ctxBound := ok && ctxDeadline.Before(deadline)
+	if ctxBound {
+		deadline = ctxDeadline
+	}
+	if err := c.conn.SetReadDeadline(deadline); err != nil {
+		return "", err
+	}
+	n, err := c.conn.Read(c.buf)
+	if err != nil {
+		if ctxErr := c.ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		// The socket can time out just before the context notices its own deadline
+		if ctxBound && errors.Is(err, os.ErrDeadlineExceeded) {
+			return "", context.DeadlineExceeded
+		}
+		return "", err
+	}
+	return string(c.buf[:n]), nil
+}
+
+// isWPAEvent reports whether a message is an unsolicited "<level>EVENT" notification
+func isWPAEvent(msg string) bool {
+	return strings.HasPrefix(msg, "<") && strings.Contains(msg, ">")
+}
+
+// wpaEventName strips the priority prefix and returns the event name
+func wpaEventName(msg string) string {
+	_, event, _ := strings.Cut(msg, ">")
+	if fields := strings.Fields(event); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// readResults lists the scan results and enriches each entry with its BSS details
+func (w *WPASupplicantScanner) readResults(conn *wpaConn) ([]WiFiNetwork, error) {
+	reply, err := conn.request("SCAN_RESULTS")
+	if err != nil {
+		return nil, err
+	}
+
+	networks := w.parseScanResults(reply, time.Now())
+	for i := range networks {
+		details, err := conn.request("BSS " + networks[i].BSSID)
+		if err != nil || strings.TrimSpace(details) == "" || strings.HasPrefix(details, "FAIL") {
+			continue
+		}
+		w.applyBSSDetails(&networks[i], details, time.Now())
+	}
+
+	// Calculate congestion scores
+	scoreCongestion(networks)
+
+	return networks, nil
+}
+
+// parseScanResults parses the tab-separated SCAN_RESULTS table:
+// "bssid / frequency / signal level / flags / ssid"
+func (w *WPASupplicantScanner) parseScanResults(output string, now time.Time) []WiFiNetwork {
+	var networks []WiFiNetwork
+
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		if i == 0 || strings.TrimSpace(line) == "" {
+			continue // Skip header line or empty lines
+		}
+
+		parts := strings.SplitN(line, "\t", 5)
+		if len(parts) < 4 {
+			continue
+		}
+
+		frequency, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		signal, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+
+		ssid := ""
+		if len(parts) == 5 {
+			ssid = unescapeWPAString(parts[4])
+		}
+
+		network := WiFiNetwork{
+			SSID:            ssid,
+			BSSID:           strings.ToLower(parts[0]),
+			Frequency:       frequency,
+			CenterFrequency: frequency,
+			Security:        wpaFlagsSecurity(parts[3]),
+			PHYMode:         "Unknown",
+			ChannelWidth:    "Unknown",
+			NetworkType:     "Infrastructure",
+			Vendor:          getVendorFromMAC(parts[0]),
+			LastSeen:        now,
+		}
+		if err := applyFrequency(&network); err != nil {
+			continue
+		}
+		// level is dBm unless the driver has no dBm support, when it is a positive relative value
+		if signal > 0 {
+			setSignal(&network, signal, SignalPercent)
+		} else {
+			setSignal(&network, signal, SignalDBm)
+		}
+		applyStationEstimate(&network)
+		if strings.Contains(parts[3], "[IBSS]") {
+			network.NetworkType = "Ad-hoc"
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+// applyBSSDetails merges the key=value output of "BSS <bssid>" into a network
+func (w *WPASupplicantScanner) applyBSSDetails(network *WiFiNetwork, details string, now time.Time) {
+	var capability uint16
+	var ies []byte
+
+	for _, line := range strings.Split(details, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "capabilities":
+			if v, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 16); err == nil {
+				capability = uint16(v)
+			}
+		case "noise":
+			if noise, err := strconv.Atoi(value); err == nil && noise != 0 {
+				network.Noise = noise
+			}
+		case "age":
+			if age, err := strconv.Atoi(value); err == nil {
+				network.LastSeen = now.Add(-time.Duration(age) * time.Second)
+			}
+		case "ie":
+			if b, err := hex.DecodeString(value); err == nil {
+				ies = b
+			}
+		}
+	}
+
+	if network.Noise != 0 {
+		network.SNR = network.Signal - network.Noise
+	}
+	// The elements, when present, decide security as they do for the other backends
+	if ies != nil {
+		applyInformationElements(network, ies, capability)
+	}
+}
+
+// wpaFlagsSecurity maps scan result flags such as "[WPA2-PSK-CCMP][ESS]" to the
+// security label the information elements behind them would give. It labels BSSes
+// whose elements could not be read.
+func wpaFlagsSecurity(flags string) string {
+	var wpa, rsn, wep bool
+	var akms []int
+
+	for _, flag := range strings.FieldsFunc(flags, func(r rune) bool { return r == '[' || r == ']' }) {
+		proto, rest, _ := strings.Cut(flag, "-")
+		switch proto {
+		case "WPA":
+			wpa = true
+		case "WPA2", "RSN":
+			rsn = true
+		case "WEP":
+			wep = true
+			continue
+		default:
+			continue
+		}
+
+		// Key management is the middle field, e.g. "PSK+SAE" in "WPA2-PSK+SAE-CCMP"
+		if idx := strings.LastIndex(rest, "-"); idx >= 0 {
+			rest = rest[:idx]
+		}
+		for _, akm := range strings.Split(rest, "+") {
+			switch {
+			case strings.Contains(akm, "SAE"):
+				akms = append(akms, ie.AKMSAE)
+			case strings.Contains(akm, "PSK"):
+				akms = append(akms, ie.AKMPSK)
+			case strings.Contains(akm, "SUITE-B-192"):
+				akms = append(akms, ie.AKMSuiteB192)
+			case strings.Contains(akm, "EAP"):
+				akms = append(akms, ie.AKM8021X)
+			case strings.Contains(akm, "OWE"):
+				akms = append(akms, ie.AKMOWE)
+			}
+		}
+	}
+
+	return ie.SecurityLabel(wep, rsn, wpa, akms)
+}
+
+// unescapeWPAString decodes the printf-style escaping wpa_supplicant applies to SSIDs
+func unescapeWPAString(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			out = append(out, s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'e':
+			out = append(out, 0x1b)
+		case 'x':
+			if i+2 < len(s) {
+				if b, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+					out = append(out, byte(b))
+					i += 2
+					continue
+				}
+			}
+			out = append(out, 'x')
+		default:
+			out = append(out, s[i])
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,270 @@
+package scanner
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeWPASupplicant answers control interface commands on a unixgram socket the
+// way wpa_supplicant does, including the events an attached monitor receives
+type fakeWPASupplicant struct {
+	scanReply  string   // Reply to SCAN
+	scanEvents []string // Events sent after replying to SCAN
+	status     string   // Reply to STATUS
+	commands   chan string
+}
+
+// serve listens on dir/iface until the test ends
+func (f *fakeWPASupplicant) serve(t *testing.T, dir, iface string) {
+	t.Helper()
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: filepath.Join(dir, iface), Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	f.commands = make(chan string, 64)
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, client, err := conn.ReadFromUnix(buf)
+			if err != nil {
+				return
+			}
+			cmd := string(buf[:n])
+			f.commands <- cmd
+
+			send := func(msg string) { conn.WriteToUnix([]byte(msg), client) }
+			// An unrelated event may arrive before any reply
+			send("<3>CTRL-EVENT-BSS-ADDED 1 00:11:32:aa:bb:cc")
+
+			switch {
+			case cmd == "ATTACH" || cmd == "DETACH":
+				send("OK\n")
+			case cmd == "SCAN":
+				send(f.scanReply)
+				for _, event := range f.scanEvents {
+					send(event)
+				}
+			case cmd == "STATUS":
+				send(f.status)
+			case cmd == "SCAN_RESULTS":
+				send("bssid / frequency / signal level / flags / ssid\n" +
+					"00:11:32:aa:bb:cc\t5180\t-55\t[WPA2-?-CCMP][ESS]\tOffice\n" +
+					"02:00:00:00:00:01\t2437\t-71\t[WPA2-EAP-CCMP][ESS]\tCorp\\x20Wi\\xc3\\xa9\n" +
+					"not a result line\n")
+			case cmd == "BSS 00:11:32:aa:bb:cc":
+				send("bssid=00:11:32:aa:bb:cc\nfreq=5180\ncapabilities=0x0411\nnoise=-95\nlevel=-55\nage=3\n" +
+					"ie=" + hex.EncodeToString(recordedBeaconIEs) + "\n")
+			default:
+				send("FAIL\n")
+			}
+		}
+	}()
+}
+
+func newFakeWPASupplicant() *fakeWPASupplicant {
+	return &fakeWPASupplicant{
+		scanReply:  "OK\n",
+		scanEvents: []string{"<3>CTRL-EVENT-SCAN-STARTED ", "<3>CTRL-EVENT-SCAN-RESULTS "},
+		status:     "wpa_state=SCANNING\n",
+	}
+}
+
+func TestWPASupplicantScan(t *testing.T) {
+	dir := t.TempDir()
+	fake := newFakeWPASupplicant()
+	fake.serve(t, dir, "wlan0")
+
+	w := &WPASupplicantScanner{CtrlDir: dir}
+	networks, err := w.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if _, iface := w.Source(); iface != "wlan0" {
+		t.Errorf("interface = %q, want the socket found in the directory", iface)
+	}
+	if len(networks) != 2 {
+		t.Fatalf("got %d networks, want 2", len(networks))
+	}
+
+	office := networks[0]
+	if office.SSID != "Office" || office.Channel != 36 || office.Signal != -55 || office.Noise != -95 || office.SNR != 40 {
+		t.Errorf("Office decoded as %+v", office)
+	}
+	// The BSS details add the IEs, which decide security over flags naming no key management
+	if office.ChannelWidth != "80MHz" || office.PHYMode != "802.11ac" || !office.BSSLoad || office.Security != "WPA2 Personal" {
+		t.Errorf("Office details: width %s, PHY %s, BSS Load %v, security %s",
+			office.ChannelWidth, office.PHYMode, office.BSSLoad, office.Security)
+	}
+	if age := time.Since(office.LastSeen); age < 3*time.Second || age > 10*time.Second {
+		t.Errorf("LastSeen %v ago, want about 3s", age)
+	}
+
+	corp := networks[1]
+	if corp.SSID != "Corp Wié" || corp.Security != "WPA2 Enterprise" || corp.ChannelWidth != "Unknown" {
+		t.Errorf("Corp decoded as %+v", corp)
+	}
+
+	var commands []string
+	for len(fake.commands) > 0 {
+		commands = append(commands, <-fake.commands)
+	}
+	want := "ATTACH SCAN SCAN_RESULTS BSS 00:11:32:aa:bb:cc BSS 02:00:00:00:00:01 DETACH"
+	if got := strings.Join(commands, " "); got != want {
+		t.Errorf("commands %q, want %q", got, want)
+	}
+}
+
+func TestWPASupplicantScanBusy(t *testing.T) {
+	dir := t.TempDir()
+	fake := newFakeWPASupplicant()
+	fake.scanReply = "FAIL-BUSY\n"
+	fake.serve(t, dir, "wlan0")
+
+	networks, err := (&WPASupplicantScanner{CtrlDir: dir, Interface: "wlan0"}).Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(networks) != 2 {
+		t.Errorf("got %d networks from the running scan, want 2", len(networks))
+	}
+}
+
+func TestWPASupplicantScanErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(*fakeWPASupplicant)
+		want   error
+		wantIn string
+	}{
+		{
+			name: "interface disabled",
+			setup: func(f *fakeWPASupplicant) {
+				f.scanReply = "FAIL\n"
+				f.status = "wpa_state=INTERFACE_DISABLED\n"
+			},
+			want: ErrRadioDisabled,
+		},
+		{
+			name:   "scan refused",
+			setup:  func(f *fakeWPASupplicant) { f.scanReply = "FAIL\n" },
+			wantIn: "SCAN failed: FAIL",
+		},
+		{
+			name: "scan failed",
+			setup: func(f *fakeWPASupplicant) {
+				f.scanEvents = []string{"<3>CTRL-EVENT-SCAN-FAILED ret=-16 retry=1"}
+			},
+			wantIn: "CTRL-EVENT-SCAN-FAILED",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			fake := newFakeWPASupplicant()
+			test.setup(fake)
+			fake.serve(t, dir, "wlan0")
+
+			_, err := (&WPASupplicantScanner{CtrlDir: dir}).Scan(context.Background())
+			if err == nil {
+				t.Fatal("Scan succeeded")
+			}
+			if test.want != nil && !errors.Is(err, test.want) {
+				t.Errorf("Scan error = %v, want %v", err, test.want)
+			}
+			if !strings.Contains(err.Error(), test.wantIn) {
+				t.Errorf("Scan error %q does not mention %q", err, test.wantIn)
+			}
+		})
+	}
+}
+
+func TestWPASupplicantNotRunning(t *testing.T) {
+	dir := t.TempDir()
+	for _, w := range []*WPASupplicantScanner{
+		{CtrlDir: filepath.Join(dir, "missing")},
+		{CtrlDir: dir},
+		{CtrlDir: dir, Interface: "wlan9"},
+	} {
+		if _, err := w.Scan(context.Background()); !errors.Is(err, ErrToolMissing) {
+			t.Errorf("Scan(%+v) error = %v, want ErrToolMissing", w, err)
+		}
+	}
+}
+
+func TestWPASupplicantScanCancelled(t *testing.T) {
+	dir := t.TempDir()
+	fake := newFakeWPASupplicant()
+	fake.scanEvents = nil // The scan never completes
+	fake.serve(t, dir, "wlan0")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := (&WPASupplicantScanner{CtrlDir: dir}).Scan(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Scan error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWPAFlagsSecurity(t *testing.T) {
+	tests := []struct {
+		flags string
+		want  string
+	}{
+		{"[ESS]", "Open"},
+		{"", "Open"},
+		{"[WEP][ESS]", "WEP"},
+		{"[WPA-PSK-TKIP][ESS]", "WPA Personal"},
+		{"[WPA2-PSK-CCMP][ESS]", "WPA2 Personal"},
+		{"[RSN-PSK-CCMP][ESS]", "WPA2 Personal"},
+		{"[WPA-PSK-CCMP+TKIP][WPA2-PSK-CCMP+TKIP][ESS]", "WPA/WPA2 Personal"},
+		{"[WPA2-PSK+SAE-CCMP][ESS]", "WPA2/WPA3 Personal"},
+		{"[WPA2-SAE-CCMP][ESS]", "WPA3 Personal"},
+		{"[WPA2-PSK-SHA256-CCMP][ESS]", "WPA2 Personal"},
+		{"[WPA2-EAP-CCMP][ESS]", "WPA2 Enterprise"},
+		{"[WPA2-EAP+FT/EAP-CCMP][ESS]", "WPA2 Enterprise"},
+		{"[WPA-EAP-TKIP][ESS]", "WPA Enterprise"},
+		{"[WPA2-EAP-SUITE-B-192-GCMP-256][ESS]", "WPA3 Enterprise"},
+		{"[WPA2-OWE-CCMP][ESS]", "OWE"},
+		{"[OWE-TRANS][ESS]", "Open"}, // The open BSS of an OWE transition pair
+		{"[WPA2-?-CCMP][ESS]", "WPA2"},
+		{"[WPA2-PSK-CCMP][WPS][ESS][P2P]", "WPA2 Personal"},
+	}
+
+	for _, test := range tests {
+		if got := wpaFlagsSecurity(test.flags); got != test.want {
+			t.Errorf("wpaFlagsSecurity(%q) = %q, want %q", test.flags, got, test.want)
+		}
+	}
+}
+
+func TestUnescapeWPAString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Office", "Office"},
+		{`Caf\xc3\xa9`, "Café"},
+		{`a\\b`, `a\b`},
+		{`say \"hi\"`, `say "hi"`},
+		{`tab\there`, "tab\there"},
+		{`line\nbreak\r`, "line\nbreak\r"},
+		{`\e[0m`, "\x1b[0m"},
+		{`\x4`, `x4`},
+		{`\xzz`, `xzz`},
+		{`trailing\`, `trailing\`},
+		{`\x00null`, "\x00null"},
+	}
+
+	for _, test := range tests {
+		if got := unescapeWPAString(test.in); got != test.want {
+			t.Errorf("unescapeWPAString(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+// Package spectrum is the single model of WiFi bands, channels and channel widths:
+// channel/frequency conversion, bonded block centers and the sub-band plan.
+package spectrum
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Errors returned (wrapped) by the conversion functions
+var (
+	ErrInvalidBand      = errors.New("unknown band")
+	ErrInvalidChannel   = errors.New("invalid channel")
+	ErrInvalidFrequency = errors.New("frequency is not a WiFi channel")
+	ErrInvalidWidth     = errors.New("invalid channel width")
+)
+
+// Band identifies a WiFi band. The values match the labels used in scan results.
+type Band string
+
+const (
+	Band2GHz Band = "2.4G"
+	Band5GHz Band = "5G"
+	Band6GHz Band = "6G"
+)
+
+// Bands lists every band in frequency order
+func Bands() []Band {
+	return []Band{Band2GHz, Band5GHz, Band6GHz}
+}
+
+// Name returns the band's long form, e.g. "2.4GHz"
+func (b Band) Name() string {
+	return string(b) + "Hz"
+}
+
+// ParseBand accepts "2.4G", "2.4GHz", "2.4", "5G", "5GHz", "5", "6G", "6GHz" or "6"
+func ParseBand(s string) (Band, error) {
+	switch strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "HZ"), "G") {
+	case "2.4", "2":
+		return Band2GHz, nil
+	case "5":
+		return Band5GHz, nil
+	case "6":
+		return Band6GHz, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidBand, s)
+}
+
+// Channel is a 20MHz primary channel within a band
+type Channel struct {
+	Band   Band
+	Number int
+}
+
+// NewChannel validates a channel number within a band
+func NewChannel(band Band, number int) (Channel, error) {
+	c := Channel{Band: band, Number: number}
+	if _, ok := c.SubBand(); !ok {
+		if band != Band2GHz && band != Band5GHz && band != Band6GHz {
+			return Channel{}, fmt.Errorf("%w: %q", ErrInvalidBand, band)
+		}
+		return Channel{}, fmt.Errorf("%w: %d in the %s band", ErrInvalidChannel, number, band.Name())
+	}
+	return c, nil
+}
+
+// ChannelFromFrequency returns the channel whose center is freq MHz
+func ChannelFromFrequency(freq int) (Channel, error) {
+	var c Channel
+	switch {
+	case freq == 2484:
+		c = Channel{Band2GHz, 14}
+	case freq >= 2412 && freq <= 2472 && (freq-2407)%5 == 0:
+		c = Channel{Band2GHz, (freq - 2407) / 5}
+	case freq > 5000 && freq <= 5925 && freq%5 == 0:
+		c = Channel{Band5GHz, (freq - 5000) / 5}
+	case freq == 5935:
+		c = Channel{Band6GHz, 2}
+	case freq > 5950 && freq <= 7125 && (freq-5950)%20 == 5:
+		c = Channel{Band6GHz, (freq - 5950) / 5}
+	default:
+		return Channel{}, fmt.Errorf("%w: %d MHz", ErrInvalidFrequency, freq)
+	}
+
+	if _, ok := c.SubBand(); !ok {
+		return Channel{}, fmt.Errorf("%w: %d MHz", ErrInvalidFrequency, freq)
+	}
+	return c, nil
+}
+
+// Frequency converts a channel number within a band to its center frequency in MHz
+func Frequency(band Band, number int) (int, error) {
+	c, err := NewChannel(band, number)
+	if err != nil {
+		return 0, err
+	}
+	return c.Frequency(), nil
+}
+
+// BandOf returns the band containing a channel center frequency
+func BandOf(freq int) (Band, error) {
+	c, err := ChannelFromFrequency(freq)
+	if err != nil {
+		return "", err
+	}
+	return c.Band, nil
+}
+
+// Frequency returns the channel's center frequency in MHz
+func (c Channel) Frequency() int {
+	return centerOf(c.Band, c.Number)
+}
+
+// centerOf applies each band's numbering formula without validation, so it also
+// works for the center channel numbers of bonded blocks
+func centerOf(band Band, number int) int {
+	switch band {
+	case Band2GHz:
+		if number == 14 {
+			return 2484
+		}
+		return 2407 + number*5
+	case Band5GHz:
+		return 5000 + number*5
+	case Band6GHz:
+		if number == 2 {
+			return 5935
+		}
+		return 5950 + number*5
+	}
+	return 0
+}
+
+// String formats the channel as "6G/37"
+func (c Channel) String() string {
+	return string(c.Band) + "/" + strconv.Itoa(c.Number)
+}
+
+// SubBand returns the regulatory sub-band containing the channel
+func (c Channel) SubBand() (SubBand, bool) {
+	for _, sub := range subBands {
+		if sub.Band != c.Band {
+			continue
+		}
+		for _, number := range sub.Channels {
+			if number == c.Number {
+				return sub, true
+			}
+		}
+	}
+	return SubBand{}, false
+}
+
+// IsDFS reports whether the channel requires Dynamic Frequency Selection
+func (c Channel) IsDFS() bool {
+	sub, ok := c.SubBand()
+	return ok && sub.DFS
+}
+
+// IsPSC reports whether the channel is a 6GHz Preferred Scanning Channel
+func (c Channel) IsPSC() bool {
+	return c.Band == Band6GHz && c.Number%16 == 5
+}
+
+// Width is a channel width in MHz. 80+80MHz operation parses as Width160,
+// the amount of spectrum it occupies.
+type Width int
+
+const (
+	Width20  Width = 20
+	Width40  Width = 40
+	Width80  Width = 80
+	Width160 Width = 160
+	Width320 Width = 320
+)
+
+// ParseWidth accepts labels such as "80MHz", "80 MHz", "80" or "80+80MHz"
+func ParseWidth(s string) (Width, error) {
+	label := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "MHz"))
+	if label == "80+80" {
+		return Width160, nil
+	}
+	mhz, err := strconv.Atoi(label)
+	if err == nil {
+		switch w := Width(mhz); w {
+		case Width20, Width40, Width80, Width160, Width320:
+			return w, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidWidth, s)
+}
+
+// String formats the width as "80MHz"
+func (w Width) String() string {
+	return strconv.Itoa(int(w)) + "MHz"
+}
+
+// Widths lists the channel widths a band supports
+func Widths(band Band) []Width {
+	switch band {
+	case Band2GHz:
+		return []Width{Width20, Width40}
+	case Band5GHz:
+		return []Width{Width20, Width40, Width80, Width160}
+	case Band6GHz:
+		return []Width{Width20, Width40, Width80, Width160, Width320}
+	}
+	return nil
+}
+
+// Block is the contiguous spectrum occupied by a (possibly bonded) channel
+type Block struct {
+	Center int   // Center frequency in MHz
+	Width  Width // Occupied width
+}
+
+// Low returns the block's lower edge in MHz
+func (b Block) Low() int { return b.Center - int(b.Width)/2 }
+
+// High returns the block's upper edge in MHz
+func (b Block) High() int { return b.Center + int(b.Width)/2 }
+
+// Overlap returns how many MHz two blocks share
+func (b Block) Overlap(other Block) int {
+	low, high := b.Low(), b.High()
+	if other.Low() > low {
+		low = other.Low()
+	}
+	if other.High() < high {
+		high = other.High()
+	}
+	if high < low {
+		return 0
+	}
+	return high - low
+}
+
+// BondedBlock returns the block a channel occupies at the given width. 2.4GHz
+// 40MHz blocks follow secondaryOffset (1 above, -1 below, 0 for the usual choice
+// of above on channels 1-7); 5 and 6GHz blocks use the standard aligned channel
+// plan, and 6GHz 320MHz uses the 320-1 channelization.
+func BondedBlock(c Channel, w Width, secondaryOffset int) (Block, error) {
+	if _, err := NewChannel(c.Band, c.Number); err != nil {
+		return Block{}, err
+	}
+	if w == Width20 {
+		return Block{Center: c.Frequency(), Width: w}, nil
+	}
+
+	supported := false
+	for _, width := range Widths(c.Band) {
+		supported = supported || width == w
+	}
+	if !supported || (c.Band == Band6GHz && c.Number == 2) {
+		return Block{}, fmt.Errorf("%w: %s on channel %s", ErrInvalidWidth, w, c)
+	}
+
+	if c.Band == Band2GHz {
+		if secondaryOffset == 0 {
+			secondaryOffset = 1
+			if c.Number > 7 {
+				secondaryOffset = -1
+			}
+		}
+		if _, err := NewChannel(c.Band, c.Number+4*secondaryOffset); err != nil || c.Number == 14 {
+			return Block{}, fmt.Errorf("%w: no 40MHz secondary for channel %s", ErrInvalidWidth, c)
+		}
+		return Block{Center: c.Frequency() + secondaryOffset*10, Width: w}, nil
+	}
+
+	// 6GHz blocks are aligned from channel 1; 5GHz ones from 36 below UNII-3 and from 149 above
+	base := 1
+	if c.Band == Band5GHz {
+		base = 36
+		if c.Number >= 149 {
+			base = 149
+		}
+	}
+	span := int(w) / 20
+	first := base + ((c.Number-base)/4/span)*span*4
+	for number := first; number < first+span*4; number += 4 {
+		if _, err := NewChannel(c.Band, number); err != nil {
+			return Block{}, fmt.Errorf("%w: %s block for channel %s leaves the band plan", ErrInvalidWidth, w, c)
+		}
+	}
+	return Block{Center: centerOf(c.Band, first) + (span-1)*10, Width: w}, nil
+}
+
+// CenterFrequency returns the center of the block a channel occupies at the given width
+func CenterFrequency(c Channel, w Width, secondaryOffset int) (int, error) {
+	block, err := BondedBlock(c, w, secondaryOffset)
+	if err != nil {
+		return 0, err
+	}
+	return block.Center, nil
+}
+
+// CenterChannelFrequency converts the center channel number advertised in an
+// operation element (e.g. VHT segment 0) to MHz
+func CenterChannelFrequency(band Band, number int) int {
+	return centerOf(band, number)
+}
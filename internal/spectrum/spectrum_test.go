@@ -0,0 +1,154 @@
+package spectrum
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFrequency(t *testing.T) {
+	tests := []struct {
+		band   Band
+		number int
+		want   int   // MHz
+		err    error // Expected error, nil on success
+	}{
+		{Band2GHz, 1, 2412, nil},
+		{Band2GHz, 13, 2472, nil},
+		{Band2GHz, 14, 2484, nil},
+		{Band2GHz, 15, 0, ErrInvalidChannel},
+		{Band5GHz, 36, 5180, nil},
+		{Band5GHz, 144, 5720, nil},
+		{Band5GHz, 177, 5885, nil},
+		{Band5GHz, 38, 0, ErrInvalidChannel}, // A 40MHz center, not a primary channel
+		{Band6GHz, 1, 5955, nil},
+		{Band6GHz, 2, 5935, nil},
+		{Band6GHz, 5, 5975, nil},
+		{Band6GHz, 233, 7115, nil},
+		{Band6GHz, 3, 0, ErrInvalidChannel},
+		{Band6GHz, 237, 0, ErrInvalidChannel},
+		{"7G", 1, 0, ErrInvalidBand},
+	}
+
+	for _, test := range tests {
+		got, err := Frequency(test.band, test.number)
+		if got != test.want || !errors.Is(err, test.err) || (test.err == nil) != (err == nil) {
+			t.Errorf("Frequency(%s, %d) = %d, %v; want %d, %v", test.band, test.number, got, err, test.want, test.err)
+		}
+	}
+}
+
+func TestChannelFromFrequency(t *testing.T) {
+	// Every channel converts to its frequency and back
+	for _, band := range Bands() {
+		for _, c := range Channels(band) {
+			got, err := ChannelFromFrequency(c.Frequency())
+			if err != nil || got != c {
+				t.Errorf("ChannelFromFrequency(%d) = %v, %v; want %v", c.Frequency(), got, err, c)
+			}
+		}
+	}
+
+	for _, freq := range []int{0, 2400, 2418, 2489, 5181, 5925, 5945, 5965, 7135} {
+		if c, err := ChannelFromFrequency(freq); !errors.Is(err, ErrInvalidFrequency) {
+			t.Errorf("ChannelFromFrequency(%d) = %v, %v; want ErrInvalidFrequency", freq, c, err)
+		}
+	}
+
+	if band, err := BandOf(5935); band != Band6GHz || err != nil {
+		t.Errorf("BandOf(5935) = %s, %v; want 6G", band, err)
+	}
+}
+
+func TestBondedBlock(t *testing.T) {
+	tests := []struct {
+		band            Band
+		number          int
+		width           Width
+		secondaryOffset int
+		want            int // Center in MHz, 0 for an invalid block
+	}{
+		{Band2GHz, 6, Width20, 0, 2437},
+		{Band2GHz, 1, Width40, 0, 2422},
+		{Band2GHz, 11, Width40, 0, 2452},
+		{Band2GHz, 6, Width40, -1, 2427},
+		{Band2GHz, 1, Width40, -1, 0},
+		{Band2GHz, 14, Width40, 0, 0},
+		{Band2GHz, 1, Width80, 0, 0},
+		{Band5GHz, 36, Width80, 0, 5210},
+		{Band5GHz, 48, Width80, 0, 5210},
+		{Band5GHz, 100, Width160, 0, 5570},
+		{Band5GHz, 144, Width80, 0, 5690},
+		{Band5GHz, 144, Width160, 0, 0}, // 132-160 runs past UNII-2C
+		{Band5GHz, 149, Width80, 0, 5775},
+		{Band5GHz, 165, Width40, 0, 5835},
+		{Band5GHz, 36, Width320, 0, 0},
+		{Band6GHz, 37, Width160, 0, 6185},
+		{Band6GHz, 1, Width320, 0, 6105},
+		{Band6GHz, 2, Width20, 0, 5935},
+		{Band6GHz, 2, Width40, 0, 0}, // Channel 2 is a lone 20MHz channel
+		{Band6GHz, 233, Width80, 0, 0},
+		{Band6GHz, 3, Width20, 0, 0},
+	}
+
+	for _, test := range tests {
+		c := Channel{Band: test.band, Number: test.number}
+		block, err := BondedBlock(c, test.width, test.secondaryOffset)
+		if test.want == 0 {
+			if err == nil {
+				t.Errorf("BondedBlock(%s, %s, %d) = %+v, want an error", c, test.width, test.secondaryOffset, block)
+			}
+			if center, err := CenterFrequency(c, test.width, test.secondaryOffset); center != 0 || err == nil {
+				t.Errorf("CenterFrequency(%s, %s, %d) = %d, %v; want 0 and an error", c, test.width, test.secondaryOffset, center, err)
+			}
+			continue
+		}
+		if err != nil || block.Center != test.want || block.Width != test.width {
+			t.Errorf("BondedBlock(%s, %s, %d) = %+v, %v; want center %d", c, test.width, test.secondaryOffset, block, err, test.want)
+		}
+	}
+}
+
+func TestBlockOverlap(t *testing.T) {
+	tests := []struct {
+		a, b Block
+		want int
+	}{
+		{Block{5210, Width80}, Block{5180, Width20}, 20},
+		{Block{5210, Width80}, Block{5230, Width40}, 40},
+		{Block{5210, Width80}, Block{5250, Width160}, 80},
+		{Block{2412, Width20}, Block{2422, Width20}, 10},
+		{Block{2412, Width20}, Block{2432, Width20}, 0}, // Touching edges
+		{Block{2412, Width20}, Block{5180, Width20}, 0},
+	}
+
+	for _, test := range tests {
+		if got := test.a.Overlap(test.b); got != test.want {
+			t.Errorf("%+v overlaps %+v by %d MHz, want %d", test.a, test.b, got, test.want)
+		}
+		if got := test.b.Overlap(test.a); got != test.want {
+			t.Errorf("%+v overlaps %+v by %d MHz, want %d", test.b, test.a, got, test.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for input, want := range map[string]Band{"2.4G": Band2GHz, "2.4GHz": Band2GHz, "5": Band5GHz, "6ghz": Band6GHz} {
+		if got, err := ParseBand(input); got != want || err != nil {
+			t.Errorf("ParseBand(%q) = %s, %v; want %s", input, got, err, want)
+		}
+	}
+	if _, err := ParseBand("60G"); !errors.Is(err, ErrInvalidBand) {
+		t.Errorf("ParseBand(60G) error %v", err)
+	}
+
+	for input, want := range map[string]Width{"20MHz": Width20, "80 MHz": Width80, "160": Width160, "80+80MHz": Width160, "320MHz": Width320} {
+		if got, err := ParseWidth(input); got != want || err != nil {
+			t.Errorf("ParseWidth(%q) = %s, %v; want %s", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "30MHz", "Unknown"} {
+		if _, err := ParseWidth(input); !errors.Is(err, ErrInvalidWidth) {
+			t.Errorf("ParseWidth(%q) error %v", input, err)
+		}
+	}
+}
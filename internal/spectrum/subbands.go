@@ -0,0 +1,82 @@
+package spectrum
+
+// SubBand is a contiguous regulatory allocation within a band
+type SubBand struct {
+	Name     string
+	Band     Band
+	Low      int   // Lower edge in MHz
+	High     int   // Upper edge in MHz
+	Channels []int // 20MHz channel numbers, in frequency order
+	DFS      bool  // Radar detection required
+	Note     string
+}
+
+// subBands is the channel plan, in frequency order
+var subBands = []SubBand{
+	{Name: "ISM", Band: Band2GHz, Low: 2401, High: 2495, Channels: numbers(1, 14, 1), Note: "channel 14 is Japan only, 802.11b"},
+
+	{Name: "UNII-1", Band: Band5GHz, Low: 5150, High: 5250, Channels: numbers(36, 48, 4), Note: "indoor use"},
+	{Name: "UNII-2A", Band: Band5GHz, Low: 5250, High: 5350, Channels: numbers(52, 64, 4), DFS: true},
+	{Name: "UNII-2C", Band: Band5GHz, Low: 5470, High: 5730, Channels: numbers(100, 144, 4), DFS: true},
+	{Name: "UNII-3", Band: Band5GHz, Low: 5725, High: 5850, Channels: numbers(149, 165, 4), Note: "higher power, outdoor use"},
+	{Name: "UNII-4", Band: Band5GHz, Low: 5850, High: 5925, Channels: numbers(169, 177, 4), Note: "newer allocation"},
+
+	{Name: "UNII-5", Band: Band6GHz, Low: 5925, High: 6425, Channels: append([]int{2}, numbers(1, 93, 4)...), Note: "channel 2 at 5935 MHz is a lone 20MHz channel"},
+	{Name: "UNII-6", Band: Band6GHz, Low: 6425, High: 6525, Channels: numbers(97, 113, 4)},
+	{Name: "UNII-7", Band: Band6GHz, Low: 6525, High: 6875, Channels: numbers(117, 181, 4)},
+	{Name: "UNII-8", Band: Band6GHz, Low: 6875, High: 7125, Channels: numbers(185, 233, 4)},
+}
+
+// numbers returns first, first+step, ... up to last
+func numbers(first, last, step int) []int {
+	var list []int
+	for n := first; n <= last; n += step {
+		list = append(list, n)
+	}
+	return list
+}
+
+// SubBands returns the sub-bands of a band in frequency order, or every sub-band when band is empty
+func SubBands(band Band) []SubBand {
+	var list []SubBand
+	for _, sub := range subBands {
+		if band == "" || sub.Band == band {
+			sub.Channels = append([]int(nil), sub.Channels...)
+			list = append(list, sub)
+		}
+	}
+	return list
+}
+
+// LookupSubBand finds a sub-band by name, e.g. "UNII-2C"
+func LookupSubBand(name string) (SubBand, bool) {
+	for _, sub := range SubBands("") {
+		if sub.Name == name {
+			return sub, true
+		}
+	}
+	return SubBand{}, false
+}
+
+// ChannelNumbers lists every 20MHz channel number in a band in frequency order
+func ChannelNumbers(band Band) []int {
+	var list []int
+	for _, sub := range SubBands(band) {
+		list = append(list, sub.Channels...)
+	}
+	return list
+}
+
+// Channels lists every 20MHz channel in a band in frequency order
+func Channels(band Band) []Channel {
+	var list []Channel
+	for _, number := range ChannelNumbers(band) {
+		list = append(list, Channel{Band: band, Number: number})
+	}
+	return list
+}
+
+// PreferredScanningChannels lists the 6GHz PSCs, one every 80MHz starting at channel 5
+func PreferredScanningChannels() []int {
+	return numbers(5, 229, 16)
+}
@@ -0,0 +1,86 @@
+package spectrum
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSubBands(t *testing.T) {
+	var names []string
+	for _, sub := range SubBands(Band5GHz) {
+		names = append(names, sub.Name)
+	}
+	if want := []string{"UNII-1", "UNII-2A", "UNII-2C", "UNII-3", "UNII-4"}; !slices.Equal(names, want) {
+		t.Errorf("5G sub-bands %v, want %v", names, want)
+	}
+	if got := len(SubBands("")); got != 10 {
+		t.Errorf("%d sub-bands in all, want 10", got)
+	}
+
+	sub, ok := LookupSubBand("UNII-2C")
+	if !ok || !sub.DFS || sub.Channels[0] != 100 || sub.Channels[len(sub.Channels)-1] != 144 {
+		t.Errorf("LookupSubBand(UNII-2C) = %+v, %v", sub, ok)
+	}
+
+	// Callers get their own copy of the channel lists
+	sub.Channels[0] = 0
+	if again, _ := LookupSubBand("UNII-2C"); again.Channels[0] != 100 {
+		t.Error("changing a returned sub-band changed the channel plan")
+	}
+}
+
+func TestChannelNumbers(t *testing.T) {
+	tests := []struct {
+		band  Band
+		count int
+		first []int
+	}{
+		{Band2GHz, 14, []int{1, 2, 3}},
+		{Band5GHz, 28, []int{36, 40, 44}},
+		{Band6GHz, 60, []int{2, 1, 5}}, // Channel 2 lies below channel 1
+	}
+
+	for _, test := range tests {
+		numbers := ChannelNumbers(test.band)
+		if len(numbers) != test.count || !slices.Equal(numbers[:3], test.first) {
+			t.Errorf("%s has channels %v, want %d starting %v", test.band, numbers, test.count, test.first)
+		}
+		// In frequency order
+		for i := 1; i < len(numbers); i++ {
+			if centerOf(test.band, numbers[i]) <= centerOf(test.band, numbers[i-1]) {
+				t.Errorf("%s channel %d follows %d", test.band, numbers[i], numbers[i-1])
+			}
+		}
+	}
+}
+
+func TestChannelProperties(t *testing.T) {
+	tests := []struct {
+		channel Channel
+		subBand string
+		dfs     bool
+		psc     bool
+	}{
+		{Channel{Band2GHz, 6}, "ISM", false, false},
+		{Channel{Band5GHz, 36}, "UNII-1", false, false},
+		{Channel{Band5GHz, 52}, "UNII-2A", true, false},
+		{Channel{Band5GHz, 144}, "UNII-2C", true, false},
+		{Channel{Band5GHz, 177}, "UNII-4", false, false},
+		{Channel{Band6GHz, 2}, "UNII-5", false, false},
+		{Channel{Band6GHz, 5}, "UNII-5", false, true},
+		{Channel{Band6GHz, 101}, "UNII-6", false, true},
+		{Channel{Band6GHz, 233}, "UNII-8", false, false},
+	}
+
+	for _, test := range tests {
+		sub, ok := test.channel.SubBand()
+		if !ok || sub.Name != test.subBand || test.channel.IsDFS() != test.dfs || test.channel.IsPSC() != test.psc {
+			t.Errorf("%s: sub-band %s (%v), DFS %v, PSC %v; want %s, %v, %v",
+				test.channel, sub.Name, ok, test.channel.IsDFS(), test.channel.IsPSC(), test.subBand, test.dfs, test.psc)
+		}
+	}
+
+	if pscs := PreferredScanningChannels(); len(pscs) != 15 || pscs[0] != 5 || pscs[14] != 229 {
+		t.Errorf("PreferredScanningChannels() = %v", pscs)
+	}
+}
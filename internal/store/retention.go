@@ -0,0 +1,322 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention is how long a store keeps observations and at what resolution
+type Retention struct {
+	Raw         time.Duration // Full resolution is kept this long, then downsampled; zero never downsamples
+	Downsampled time.Duration // All data is deleted at this age; zero keeps it forever
+	Interval    time.Duration // Bucket size of downsampled data
+}
+
+// DefaultRetention keeps a week at full resolution and hourly summaries for 90 days
+func DefaultRetention() Retention {
+	return Retention{Raw: 7 * 24 * time.Hour, Downsampled: 90 * 24 * time.Hour, Interval: time.Hour}
+}
+
+// ParseRetention parses a policy such as "raw=7d,interval=1h,keep=90d"; durations
+// take Go units plus d for days, and settings left out keep their defaults
+func ParseRetention(s string) (Retention, error) {
+	retention := DefaultRetention()
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(entry, "=")
+		var setting *time.Duration
+		switch strings.TrimSpace(name) {
+		case "raw":
+			setting = &retention.Raw
+		case "interval":
+			setting = &retention.Interval
+		case "keep":
+			setting = &retention.Downsampled
+		default:
+			return retention, fmt.Errorf("invalid retention %q: expected raw, interval or keep, e.g. raw=7d", entry)
+		}
+
+		d, err := parseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return retention, fmt.Errorf("invalid retention %q: %v", entry, err)
+		}
+		*setting = d
+	}
+	return retention, nil
+}
+
+// parseDuration parses a Go duration or a whole number of days such as "7d"
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("days must be a whole number")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err == nil && d < 0 {
+		return 0, fmt.Errorf("duration must not be negative")
+	}
+	return d, err
+}
+
+// String describes the policy, e.g. "raw for 168h0m0s, 1h0m0s buckets for 2160h0m0s"
+func (r Retention) String() string {
+	keep := func(d time.Duration) string {
+		if d == 0 {
+			return "forever"
+		}
+		return d.String()
+	}
+	return fmt.Sprintf("raw for %s, %s buckets for %s", keep(r.Raw), r.Interval, keep(r.Downsampled))
+}
+
+// Compact applies the retention policy as of now: raw days that have aged past
+// Raw are downsampled, merging with any summaries already kept for the day, and
+// days that have aged past Downsampled are deleted. Only whole days are processed,
+// so a day is kept until its last observation has aged out. Downsampling left
+// incomplete by an interruption is finished first.
+func (s *Store) Compact(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.finishDownsampling(); err != nil {
+		return err
+	}
+	if s.Retention.Raw > 0 {
+		days, err := s.days(resolutionRaw)
+		if err != nil {
+			return err
+		}
+		for _, day := range days {
+			if !dayExpired(day, now, s.Retention.Raw) {
+				continue
+			}
+			if err := s.downsampleDay(day); err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.Retention.Downsampled > 0 {
+		for _, resolution := range []string{resolutionDownsampled, resolutionRaw} {
+			days, err := s.days(resolution)
+			if err != nil {
+				return err
+			}
+			for _, day := range days {
+				if !dayExpired(day, now, s.Retention.Downsampled) {
+					continue
+				}
+				if err := os.Remove(s.path(resolution, day)); err != nil {
+					return fmt.Errorf("failed to delete expired store file: %v", err)
+				}
+			}
+		}
+	}
+
+	s.compacted = now.UTC().Truncate(24 * time.Hour)
+	return nil
+}
+
+// dayExpired reports whether all of a UTC day is older than age as of now
+func dayExpired(day string, now time.Time, age time.Duration) bool {
+	start, err := time.Parse(dayLayout, day)
+	if err != nil {
+		return false
+	}
+	return !start.Add(24 * time.Hour).After(now.Add(-age))
+}
+
+// downsampleDay replaces a raw day file with bucket summaries. The summaries are
+// written to a temporary file; the raw file is then set aside under mergedSuffix
+// before the summaries are renamed into place and it is deleted. An interruption
+// before the raw file is set aside leaves it to be downsampled again, and one after
+// is finished by finishDownsampling, so no observation is lost or counted twice.
+func (s *Store) downsampleDay(day string) error {
+	rawPath, path := s.path(resolutionRaw, day), s.path(resolutionDownsampled, day)
+
+	observations, err := readDay(rawPath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		existing, err := readDay(path)
+		if err != nil {
+			return err
+		}
+		observations = append(existing, observations...)
+	}
+
+	tmp := path + ".tmp"
+	if err := s.writeDay(tmp, downsample(observations, s.Retention.Interval)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(rawPath, rawPath+mergedSuffix); err != nil {
+		return fmt.Errorf("failed to set aside downsampled store file: %v", err)
+	}
+	return s.commitDownsampled(day)
+}
+
+// mergedSuffix marks a raw day file whose observations are in the temporary
+// downsampled file of its day, which has yet to be renamed into place
+const mergedSuffix = ".merged"
+
+// finishDownsampling completes the downsampling of days whose raw file was set
+// aside when it was interrupted
+func (s *Store) finishDownsampling() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, resolutionRaw, "*.jsonl"+mergedSuffix))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		day := strings.TrimSuffix(filepath.Base(path), ".jsonl"+mergedSuffix)
+		if err := s.commitDownsampled(day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commitDownsampled renames a day's temporary downsampled file into place, unless
+// that already happened, and deletes the raw file set aside for it
+func (s *Store) commitDownsampled(day string) error {
+	path := s.path(resolutionDownsampled, day)
+	if err := os.Rename(path+".tmp", path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace downsampled store file: %v", err)
+	}
+	if err := os.Remove(s.path(resolutionRaw, day) + mergedSuffix); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete downsampled store file: %v", err)
+	}
+	return nil
+}
+
+// writeDay writes a downsampled day file
+func (s *Store) writeDay(path string, observations []Observation) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create store file: %v", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(s.header(resolutionDownsampled)); err != nil {
+		return fmt.Errorf("failed to write store header: %v", err)
+	}
+	for _, observation := range observations {
+		if err := enc.Encode(observation); err != nil {
+			return fmt.Errorf("failed to write observation: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return file.Sync()
+}
+
+// bucketKey groups observations of one BSS on one channel within one interval
+type bucketKey struct {
+	start   time.Time
+	bssid   string
+	ssid    string
+	band    string
+	channel int
+}
+
+// bucket accumulates the observations of one bucketKey
+type bucket struct {
+	summary   Observation
+	latest    time.Time
+	signalSum float64
+	noiseSum  float64
+	noiseN    int
+}
+
+// downsample summarizes observations per BSS, channel and interval. Summaries may
+// be downsampled again: each counts for the raw observations behind it.
+func downsample(observations []Observation, interval time.Duration) []Observation {
+	buckets := make(map[bucketKey]*bucket)
+	for _, observation := range observations {
+		key := bucketKey{
+			start:   observation.Time.UTC().Truncate(interval),
+			bssid:   observation.BSSID,
+			ssid:    observation.SSID,
+			band:    observation.Band,
+			channel: observation.Channel,
+		}
+
+		samples, minSignal, maxSignal := observation.Samples, observation.MinSignal, observation.MaxSignal
+		if samples == 0 {
+			samples, minSignal, maxSignal = 1, observation.Signal, observation.Signal
+		}
+
+		b, ok := buckets[key]
+		if !ok {
+			b = &bucket{summary: Observation{MinSignal: minSignal, MaxSignal: maxSignal}}
+			buckets[key] = b
+		}
+		// Descriptive fields come from the latest observation in the bucket
+		if !observation.Time.Before(b.latest) {
+			b.latest = observation.Time
+			b.summary.Frequency = observation.Frequency
+			b.summary.Width = observation.Width
+			b.summary.Security = observation.Security
+		}
+
+		b.summary.Samples += samples
+		b.signalSum += float64(observation.Signal * samples)
+		if observation.Noise != 0 {
+			b.noiseSum += float64(observation.Noise * samples)
+			b.noiseN += samples
+		}
+		if minSignal < b.summary.MinSignal {
+			b.summary.MinSignal = minSignal
+		}
+		if maxSignal > b.summary.MaxSignal {
+			b.summary.MaxSignal = maxSignal
+		}
+	}
+
+	summaries := make([]Observation, 0, len(buckets))
+	for key, b := range buckets {
+		summary := b.summary
+		summary.Time = key.start
+		summary.BSSID, summary.SSID, summary.Band, summary.Channel = key.bssid, key.ssid, key.band, key.channel
+		summary.Signal = int(math.Round(b.signalSum / float64(summary.Samples)))
+		if b.noiseN > 0 {
+			summary.Noise = int(math.Round(b.noiseSum / float64(b.noiseN)))
+		}
+		summaries = append(summaries, summary)
+	}
+
+	// Map iteration order is random; keep files reproducible
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		if a.BSSID != b.BSSID {
+			return a.BSSID < b.BSSID
+		}
+		if a.SSID != b.SSID {
+			return a.SSID < b.SSID
+		}
+		return a.Channel < b.Channel
+	})
+	return summaries
+}
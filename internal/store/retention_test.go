@@ -0,0 +1,246 @@
+package store
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRetention(t *testing.T) {
+	defaults := DefaultRetention()
+	day := 24 * time.Hour
+	tests := []struct {
+		spec    string
+		want    Retention
+		wantErr bool
+	}{
+		{"", defaults, false},
+		{"raw=7d,interval=1h,keep=90d", defaults, false},
+		{"raw=2d", Retention{Raw: 2 * day, Downsampled: defaults.Downsampled, Interval: defaults.Interval}, false},
+		{" keep = 365d , interval=15m ", Retention{Raw: defaults.Raw, Downsampled: 365 * day, Interval: 15 * time.Minute}, false},
+		{"raw=36h,keep=0", Retention{Raw: 36 * time.Hour, Interval: defaults.Interval}, false},
+		{"raw=0d,", Retention{Downsampled: defaults.Downsampled, Interval: defaults.Interval}, false},
+		{"raw=1.5d", defaults, true},
+		{"raw=-1d", defaults, true},
+		{"keep=-1h", defaults, true},
+		{"interval=soon", defaults, true},
+		{"raw", defaults, true},
+		{"max=30d", defaults, true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseRetention(test.spec)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseRetention(%q) error = %v, want error %v", test.spec, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && got != test.want {
+			t.Errorf("ParseRetention(%q) = %+v, want %+v", test.spec, got, test.want)
+		}
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	raw := func(offset time.Duration, signal, noise int) Observation {
+		observation := observationAt(start.Add(offset), "00:11:32:aa:bb:cc", signal)
+		observation.Noise = noise
+		return observation
+	}
+	// A summary of three earlier observations in the same hour
+	summary := raw(0, -60, -90)
+	summary.Samples, summary.MinSignal, summary.MaxSignal = 3, -65, -55
+	summary.Security = "WPA2 Personal"
+
+	later := raw(40*time.Minute, -40, 0) // Noise unknown
+	later.Security = "WPA3 Personal"
+	otherChannel := raw(10*time.Minute, -70, -94)
+	otherChannel.Channel = 40
+
+	got := downsample([]Observation{later, summary, raw(20*time.Minute, -48, -94), otherChannel, raw(time.Hour, -50, -92)}, time.Hour)
+
+	want := []Observation{
+		{
+			Time: start, BSSID: "00:11:32:aa:bb:cc", SSID: "Office", Band: "5G", Channel: 36,
+			// Each summary counts for its samples: (3*-60 - 40 - 48) / 5 and (3*-90 - 94) / 4
+			Signal: -54, Noise: -91, Security: "WPA3 Personal",
+			Samples: 5, MinSignal: -65, MaxSignal: -40,
+		},
+		{
+			Time: start, BSSID: "00:11:32:aa:bb:cc", SSID: "Office", Band: "5G", Channel: 40,
+			Signal: -70, Noise: -94, Samples: 1, MinSignal: -70, MaxSignal: -70,
+		},
+		{
+			Time: start.Add(time.Hour), BSSID: "00:11:32:aa:bb:cc", SSID: "Office", Band: "5G", Channel: 36,
+			Signal: -50, Noise: -92, Samples: 1, MinSignal: -50, MaxSignal: -50,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("downsample =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Downsampling summaries again at a coarser interval keeps the weights
+	again := downsample(got, 24*time.Hour)
+	if len(again) != 2 || again[0].Samples != 6 || again[0].Signal != -53 || again[0].MinSignal != -65 || again[0].MaxSignal != -40 {
+		t.Errorf("re-downsampled = %+v", again)
+	}
+}
+
+func TestCompactExpiry(t *testing.T) {
+	// Raw data older than two days is downsampled, everything older than four deleted
+	retention := Retention{Raw: 48 * time.Hour, Downsampled: 96 * time.Hour, Interval: time.Hour}
+	midnight := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		now             time.Time
+		raw, downsample []string
+	}{
+		{
+			// Each day is processed once it has wholly aged past the limit
+			name:       "at midnight",
+			now:        midnight,
+			raw:        []string{"2026-03-08", "2026-03-09"},
+			downsample: []string{"2026-03-06", "2026-03-07"},
+		},
+		{
+			name:       "just before midnight",
+			now:        midnight.Add(-time.Nanosecond),
+			raw:        []string{"2026-03-07", "2026-03-08", "2026-03-09"},
+			downsample: []string{"2026-03-05", "2026-03-06"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := openStore(t)
+			s.Retention = retention
+			var observations []Observation
+			for day := 4; day <= 9; day++ {
+				at := time.Date(2026, 3, day, 23, 30, 0, 0, time.UTC)
+				observations = append(observations, observationAt(at, "00:11:32:aa:bb:cc", -50), observationAt(at.Add(10*time.Minute), "00:11:32:aa:bb:cc", -60))
+			}
+			if err := s.AppendObservations(observations); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := s.Compact(test.now); err != nil {
+				t.Fatalf("Compact: %v", err)
+			}
+			if days, _ := s.days(resolutionRaw); !reflect.DeepEqual(days, test.raw) {
+				t.Errorf("raw days %v, want %v", days, test.raw)
+			}
+			if days, _ := s.days(resolutionDownsampled); !reflect.DeepEqual(days, test.downsample) {
+				t.Errorf("downsampled days %v, want %v", days, test.downsample)
+			}
+			if _, err := os.Stat(s.path(resolutionDownsampled, test.downsample[0]) + ".tmp"); !os.IsNotExist(err) {
+				t.Errorf("temporary file left behind: %v", err)
+			}
+		})
+	}
+}
+
+func TestCompactMergesLateObservations(t *testing.T) {
+	s := openStore(t)
+	s.Retention = Retention{Raw: 24 * time.Hour, Interval: time.Hour}
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := at.Add(72 * time.Hour)
+
+	for i, signal := range []int{-50, -60} {
+		if err := s.AppendObservations([]Observation{observationAt(at.Add(time.Duration(i)*time.Minute), "00:11:32:aa:bb:cc", signal)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Compact(now); err != nil {
+			t.Fatalf("Compact #%d: %v", i+1, err)
+		}
+	}
+
+	observations, err := s.Query(Query{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(observations) != 1 || observations[0].Samples != 2 || observations[0].Signal != -55 {
+		t.Errorf("Query = %+v, want one summary of both observations", observations)
+	}
+}
+
+func TestCompactFinishesInterruptedDownsampling(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := "2026-03-01"
+
+	tests := []struct {
+		name    string
+		renamed bool // Whether the summaries were renamed into place before the interruption
+	}{
+		{"before the summaries were renamed", false},
+		{"after the summaries were renamed", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := openStore(t)
+			s.Retention = Retention{Raw: 24 * time.Hour, Interval: time.Hour}
+			now := at.Add(72 * time.Hour)
+
+			// An earlier compaction already summarized one observation
+			if err := s.AppendObservations([]Observation{observationAt(at, "00:11:32:aa:bb:cc", -50)}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Compact(now); err != nil {
+				t.Fatal(err)
+			}
+
+			// A late observation was being merged when the process stopped, after
+			// its raw file was set aside
+			if err := s.AppendObservations([]Observation{observationAt(at.Add(time.Minute), "00:11:32:aa:bb:cc", -60)}); err != nil {
+				t.Fatal(err)
+			}
+			rawPath, path := s.path(resolutionRaw, day), s.path(resolutionDownsampled, day)
+			existing, err := readDay(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			late, err := readDay(rawPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.writeDay(path+".tmp", downsample(append(existing, late...), time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Rename(rawPath, rawPath+mergedSuffix); err != nil {
+				t.Fatal(err)
+			}
+			if test.renamed {
+				if err := os.Rename(path+".tmp", path); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			reopened, err := Open(s.dir, s.Retention)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			observations, err := reopened.Query(Query{})
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			if len(observations) != 1 || observations[0].Samples != 2 || observations[0].Signal != -55 {
+				t.Errorf("Query = %+v, want one summary of both observations", observations)
+			}
+			for _, leftover := range []string{rawPath, rawPath + mergedSuffix, path + ".tmp"} {
+				if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+					t.Errorf("%s left behind: %v", leftover, err)
+				}
+			}
+
+			// Compacting again changes nothing
+			if err := reopened.Compact(now); err != nil {
+				t.Fatalf("Compact: %v", err)
+			}
+			if again, err := reopened.Query(Query{}); err != nil || !reflect.DeepEqual(again, observations) {
+				t.Errorf("Query after compacting again = %+v, %v", again, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/scanner"
+)
+
+// Store file identification. Every day file starts with a header line; each
+// following line is one Observation.
+const (
+	StoreFormat  = "wifi-bander-store"
+	StoreVersion = 1
+)
+
+// Resolutions of the two tiers a store keeps
+const (
+	resolutionRaw         = "raw"
+	resolutionDownsampled = "downsampled"
+)
+
+// dayLayout names the day files, one per UTC day and tier
+const dayLayout = "2006-01-02"
+
+// FileHeader is the first line of a day file
+type FileHeader struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	Resolution string    `json:"resolution"`         // "raw" or "downsampled"
+	Interval   string    `json:"interval,omitempty"` // Bucket size of downsampled files
+	Created    time.Time `json:"created"`
+}
+
+// Observation is one BSS seen on one channel at one time. Downsampled observations
+// summarize a bucket: Time is its start, Signal and Noise are means and Samples
+// counts the raw observations behind them.
+type Observation struct {
+	Time      time.Time `json:"time"`
+	BSSID     string    `json:"bssid"`
+	SSID      string    `json:"ssid"`
+	Band      string    `json:"band"`
+	Channel   int       `json:"channel"`
+	Frequency int       `json:"frequency,omitempty"`
+	Signal    int       `json:"signal"`          // dBm
+	Noise     int       `json:"noise,omitempty"` // dBm, zero when unknown
+	Width     string    `json:"width,omitempty"`
+	Security  string    `json:"security,omitempty"`
+
+	// Set for downsampled observations
+	Samples   int `json:"samples,omitempty"`    // Raw observations summarized
+	MinSignal int `json:"min_signal,omitempty"` // Weakest signal in the bucket
+	MaxSignal int `json:"max_signal,omitempty"` // Strongest signal in the bucket
+}
+
+// NewObservation records a scanned network, at its last-seen time when the backend reports one
+func NewObservation(network scanner.WiFiNetwork, at time.Time) Observation {
+	if !network.LastSeen.IsZero() {
+		at = network.LastSeen
+	}
+	return Observation{
+		Time:      at.UTC(),
+		BSSID:     strings.ToLower(network.BSSID),
+		SSID:      network.SSID,
+		Band:      network.Band,
+		Channel:   network.Channel,
+		Frequency: network.Frequency,
+		Signal:    network.Signal,
+		Noise:     network.Noise,
+		Width:     network.ChannelWidth,
+		Security:  network.Security,
+	}
+}
+
+// Store is a directory of JSONL day files: full-resolution observations under raw/
+// and, once they age past the retention policy, bucketed summaries under downsampled/
+type Store struct {
+	Retention Retention
+
+	mu        sync.Mutex
+	dir       string
+	compacted time.Time // Day of the last compaction
+}
+
+// Open opens or creates a store in dir and applies the retention policy
+func Open(dir string, retention Retention) (*Store, error) {
+	if retention.Interval <= 0 {
+		return nil, fmt.Errorf("store downsampling interval must be positive")
+	}
+	if retention.Downsampled > 0 && retention.Downsampled < retention.Raw {
+		return nil, fmt.Errorf("store retention %s is shorter than its raw retention %s", retention.Downsampled, retention.Raw)
+	}
+	for _, resolution := range []string{resolutionRaw, resolutionDownsampled} {
+		if err := os.MkdirAll(filepath.Join(dir, resolution), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create store: %v", err)
+		}
+	}
+
+	s := &Store{Retention: retention, dir: dir}
+	if err := s.Compact(time.Now()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Append adds the networks of one scan taken at the given time. The retention
+// policy is applied again whenever a new day starts.
+func (s *Store) Append(at time.Time, networks []scanner.WiFiNetwork) error {
+	observations := make([]Observation, len(networks))
+	for i, network := range networks {
+		observations[i] = NewObservation(network, at)
+	}
+	if err := s.AppendObservations(observations); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	stale := at.UTC().Truncate(24 * time.Hour).After(s.compacted)
+	s.mu.Unlock()
+	if stale {
+		return s.Compact(at)
+	}
+	return nil
+}
+
+// AppendObservations adds raw observations, each to the file of its day
+func (s *Store) AppendObservations(observations []Observation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byDay := make(map[string][]Observation)
+	for _, observation := range observations {
+		day := observation.Time.UTC().Format(dayLayout)
+		byDay[day] = append(byDay[day], observation)
+	}
+	for day, batch := range byDay {
+		if err := s.appendDay(day, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendDay appends observations to a raw day file, writing the header when the file is new.
+// A partial line left at the end by an interrupted write is dropped first, so the new
+// observations start on a line of their own.
+func (s *Store) appendDay(day string, observations []Observation) error {
+	path := s.path(resolutionRaw, day)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open store file: %v", err)
+	}
+	defer file.Close()
+
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to seek store file: %v", err)
+	}
+	if size > 0 {
+		end, err := lastLineEnd(file, size)
+		if err != nil {
+			return fmt.Errorf("failed to read store file: %v", err)
+		}
+		if end < size {
+			log.Printf("Store file %s: dropping %d bytes of an interrupted write", path, size-end)
+			if err := file.Truncate(end); err != nil {
+				return fmt.Errorf("failed to repair store file: %v", err)
+			}
+			if size, err = file.Seek(end, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to seek store file: %v", err)
+			}
+		}
+	}
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	if size == 0 {
+		if err := enc.Encode(s.header(resolutionRaw)); err != nil {
+			return fmt.Errorf("failed to write store header: %v", err)
+		}
+	}
+	for _, observation := range observations {
+		if err := enc.Encode(observation); err != nil {
+			return fmt.Errorf("failed to write observation: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write observations to %s: %v", path, err)
+	}
+	return nil
+}
+
+// lastLineEnd returns the offset just past the last newline in the first size bytes
+// of a file, or 0 when there is none
+func lastLineEnd(file *os.File, size int64) (int64, error) {
+	buf := make([]byte, 4096)
+	for end := size; end > 0; {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+		chunk := buf[:end-start]
+		if _, err := file.ReadAt(chunk, start); err != nil {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// header returns the header for a new file of the given tier
+func (s *Store) header(resolution string) FileHeader {
+	header := FileHeader{Format: StoreFormat, Version: StoreVersion, Resolution: resolution, Created: time.Now()}
+	if resolution == resolutionDownsampled {
+		header.Interval = s.Retention.Interval.String()
+	}
+	return header
+}
+
+// path returns the file of one tier and day
+func (s *Store) path(resolution, day string) string {
+	return filepath.Join(s.dir, resolution, day+".jsonl")
+}
+
+// days lists the days a tier has files for, oldest first
+func (s *Store) days(resolution string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, resolution, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var days []string
+	for _, path := range paths {
+		day := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		if _, err := time.Parse(dayLayout, day); err == nil {
+			days = append(days, day)
+		}
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+// Query selects observations; zero fields match everything
+type Query struct {
+	From    time.Time // Inclusive
+	To      time.Time // Exclusive
+	BSSID   string    // Case-insensitive
+	SSID    string
+	Band    string
+	Channel int
+}
+
+// Match reports whether an observation satisfies the query
+func (q Query) Match(observation Observation) bool {
+	switch {
+	case !q.From.IsZero() && observation.Time.Before(q.From):
+		return false
+	case !q.To.IsZero() && !observation.Time.Before(q.To):
+		return false
+	case q.BSSID != "" && !strings.EqualFold(observation.BSSID, q.BSSID):
+		return false
+	case q.SSID != "" && observation.SSID != q.SSID:
+		return false
+	case q.Band != "" && observation.Band != q.Band:
+		return false
+	case q.Channel != 0 && observation.Channel != q.Channel:
+		return false
+	}
+	return true
+}
+
+// Query returns the matching observations from both tiers in time order. Days old
+// enough to be downsampled return bucket summaries, with Samples set.
+func (s *Store) Query(q Query) ([]Observation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []Observation
+	for _, resolution := range []string{resolutionDownsampled, resolutionRaw} {
+		days, err := s.days(resolution)
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range days {
+			if !q.overlapsDay(day) {
+				continue
+			}
+			observations, err := readDay(s.path(resolution, day))
+			if err != nil {
+				return nil, err
+			}
+			for _, observation := range observations {
+				if q.Match(observation) {
+					results = append(results, observation)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Time.Before(results[j].Time)
+	})
+	return results, nil
+}
+
+// overlapsDay reports whether the query's time range touches a UTC day
+func (q Query) overlapsDay(day string) bool {
+	start, err := time.Parse(dayLayout, day)
+	if err != nil {
+		return false
+	}
+	end := start.Add(24 * time.Hour)
+	if !q.From.IsZero() && !q.From.Before(end) {
+		return false
+	}
+	if !q.To.IsZero() && !start.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// readDay reads every observation from a day file. Lines that do not decode, such
+// as one left truncated by an interrupted write, are skipped with a warning; only
+// an unreadable file or header fails.
+func readDay(path string) ([]Observation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store file: %v", err)
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	line, err := r.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if _, headerErr := readHeader(line); headerErr != nil {
+		if err == io.EOF {
+			return nil, nil // Interrupted before the header was complete
+		}
+		return nil, fmt.Errorf("%s: %v", path, headerErr)
+	}
+
+	var observations []Observation
+	for number := 2; ; number++ {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return observations, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			var observation Observation
+			if decodeErr := json.Unmarshal(line, &observation); decodeErr != nil {
+				log.Printf("Store file %s: skipping line %d: %v", path, number, decodeErr)
+			} else {
+				observations = append(observations, observation)
+			}
+		}
+		if err == io.EOF {
+			return observations, nil
+		}
+	}
+}
+
+// readHeader decodes and validates a day file's header line
+func readHeader(line []byte) (FileHeader, error) {
+	var header FileHeader
+	if err := json.Unmarshal(line, &header); err != nil {
+		return header, fmt.Errorf("invalid store header: %v", err)
+	}
+	if header.Format != StoreFormat {
+		return header, fmt.Errorf("not a store file (format %q)", header.Format)
+	}
+	if header.Version < 1 || header.Version > StoreVersion {
+		return header, fmt.Errorf("unsupported store version %d", header.Version)
+	}
+	return header, nil
+}
+
+// StoringScanner wraps a Scanner and appends every successful scan to a store
+type StoringScanner struct {
+	Scanner scanner.Scanner
+	Store   *Store
+}
+
+// Scan runs the wrapped scanner and appends its result to the store. A scan the
+// store cannot keep is still returned; the store error is only logged, so a full
+// disk does not stop the analysis.
+func (s *StoringScanner) Scan(ctx context.Context) ([]scanner.WiFiNetwork, error) {
+	networks, err := s.Scanner.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Store.Append(time.Now(), networks); err != nil {
+		log.Printf("Failed to store scan: %v", err)
+	}
+	return networks, nil
+}
+
+// Source reports the wrapped scanner's source, or its type name when it cannot name one
+func (s *StoringScanner) Source() (string, string) {
+	if reporter, ok := s.Scanner.(scanner.SourceReporter); ok {
+		return reporter.Source()
+	}
+	return fmt.Sprintf("%T", s.Scanner), ""
+}
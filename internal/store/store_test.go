@@ -0,0 +1,234 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/scanner"
+)
+
+// captureLog collects the standard logger's output until the test ends
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+// openStore opens an empty store that keeps everything at full resolution
+func openStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(t.TempDir(), Retention{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return s
+}
+
+func observationAt(at time.Time, bssid string, signal int) Observation {
+	return Observation{Time: at, BSSID: bssid, SSID: "Office", Band: "5G", Channel: 36, Signal: signal}
+}
+
+// appendRaw writes bytes to the end of a raw day file, as a crash mid-write would leave them
+func appendRaw(t *testing.T, s *Store, day, data string) {
+	t.Helper()
+	file, err := os.OpenFile(s.path(resolutionRaw, day), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAppendRepairsInterruptedWrite(t *testing.T) {
+	logged := captureLog(t)
+	s := openStore(t)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := s.AppendObservations([]Observation{observationAt(at, "00:11:32:aa:bb:cc", -50)}); err != nil {
+		t.Fatal(err)
+	}
+	appendRaw(t, s, "2026-03-01", `{"time":"2026-03-01T12:00:10Z","bssid":"00:11`)
+
+	// The partial line is skipped until the next append removes it
+	observations, err := s.Query(Query{})
+	if err != nil || len(observations) != 1 {
+		t.Fatalf("Query before repair = %d observations, %v; want 1", len(observations), err)
+	}
+	if err := s.AppendObservations([]Observation{observationAt(at.Add(20*time.Second), "00:11:32:aa:bb:cc", -52)}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logged.String(), "dropping 45 bytes of an interrupted write") {
+		t.Errorf("repair not logged: %q", logged)
+	}
+
+	data, err := os.ReadFile(s.path(resolutionRaw, "2026-03-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("day file has %d lines, want a header and 2 observations:\n%s", len(lines), data)
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("invalid line %q", line)
+		}
+	}
+
+	observations, err = s.Query(Query{})
+	if err != nil || len(observations) != 2 || observations[1].Signal != -52 {
+		t.Errorf("Query after repair = %+v, %v", observations, err)
+	}
+}
+
+func TestAppendRepairsInterruptedHeader(t *testing.T) {
+	captureLog(t)
+	s := openStore(t)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	appendRaw(t, s, "2026-03-01", `{"format":"wifi-bander-st`)
+
+	if observations, err := s.Query(Query{}); err != nil || len(observations) != 0 {
+		t.Fatalf("Query of a file without a complete header = %+v, %v", observations, err)
+	}
+	if err := s.AppendObservations([]Observation{observationAt(at, "00:11:32:aa:bb:cc", -50)}); err != nil {
+		t.Fatal(err)
+	}
+	if observations, err := s.Query(Query{}); err != nil || len(observations) != 1 {
+		t.Errorf("Query after repair = %+v, %v", observations, err)
+	}
+}
+
+func TestReadDaySkipsCorruptLines(t *testing.T) {
+	logged := captureLog(t)
+	s := openStore(t)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := s.AppendObservations([]Observation{observationAt(at, "00:11:32:aa:bb:cc", -50)}); err != nil {
+		t.Fatal(err)
+	}
+	// What an unrepaired store looked like after appending past an interrupted write
+	appendRaw(t, s, "2026-03-01", `{"time":"2026-03-01T12:00:10Z","bss`+
+		`{"time":"2026-03-01T12:00:20Z","bssid":"00:11:32:aa:bb:cc","ssid":"Office","band":"5G","channel":36,"signal":-52}`+"\n\n")
+	if err := s.AppendObservations([]Observation{observationAt(at.Add(30*time.Second), "00:11:32:aa:bb:cc", -54)}); err != nil {
+		t.Fatal(err)
+	}
+
+	observations, err := s.Query(Query{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(observations) != 2 || observations[0].Signal != -50 || observations[1].Signal != -54 {
+		t.Errorf("Query = %+v, want the observations around the corrupt line", observations)
+	}
+	if !strings.Contains(logged.String(), "skipping line 3") {
+		t.Errorf("corrupt line not logged: %q", logged)
+	}
+
+	// Compaction and reopening get past the corrupt line too
+	s.Retention.Raw = 24 * time.Hour
+	if err := s.Compact(at.Add(72 * time.Hour)); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if _, err := Open(s.dir, s.Retention); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if observations, err := s.Query(Query{}); err != nil || len(observations) != 1 || observations[0].Samples != 2 {
+		t.Errorf("Query after compaction = %+v, %v; want one summary of 2 samples", observations, err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	s := openStore(t)
+	at := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+	other := observationAt(at.Add(30*time.Second), "02:00:00:00:00:01", -70)
+	other.Band, other.Channel = "2.4G", 6
+	err := s.AppendObservations([]Observation{
+		observationAt(at, "00:11:32:aa:bb:cc", -50),
+		other,
+		observationAt(at.Add(2*time.Minute), "00:11:32:aa:bb:cc", -55), // Next day
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []int // Signals in time order
+	}{
+		{"everything", Query{}, []int{-50, -70, -55}},
+		{"from inclusive", Query{From: at.Add(30 * time.Second)}, []int{-70, -55}},
+		{"to exclusive", Query{To: at.Add(30 * time.Second)}, []int{-50}},
+		{"bssid case-insensitive", Query{BSSID: "00:11:32:AA:BB:CC"}, []int{-50, -55}},
+		{"band", Query{Band: "2.4G"}, []int{-70}},
+		{"channel", Query{Channel: 36, To: at.Add(time.Minute)}, []int{-50}},
+		{"ssid", Query{SSID: "Cafe"}, nil},
+	}
+
+	for _, test := range tests {
+		observations, err := s.Query(test.query)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		var got []int
+		for _, observation := range observations {
+			got = append(got, observation.Signal)
+		}
+		if len(got) != len(test.want) || (len(got) > 0 && !equalInts(got, test.want)) {
+			t.Errorf("%s: signals %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+// scanFunc adapts a function to scanner.Scanner
+type scanFunc func(ctx context.Context) ([]scanner.WiFiNetwork, error)
+
+func (f scanFunc) Scan(ctx context.Context) ([]scanner.WiFiNetwork, error) { return f(ctx) }
+
+func TestStoringScannerKeepsScansTheStoreRejects(t *testing.T) {
+	logged := captureLog(t)
+	s := openStore(t)
+	networks := []scanner.WiFiNetwork{{SSID: "Office", BSSID: "00:11:32:AA:BB:CC", Band: "5G", Channel: 36, Signal: -50}}
+	storing := &StoringScanner{
+		Scanner: scanFunc(func(ctx context.Context) ([]scanner.WiFiNetwork, error) { return networks, nil }),
+		Store:   s,
+	}
+
+	if got, err := storing.Scan(context.Background()); err != nil || len(got) != 1 {
+		t.Fatalf("Scan = %+v, %v", got, err)
+	}
+	if observations, err := s.Query(Query{}); err != nil || len(observations) != 1 || observations[0].BSSID != "00:11:32:aa:bb:cc" {
+		t.Errorf("Query = %+v, %v; want the scanned network", observations, err)
+	}
+
+	// A store that cannot be written to only logs
+	if err := os.RemoveAll(filepath.Join(s.dir, resolutionRaw)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := storing.Scan(context.Background()); err != nil || len(got) != 1 {
+		t.Errorf("Scan with a broken store = %+v, %v; want the networks", got, err)
+	}
+	if !strings.Contains(logged.String(), "Failed to store scan") {
+		t.Errorf("store failure not logged: %q", logged)
+	}
+}
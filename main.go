@@ -1,27 +1,254 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"os/signal"
+	"runtime"
 	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/svgreg/wifi-bander/internal/alert"
 	"github.com/svgreg/wifi-bander/internal/analyzer"
+	"github.com/svgreg/wifi-bander/internal/changes"
 	"github.com/svgreg/wifi-bander/internal/display"
+	"github.com/svgreg/wifi-bander/internal/regdb"
 	"github.com/svgreg/wifi-bander/internal/scanner"
+	"github.com/svgreg/wifi-bander/internal/store"
 )
 
+// scanInterval is the pause between live scans
+const scanInterval = 10 * time.Second
+
 func main() {
+	pcapPath := flag.String("pcap", "", "analyze beacons from a pcap/pcapng monitor-mode capture instead of scanning")
+	simulatePath := flag.String("simulate", "", "scan a synthetic RF environment from a scenario file or built-in scenario ("+strings.Join(scanner.ScenarioNames(), ", ")+")")
+	recordPath := flag.String("record", "", "append every scan to a JSONL session file")
+	replayPath := flag.String("replay", "", "play back a recorded session instead of scanning")
+	speed := flag.Float64("speed", 1, "replay speed multiplier; 0 replays without delay")
+	loop := flag.Bool("loop", false, "restart the replayed session when it ends")
+	backendList := flag.String("backend", os.Getenv("WIFI_BANDER_BACKEND"),
+		"comma-separated backends to try in order, each as name or name:arg; \"list\" shows them (default from WIFI_BANDER_BACKEND)")
+	country := flag.String("country", "",
+		"two-letter regulatory country for recommendations, detected with `iw reg get` when empty; \"list\" shows the known countries")
+	modelList := flag.String("model", analyzer.DefaultModel,
+		"comma-separated scoring models; the first ranks recommendations and the rest are compared with it; \"list\" shows them")
+	weightsPath := flag.String("weights", "", "JSON file of scoring weights overriding the defaults")
+	calibrate := flag.String("calibrate", "",
+		"per-adapter signal offsets in dB, e.g. wlan0=+3,wlan1=-2; an offset without an interface applies to every adapter")
+	storeDir := flag.String("store", "", "directory to keep every scan in as per-BSSID observations")
+	retentionSpec := flag.String("retention", "",
+		"store retention as raw=7d,interval=1h,keep=90d: full resolution for raw, then interval buckets until keep")
+	signalChange := flag.Int("signal-change", changes.DefaultSignalThreshold,
+		"report a network whose signal moved by more than this many dB since it was last reported; 0 disables")
+	alertsPath := flag.String("alerts", "", "JSON file of alert rules and the commands, webhooks or syslog they notify")
+	window := flag.Duration("window", 5*time.Minute, "how much scan history recommendations are based on; 0 uses only the latest scan")
+	flag.Parse()
+
+	if *backendList == "list" {
+		listBackends()
+		return
+	}
+	if *country == "list" {
+		listCountries()
+		return
+	}
+	if *modelList == "list" {
+		listModels()
+		return
+	}
+
+	scorers, err := scoringModels(*modelList, *weightsPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	calibration, err := scanner.ParseCalibration(*calibrate)
+	if err != nil {
+		log.Fatal(err)
+	}
+	retention, err := store.ParseRetention(*retentionSpec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var alerts *alert.Engine
+	if *alertsPath != "" {
+		if alerts, err = alert.Load(*alertsPath); err != nil {
+			log.Fatal(err)
+		}
+		alerts.OnError = func(action string, err error) {
+			log.Printf("Alert action %s: %v", action, err)
+		}
+	}
+
 	fmt.Println("WiFi Bander - Cross-Platform WiFi Network Analyzer")
+
+	sources := 0
+	for _, path := range []string{*replayPath, *pcapPath, *simulatePath} {
+		if path != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		log.Fatal("-replay, -pcap and -simulate each choose the scan source; give only one")
+	}
+
+	var src scanner.Scanner
+	var replay *scanner.ReplayScanner
+	interval := scanInterval
+	once := false
+
+	if *replayPath != "" {
+		// The replay scanner reproduces the recorded pacing itself
+		replay = &scanner.ReplayScanner{Path: *replayPath, Speed: *speed, Loop: *loop}
+		src = replay
+		interval = 0
+		if len(calibration) > 0 {
+			log.Printf("Replayed scans keep the calibration they were recorded with; -calibrate is ignored")
+		}
+		if *backendList != "" {
+			log.Printf("Replayed scans keep the backend they were recorded with; -backend %s is ignored", *backendList)
+		}
+	} else {
+		specs := scanner.ParseBackendSpecs(*backendList)
+		if *pcapPath != "" || *simulatePath != "" {
+			if len(specs) > 0 {
+				log.Printf("-pcap and -simulate choose their own backend; -backend %s is ignored", *backendList)
+			}
+			if *pcapPath != "" {
+				specs = []string{"pcap:" + *pcapPath}
+				once = true
+			} else {
+				specs = []string{"simulate:" + *simulatePath}
+			}
+		}
+
+		var err error
+		if len(specs) == 0 {
+			src, err = scanner.NewScanner()
+			if err != nil {
+				log.Fatalf("No scanner backends available on %s; choose one with -backend (see -backend list)", runtime.GOOS)
+			}
+		} else {
+			src, err = scanner.NewChainScanner(specs)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		switch s := src.(type) {
+		case *scanner.ChainScanner:
+			s.OnFailure = reportBackendFailure
+			s.Calibration = calibration
+		case *scanner.MultiRadioScanner:
+			s.OnFailure = reportBackendFailure
+			s.Calibration = calibration
+		}
+		if len(calibration) > 0 {
+			fmt.Printf("Signal calibration: %s\n", calibration)
+		}
+	}
+
+	var recorder *scanner.Recorder
+	if *recordPath != "" {
+		var err error
+		recorder, err = scanner.NewRecorder(*recordPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		src = &scanner.RecordingScanner{Scanner: src, Recorder: recorder, OnError: func(err error) {
+			log.Printf("Failed to record scan: %v", err)
+		}}
+	}
+
+	if *storeDir != "" {
+		st, err := store.Open(*storeDir, retention)
+		if err != nil {
+			log.Fatal(err)
+		}
+		src = &store.StoringScanner{Scanner: src, Store: st}
+		fmt.Printf("Storing scans in %s (%s)\n", *storeDir, retention)
+	}
+
+	// Ctrl-C cancels the scan in progress and ends the loop cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	domain, err := regulatoryDomain(ctx, *country)
+	if err == nil {
+		config := analysisConfig{
+			domain:   domain,
+			scorers:  scorers,
+			history:  analyzer.NewHistory(*window),
+			changes:  changes.NewDetector(*signalChange),
+			alerts:   alerts,
+			rogues:   analyzer.NewRogueDetector(),
+			recorded: *replayPath != "" || *pcapPath != "",
+		}
+		if replay != nil {
+			// A looped session goes back in time; its history starts over with it
+			replay.OnLoop = config.history.Reset
+		}
+		err = run(ctx, src, config, interval, once)
+	}
+	stop()
+
+	if recorder != nil {
+		recorder.Close()
+	}
+	if alerts != nil {
+		alerts.Close()
+	}
+	if err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+}
+
+// analysisConfig is how scans are judged: the regulatory domain recommendations must
+// respect, the scoring models, the first of which ranks them, the scan history
+// they are based on, the detectors comparing each scan with the last and with its
+// SSIDs' other access points, and the alert rules, if any. Recorded scans, from a
+// replayed session or a capture, are analyzed at the time they were taken, live
+// ones at the time they complete.
+type analysisConfig struct {
+	domain   *regdb.Domain
+	scorers  []analyzer.Scorer
+	history  *analyzer.History
+	changes  *changes.Detector
+	rogues   *analyzer.RogueDetector
+	alerts   *alert.Engine
+	recorded bool
+}
+
+// run performs the initial scan and keeps scanning every interval until the
+// source is exhausted or ctx is cancelled. With once set only the initial scan is shown.
+func run(ctx context.Context, src scanner.Scanner, config analysisConfig, interval time.Duration, once bool) error {
 	fmt.Println("Initializing scanner...")
 
 	// Test the scanner once before starting the loop
-	networks, err := scanner.ScanWiFiNetworks()
+	networks, err := src.Scan(ctx)
+	if ctx.Err() != nil {
+		return nil
+	}
 	if err != nil {
-		log.Fatalf("Initial scan failed: %v\nPlease ensure you have the correct permissions to scan WiFi networks.", err)
+		return fmt.Errorf("initial scan failed: %v%s", err, guidance(err))
 	}
 
 	fmt.Println("Scanner initialized successfully.")
+	if reporter, ok := src.(scanner.SourceReporter); ok {
+		if backend, iface := reporter.Source(); iface != "" {
+			fmt.Printf("Using %s backend on %s\n", backend, iface)
+		} else if backend != "" {
+			fmt.Printf("Using %s backend\n", backend)
+		}
+	}
+	fmt.Printf("Regulatory domain: %s\n", config.domain)
 
 	// Show detailed channel information on first run
 	if len(networks) > 0 {
@@ -31,41 +258,267 @@ func main() {
 			analyzerNetworks[i] = net
 		}
 
-		display.DisplayChannelInfo(analyzerNetworks)
-		fmt.Println("\nStarting continuous scan...")
-		time.Sleep(3 * time.Second) // Give user time to read
-	} else {
+		display.DisplayChannelInfo(analyzerNetworks, config.domain)
+		if !once {
+			fmt.Println("\nStarting continuous scan...")
+			if interval > 0 && !sleep(ctx, 3*time.Second) { // Give user time to read
+				return nil
+			}
+		}
+	} else if !once {
 		fmt.Println("No networks detected in initial scan. Starting continuous scan...")
 	}
 
 	for {
-		networks, err := scanner.ScanWiFiNetworks()
+		showResults(networks, config)
+		if once {
+			return nil
+		}
+
+		if !sleep(ctx, interval) {
+			return nil
+		}
+
+		for {
+			networks, err = src.Scan(ctx)
+			if err == nil {
+				break
+			}
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			log.Printf("Error scanning networks: %v%s", err, guidance(err))
+			if !sleep(ctx, 5*time.Second) {
+				return nil
+			}
+		}
+	}
+}
+
+// sleep pauses for d, returning false if ctx was cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// scanErrorHints maps scanner sentinel errors to advice on fixing them
+var scanErrorHints = []struct {
+	err  error
+	hint string
+}{
+	{scanner.ErrPermissionDenied, "Scanning needs more privileges: run with sudo, grant CAP_NET_ADMIN (sudo setcap cap_net_admin+ep ./wifi-bander), or use a backend that reads cached results such as -backend networkmanager."},
+	{scanner.ErrRadioDisabled, "The Wi-Fi radio is off: enable it with `rfkill unblock wifi` or `nmcli radio wifi on` (macOS: turn Wi-Fi on in the menu bar)."},
+	{scanner.ErrNoInterface, "No wireless interface was found: check that the adapter is present and its driver is loaded (`iw dev`), or name one with -backend nl80211:wlan0."},
+	{scanner.ErrDeviceBusy, "The adapter is busy with another scan or connection attempt; the scan will be retried."},
+	{scanner.ErrToolMissing, "A required tool or service is missing: install iw, NetworkManager or wireless-tools, or choose an available backend (see -backend list)."},
+	{context.DeadlineExceeded, "A scanner command timed out: the adapter or driver may be stuck; try another backend with -backend."},
+}
+
+// guidance returns one line of advice per recognised cause in err, each prefixed with a newline
+func guidance(err error) string {
+	var hints strings.Builder
+	for _, entry := range scanErrorHints {
+		if errors.Is(err, entry.err) {
+			hints.WriteString("\n" + entry.hint)
+		}
+	}
+	return hints.String()
+}
+
+// reportedFailures remembers logged backend failures so a persistent problem is reported once
+var reportedFailures = make(map[string]bool)
+
+// reportBackendFailure logs why a backend or radio was skipped while others succeeded
+func reportBackendFailure(err *scanner.BackendError) {
+	if reportedFailures[err.Error()] {
+		return
+	}
+	reportedFailures[err.Error()] = true
+	log.Printf("Scanner backend failed, using fallback: %v", err)
+}
+
+// listBackends prints every registered backend with its platforms and capabilities
+func listBackends() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BACKEND\tPLATFORMS\tDEFAULT\tSIGNAL\tCAPABILITIES\tDESCRIPTION")
+	for _, backend := range scanner.Backends() {
+		platforms := "all"
+		if len(backend.Platforms) > 0 {
+			platforms = strings.Join(backend.Platforms, ",")
+		}
+		auto := "no"
+		if backend.Auto && backend.Supported() {
+			auto = "yes"
+		}
+		unit := string(backend.Signal)
+		if unit == "" {
+			unit = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", backend.Name, platforms, auto, unit, backend.Capabilities, backend.Description)
+	}
+	w.Flush()
+}
+
+// regulatoryDomain picks the domain recommendations must respect: the -country
+// flag, else the country the kernel reports, else the conservative world domain
+func regulatoryDomain(ctx context.Context, country string) (*regdb.Domain, error) {
+	if country != "" {
+		domain, err := regdb.Lookup(country)
 		if err != nil {
-			log.Printf("Error scanning networks: %v", err)
-			time.Sleep(5 * time.Second)
-			continue
+			return nil, fmt.Errorf("%v (known: %s)", err, strings.Join(regdb.Countries(), ", "))
+		}
+		return domain, nil
+	}
+
+	detected, err := scanner.DetectCountry(ctx)
+	switch {
+	case errors.Is(err, errors.ErrUnsupported):
+		log.Printf("Cannot detect the regulatory country on %s; recommending only channels allowed worldwide (choose one with -country)", runtime.GOOS)
+	case err != nil || detected == regdb.World:
+		log.Printf("No regulatory country set; recommending only channels allowed worldwide (choose one with -country)")
+	default:
+		if domain, err := regdb.Lookup(detected); err == nil {
+			return domain, nil
 		}
+		log.Printf("Regulatory country %s is not in the database; recommending only channels allowed worldwide", detected)
+	}
+	return regdb.WorldDomain(), nil
+}
 
-		// Sort networks by congestion score (ascending - least congested first)
-		sort.Slice(networks, func(i, j int) bool {
-			return networks[i].CongestionScore < networks[j].CongestionScore
-		})
+// listCountries prints every country in the regulatory database
+func listCountries() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COUNTRY\tNAME")
+	for _, code := range regdb.Countries() {
+		domain, _ := regdb.Lookup(code)
+		fmt.Fprintf(w, "%s\t%s\n", code, domain.Name)
+	}
+	w.Flush()
+}
 
-		// Convert to display interface
-		displayNetworks := make([]display.WiFiNetwork, len(networks))
-		for i, net := range networks {
-			displayNetworks[i] = net
+// showResults prints the network table and channel recommendations for one scan
+func showResults(networks []scanner.WiFiNetwork, config analysisConfig) {
+	// Backends score congestion with the default model; rescore with the chosen one
+	scanner.ScoreCongestion(networks, config.scorers[0])
+
+	// Sort networks by congestion score (ascending - least congested first)
+	sort.Slice(networks, func(i, j int) bool {
+		return networks[i].CongestionScore < networks[j].CongestionScore
+	})
+
+	// Convert to display interface
+	displayNetworks := make([]display.WiFiNetwork, len(networks))
+	for i, net := range networks {
+		displayNetworks[i] = net
+	}
+
+	// Convert to analyzer interfaces
+	analyzerNetworks := make([]analyzer.WiFiNetwork, len(networks))
+	accessPoints := make([]analyzer.AccessPoint, len(networks))
+	for i, net := range networks {
+		analyzerNetworks[i] = net
+		accessPoints[i] = net
+	}
+
+	at := time.Now()
+	if config.recorded {
+		at = scanTime(networks)
+	}
+	config.history.Add(analyzerNetworks, at)
+	primed := config.changes.Primed()
+	events := config.changes.Detect(networks, at)
+
+	display.DisplayResults(displayNetworks)
+	if primed {
+		display.DisplayChanges(events)
+	}
+	display.DisplayRogueFindings(config.rogues.Analyze(accessPoints, at))
+	if config.alerts != nil {
+		reportAlerts(config.alerts.Evaluate(networks, events, at))
+	}
+	display.DisplayRecommendations(config.history, config.domain, config.scorers[0])
+	if len(config.scorers) > 1 {
+		display.DisplayModelComparison(config.history, config.domain, config.scorers)
+	}
+}
+
+// reportAlerts prints the alerts that fired or resolved with this scan
+func reportAlerts(notifications []alert.Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+	fmt.Println("\n=== Alerts ===")
+	for _, notification := range notifications {
+		icon := "🔔"
+		if notification.State == alert.StateResolved {
+			icon = "✅"
 		}
+		fmt.Printf("  %s %s\n", icon, notification)
+	}
+}
 
-		// Convert to analyzer interface
-		analyzerNetworks := make([]analyzer.WiFiNetwork, len(networks))
-		for i, net := range networks {
-			analyzerNetworks[i] = net
+// scanTime returns when a recorded scan was taken: its most recent observation, so
+// replayed and captured scans keep their own pacing, falling back to now. Live
+// scans are timed by the clock instead, as backends that cache results report the
+// same or even older last-seen times in consecutive scans.
+func scanTime(networks []scanner.WiFiNetwork) time.Time {
+	var latest time.Time
+	for _, network := range networks {
+		if network.LastSeen.After(latest) {
+			latest = network.LastSeen
+		}
+	}
+	if latest.IsZero() {
+		return time.Now()
+	}
+	return latest
+}
+
+// scoringModels creates the comma-separated scoring models, all with the weights
+// from weightsPath or the defaults when it is empty
+func scoringModels(list, weightsPath string) ([]analyzer.Scorer, error) {
+	weights := analyzer.DefaultWeights()
+	if weightsPath != "" {
+		var err error
+		if weights, err = analyzer.LoadWeights(weightsPath); err != nil {
+			return nil, err
 		}
+	}
 
-		display.DisplayResults(displayNetworks)
-		display.DisplayRecommendations(analyzerNetworks)
+	var scorers []analyzer.Scorer
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		scorer, err := analyzer.NewScorer(name, weights)
+		if err != nil {
+			return nil, fmt.Errorf("%v (see -model list)", err)
+		}
+		scorers = append(scorers, scorer)
+	}
+	if len(scorers) == 0 {
+		scorers = append(scorers, analyzer.DefaultScorer())
+	}
+	return scorers, nil
+}
 
-		time.Sleep(10 * time.Second)
+// listModels prints every registered scoring model
+func listModels() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tDEFAULT\tDESCRIPTION")
+	for _, model := range analyzer.Models() {
+		isDefault := "no"
+		if model.Name == analyzer.DefaultModel {
+			isDefault = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", model.Name, isDefault, model.Description)
 	}
+	w.Flush()
 }
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svgreg/wifi-bander/internal/analyzer"
+	"github.com/svgreg/wifi-bander/internal/changes"
+	"github.com/svgreg/wifi-bander/internal/regdb"
+	"github.com/svgreg/wifi-bander/internal/scanner"
+)
+
+// captureStdout redirects standard output to a file until the returned function
+// restores it and returns what was written
+func captureStdout(t *testing.T) func() string {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = file
+	t.Cleanup(func() { os.Stdout = stdout })
+	return func() string {
+		os.Stdout = stdout
+		file.Close()
+		data, err := os.ReadFile(file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+}
+
+// testConfig analyzes scans against the US domain with a one-minute history
+func testConfig(t *testing.T) analysisConfig {
+	t.Helper()
+	domain, err := regdb.Lookup("US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return analysisConfig{
+		domain:  domain,
+		scorers: []analyzer.Scorer{analyzer.DefaultScorer()},
+		history: analyzer.NewHistory(time.Minute),
+		changes: changes.NewDetector(changes.DefaultSignalThreshold),
+		rogues:  analyzer.NewRogueDetector(),
+	}
+}
+
+// recordSession writes scans ten seconds apart, starting at start, to a session file
+func recordSession(t *testing.T, start time.Time, scans [][]scanner.WiFiNetwork) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := scanner.NewRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recorder.Close()
+	for i, networks := range scans {
+		if err := recorder.Record(start.Add(time.Duration(i)*10*time.Second), "nl80211", "wlan0", networks); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestRunReplaysSession(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	office := scanner.WiFiNetwork{SSID: "Office", BSSID: "00:11:32:aa:bb:cc", Band: "5G", Channel: 36, Frequency: 5180,
+		ChannelWidth: "20MHz", Signal: -50, Security: "WPA2 Personal", SignalUnit: scanner.SignalDBm}
+	cafe := scanner.WiFiNetwork{SSID: "Cafe", BSSID: "00:11:32:aa:bb:cd", Band: "2.4G", Channel: 6, Frequency: 2437,
+		ChannelWidth: "20MHz", Signal: -70, Security: "Open", SignalUnit: scanner.SignalDBm}
+	newcomer := scanner.WiFiNetwork{SSID: "Newcomer", BSSID: "00:11:32:aa:bb:ce", Band: "5G", Channel: 149, Frequency: 5745,
+		ChannelWidth: "20MHz", Signal: -60, Security: "WPA3 Personal", SignalUnit: scanner.SignalDBm}
+	weaker := office
+	weaker.Signal = -65
+	path := recordSession(t, start, [][]scanner.WiFiNetwork{
+		{office, cafe},
+		{office, cafe, newcomer},
+		{weaker, cafe, newcomer},
+	})
+
+	config := testConfig(t)
+	config.recorded = true
+	events := config.changes.Subscribe(16)
+	output := captureStdout(t)
+	err := run(context.Background(), &scanner.ReplayScanner{Path: path}, config, 0, false)
+	printed := output()
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if !strings.Contains(printed, "Using nl80211 backend on wlan0") {
+		t.Errorf("recorded source not shown:\n%s", printed)
+	}
+	if got := strings.Count(printed, "Newcomer"); got < 2 {
+		t.Errorf("Newcomer shown %d times, want it in the last two scans:\n%s", got, printed)
+	}
+
+	// Each replayed scan is analyzed at the time it was recorded
+	if config.history.Scans() != 3 || config.history.Span() != 20*time.Second {
+		t.Errorf("history holds %d scans over %v, want 3 over 20s", config.history.Scans(), config.history.Span())
+	}
+	config.changes.Close()
+	var got []string
+	for event := range events {
+		got = append(got, string(event.Type)+" "+event.Network.SSID+" +"+event.Time.Sub(start).String())
+	}
+	want := []string{"appeared Newcomer +10s", "signal Office +20s"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("events %v, want %v", got, want)
+	}
+}
+
+// cachedScanner is a live backend returning the same cached results, with the
+// same last-seen times, until it runs out
+type cachedScanner struct {
+	networks []scanner.WiFiNetwork
+	scans    int
+}
+
+func (c *cachedScanner) Scan(ctx context.Context) ([]scanner.WiFiNetwork, error) {
+	if c.scans == 0 {
+		return nil, io.EOF
+	}
+	c.scans--
+	networks := make([]scanner.WiFiNetwork, len(c.networks))
+	copy(networks, c.networks)
+	return networks, nil
+}
+
+func TestRunTimesLiveScansByTheClock(t *testing.T) {
+	cached := time.Now().Add(-time.Hour)
+	src := &cachedScanner{scans: 3, networks: []scanner.WiFiNetwork{{SSID: "Office", BSSID: "00:11:32:aa:bb:cc",
+		Band: "5G", Channel: 36, Frequency: 5180, ChannelWidth: "20MHz", Signal: -50, LastSeen: cached}}}
+
+	config := testConfig(t)
+	output := captureStdout(t)
+	began := time.Now()
+	err := run(context.Background(), src, config, time.Millisecond, false)
+	output()
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	// Scans with the same last-seen time are still separate scans, taken now
+	if config.history.Scans() != 3 {
+		t.Errorf("history holds %d scans, want 3", config.history.Scans())
+	}
+	if span := config.history.Span(); span <= 0 || span > time.Since(began) {
+		t.Errorf("history spans %v, want the %v run took", span, time.Since(began))
+	}
+}
+
+func TestRunInitialScanFailure(t *testing.T) {
+	output := captureStdout(t)
+	err := run(context.Background(), &scanner.ReplayScanner{Path: filepath.Join(t.TempDir(), "missing.jsonl")}, testConfig(t), 0, false)
+	output()
+	if err == nil || !strings.Contains(err.Error(), "initial scan failed") {
+		t.Errorf("run = %v, want the initial scan failure", err)
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	network := scanner.WiFiNetwork{SSID: "Office", BSSID: "00:11:32:aa:bb:cc", Band: "5G", Channel: 36, Frequency: 5180,
+		ChannelWidth: "20MHz", Signal: -50, Security: "WPA2 Personal"}
+	path := recordSession(t, start, [][]scanner.WiFiNetwork{{network}, {network}})
+
+	// Only the first scan is analyzed, as for a capture file
+	config := testConfig(t)
+	output := captureStdout(t)
+	err := run(context.Background(), &scanner.ReplayScanner{Path: path}, config, 0, true)
+	output()
+	if err != nil || config.history.Scans() != 1 {
+		t.Errorf("run = %v with %d scans analyzed, want 1", err, config.history.Scans())
+	}
+}
+
+func TestRunWorldDomainAsksForCountryFor6GHz(t *testing.T) {
+	network := scanner.WiFiNetwork{SSID: "Office", BSSID: "00:11:32:aa:bb:cc", Band: "6G", Channel: 37, Frequency: 6135,
+		ChannelWidth: "80MHz", Signal: -50, Security: "WPA3 Personal"}
+	path := recordSession(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), [][]scanner.WiFiNetwork{{network}})
+
+	config := testConfig(t)
+	config.domain = regdb.WorldDomain()
+	output := captureStdout(t)
+	err := run(context.Background(), &scanner.ReplayScanner{Path: path}, config, 0, true)
+	printed := output()
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !strings.Contains(printed, "The 6G band is not permitted in every country; choose yours with -country") {
+		t.Errorf("6 GHz recommendations missing without a hint:\n%s", printed)
+	}
+}
+
+func TestScoringModels(t *testing.T) {
+	weightsPath := filepath.Join(t.TempDir(), "weights.json")
+	if err := os.WriteFile(weightsPath, []byte(`{"dfs": 0}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		list, weights string
+		want          string // Model names, empty for an error
+	}{
+		{"", "", "overlap"},
+		{"airtime", "", "airtime"},
+		{" airtime, overlap ", weightsPath, "airtime overlap"},
+		{"airtime,fancy", "", ""},
+		{"overlap", filepath.Join(t.TempDir(), "missing.json"), ""},
+	}
+
+	for _, test := range tests {
+		scorers, err := scoringModels(test.list, test.weights)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("scoringModels(%q, %q) succeeded", test.list, test.weights)
+			}
+			continue
+		}
+		var names []string
+		for _, scorer := range scorers {
+			names = append(names, scorer.Name())
+			if test.weights != "" && scorer.Weights().DFS != 0 {
+				t.Errorf("scoringModels(%q): %s ignores the weights file", test.list, scorer.Name())
+			}
+		}
+		if err != nil || strings.Join(names, " ") != test.want {
+			t.Errorf("scoringModels(%q, %q) = %v, %v; want %s", test.list, test.weights, names, err, test.want)
+		}
+	}
+}